@@ -0,0 +1,166 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"encore.dev/beta/errs"
+	"gorm.io/gorm"
+)
+
+// The endpoints of this service read and write the records of one site, named
+// by the siteID path parameter. The few that find a site, or a record whatever
+// site it belongs to, say so. They are private: other services call them, and
+// decide themselves who may do so.
+
+// session returns a database session for the site siteID.
+func session(ctx context.Context, siteID uint) (*gorm.DB, error) {
+	db, err := database()
+	if err != nil {
+		return nil, err
+	}
+	return db.WithContext(WithSite(ctx, siteID)), nil
+}
+
+// transaction runs fn in a transaction on the records of the site siteID,
+// which commits if fn returns nil and rolls back otherwise. The events fn
+// enqueues are published right after the commit.
+func transaction(ctx context.Context, siteID uint, fn func(tx *gorm.DB) error) error {
+	db, err := session(ctx, siteID)
+	if err != nil {
+		return err
+	}
+	if err := db.Transaction(fn); err != nil {
+		return DBError(err)
+	}
+	outbox.notify()
+	return nil
+}
+
+// ItemError is the expected failure of one operation of a batch, such as a
+// missing record or a duplicate value.
+type ItemError struct {
+	Code    errs.ErrCode `json:"code"`
+	Message string       `json:"message"`
+	// Field is the request field the error refers to, if any.
+	Field string `json:"field,omitempty"`
+	// CurrentVersion is the version of the record an update conflicted with.
+	CurrentVersion int `json:"currentVersion,omitempty"`
+}
+
+// Err returns the failure as the error the operation would have returned on
+// its own endpoint.
+func (e *ItemError) Err() error {
+	err := &errs.Error{Code: e.Code, Message: e.Message}
+	switch {
+	case e.CurrentVersion != 0:
+		err.Details = VersionDetails{Current: e.CurrentVersion}
+	case e.Field != "":
+		err.Details = FieldDetails{Field: e.Field}
+	}
+	return err
+}
+
+// itemError describes err if it is an expected failure.
+func itemError(err error) (*ItemError, bool) {
+	var encoreErr *errs.Error
+	if !errors.As(DBError(err), &encoreErr) {
+		return nil, false
+	}
+	switch encoreErr.Code {
+	case errs.Unknown, errs.Internal, errs.Unavailable, errs.DeadlineExceeded, errs.Canceled, errs.DataLoss:
+		return nil, false
+	}
+	ie := &ItemError{Code: encoreErr.Code, Message: encoreErr.Message}
+	switch d := encoreErr.Details.(type) {
+	case FieldDetails:
+		ie.Field = d.Field
+	case VersionDetails:
+		ie.CurrentVersion = d.Current
+	}
+	return ie, true
+}
+
+// DeleteOp deletes the record with the primary key ID as part of a batch.
+type DeleteOp struct {
+	ID uint `json:"id"`
+}
+
+// errEmptyOp is the failure of a batch operation that sets none of its fields.
+var errEmptyOp = &errs.Error{Code: errs.InvalidArgument, Message: "operation must set one of create, update or delete"}
+
+// errRollback aborts the transaction of an atomic batch that had failures.
+var errRollback = errors.New("batch: rollback")
+
+// runBatch runs fn for operations 0..n-1 in a single transaction. Every
+// operation runs under its own savepoint, so a failing one leaves the others
+// intact. With atomic set, a single failure rolls back the whole batch.
+//
+// Expected failures are returned at the index of their operation; unexpected
+// errors abort the batch. committed reports whether the operations that did
+// not fail were committed.
+func runBatch(ctx context.Context, siteID uint, n int, atomic bool, fn func(tx *gorm.DB, i int) error) (failures []*ItemError, committed bool, err error) {
+	failures = make([]*ItemError, n)
+	failed := false
+	err = transaction(ctx, siteID, func(tx *gorm.DB) error {
+		for i := 0; i < n; i++ {
+			savepoint := "op_" + strconv.Itoa(i)
+			if err := tx.SavePoint(savepoint).Error; err != nil {
+				return err
+			}
+			if err := fn(tx, i); err != nil {
+				ie, ok := itemError(err)
+				if !ok {
+					return err
+				}
+				if err := tx.RollbackTo(savepoint).Error; err != nil {
+					return err
+				}
+				failures[i] = ie
+				failed = true
+			}
+		}
+		if atomic && failed {
+			return errRollback
+		}
+		return nil
+	})
+	if errors.Is(err, errRollback) {
+		return failures, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return failures, true, nil
+}
+
+// filter accumulates the conditions of a list request.
+type filter struct {
+	conds []string
+	args  []any
+}
+
+func (f *filter) add(cond string, arg any) {
+	f.conds = append(f.conds, cond)
+	f.args = append(f.args, arg)
+}
+
+func (f *filter) contains(column, s string) {
+	if s != "" {
+		f.add(column+" ILIKE ?", "%"+escapeLike(s)+"%")
+	}
+}
+
+func (f *filter) apply(db *gorm.DB) *gorm.DB {
+	if len(f.conds) == 0 {
+		return db
+	}
+	return db.Where(strings.Join(f.conds, " AND "), f.args...)
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
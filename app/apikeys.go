@@ -0,0 +1,118 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"encore.dev/beta/errs"
+	"gorm.io/gorm"
+)
+
+type ListApiKeysResponse struct {
+	ApiKeys []*ApiKey `json:"apiKeys"`
+}
+
+// ListApiKeys lists the API keys of a site, by ID.
+//
+//encore:api private method=GET path=/sites/:siteID/api-keys
+func ListApiKeys(ctx context.Context, siteID uint) (*ListApiKeysResponse, error) {
+	db, err := session(ctx, siteID)
+	if err != nil {
+		return nil, err
+	}
+	keys := []*ApiKey{}
+	if err := db.Order("id").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return &ListApiKeysResponse{ApiKeys: keys}, nil
+}
+
+type CreateApiKeyParams struct {
+	Name string `json:"name"`
+	// Prefix is the start of the secret, which tells keys apart.
+	Prefix string `json:"prefix"`
+	// Hash is the SHA-256 of the secret, which is not stored.
+	Hash string `json:"hash"`
+	// Scopes are separated by spaces.
+	Scopes    string     `json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// CreateApiKey stores a new API key of a site.
+//
+//encore:api private method=POST path=/sites/:siteID/api-keys
+func CreateApiKey(ctx context.Context, siteID uint, p *CreateApiKeyParams) (*ApiKey, error) {
+	key := &ApiKey{Name: p.Name, Prefix: p.Prefix, Hash: p.Hash, Scopes: p.Scopes, ExpiresAt: p.ExpiresAt}
+	err := transaction(ctx, siteID, func(tx *gorm.DB) error {
+		return tx.Create(key).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// RevokeApiKey revokes an API key of a site and returns it. Revoking a key
+// twice keeps the time of the first revocation.
+//
+//encore:api private method=POST path=/sites/:siteID/api-keys/:id/revoke
+func RevokeApiKey(ctx context.Context, siteID, id uint) (*ApiKey, error) {
+	var key ApiKey
+	err := transaction(ctx, siteID, func(tx *gorm.DB) error {
+		if err := tx.First(&key, id).Error; err != nil {
+			return err
+		}
+		if key.RevokedAt != nil {
+			return nil
+		}
+		now := time.Now()
+		key.RevokedAt = &now
+		return tx.Model(&key).Update("revoked_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+type AuthenticateApiKeyParams struct {
+	// Hash is the SHA-256 of the secret the caller presented.
+	Hash string `json:"hash"`
+}
+
+// AuthenticateApiKey returns the valid API key with the given hash, of any
+// site, and records that it was used. It returns Unauthenticated for
+// unknown, revoked and expired keys.
+//
+//encore:api private method=POST path=/api-keys/authenticate
+func AuthenticateApiKey(ctx context.Context, p *AuthenticateApiKeyParams) (*ApiKey, error) {
+	db, err := database()
+	if err != nil {
+		return nil, err
+	}
+	// The key determines the site, so it has to be found across all sites.
+	db = db.WithContext(AcrossSites(ctx))
+	now := time.Now()
+	var key ApiKey
+	err = db.Where("hash = ?", p.Hash).First(&key).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, &errs.Error{Code: errs.Unauthenticated, Message: "invalid API key"}
+	case err != nil:
+		return nil, err
+	case key.RevokedAt != nil:
+		return nil, &errs.Error{Code: errs.Unauthenticated, Message: "API key has been revoked"}
+	case key.ExpiresAt != nil && !now.Before(*key.ExpiresAt):
+		return nil, &errs.Error{Code: errs.Unauthenticated, Message: "API key has expired"}
+	}
+	// Only record the use once a minute, so that busy clients don't write on
+	// every request.
+	err = db.Model(&ApiKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", key.ID, now.Add(-time.Minute)).
+		Update("last_used_at", now).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
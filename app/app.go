@@ -1,6 +1,10 @@
 package app
 
 import (
+	"sync"
+	"time"
+
+	"encore.dev/shutdown"
 	"encore.dev/storage/sqldb"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -8,31 +12,55 @@ import (
 
 //encore:service
 type Service struct {
-	db *gorm.DB
+	webhooks *webhookDispatcher
 }
 
 var blogDB = sqldb.NewDatabase("app", sqldb.DatabaseConfig{
 	Migrations: "./migrations",
 })
 
-// initService initializes the site service.
-// It is automatically called by Encore on service startup.
-func initService() (*Service, error) {
+// database returns the GORM handle of blogDB, opened on first use. The
+// database belongs to this service alone; other services go through its
+// endpoints.
+var database = sync.OnceValues(func() (*gorm.DB, error) {
 	db, err := gorm.Open(postgres.New(postgres.Config{
 		Conn: blogDB.Stdlib(),
 	}))
 	if err != nil {
 		return nil, err
 	}
-	return &Service{db: db}, nil
-}
+	if err := db.Use(tenantScope{}); err != nil {
+		return nil, err
+	}
+	return db, nil
+})
 
-// New creates a new instance of Service (for external use, e.g., by graphql/service.go).
-func New() (*Service, error) {
-	return initService()
+// initService initializes the site service and starts publishing events and
+// sending webhooks.
+// It is automatically called by Encore on service startup.
+func initService() (*Service, error) {
+	if _, err := database(); err != nil {
+		return nil, err
+	}
+	webhooks := newWebhookDispatcher(
+		time.Duration(cfg.Webhooks.TimeoutSeconds())*time.Second,
+		cfg.Webhooks.MaxAttempts(),
+		time.Duration(cfg.Webhooks.PollSeconds())*time.Second,
+		time.Duration(cfg.Webhooks.RetentionDays())*24*time.Hour,
+		cfg.Webhooks.AllowPrivateTargets(),
+	)
+	go outbox.run()
+	go webhooks.run()
+	return &Service{webhooks: webhooks}, nil
 }
 
-// DB returns the gorm.DB instance for external use.
-func (s *Service) DB() *gorm.DB {
-	return s.db
-}
\ No newline at end of file
+// Shutdown stops publishing events once no more requests can write them, and
+// stops sending webhooks once no more events come in. Whatever is still
+// pending is picked up by another instance or after restart.
+func (s *Service) Shutdown(p shutdown.Progress) error {
+	<-p.OutstandingRequests.Done()
+	outbox.close()
+	<-p.OutstandingPubSubMessages.Done()
+	s.webhooks.close()
+	return nil
+}
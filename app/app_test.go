@@ -0,0 +1,26 @@
+//go:build encore_app
+
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+)
+
+// createSite creates a site for a test, with a slug no other test uses.
+func createSite(t *testing.T, name string) *Site {
+	t.Helper()
+	db, err := database()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b [4]byte
+	rand.Read(b[:])
+	site := &Site{Slug: name + "-" + hex.EncodeToString(b[:]), Name: name}
+	if err := db.WithContext(context.Background()).Create(site).Error; err != nil {
+		t.Fatal(err)
+	}
+	return site
+}
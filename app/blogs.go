@@ -0,0 +1,269 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ListBlogsParams struct {
+	// IDs limits the list to these posts.
+	IDs           []uint `query:"id"`
+	TitleContains string `query:"titleContains"`
+	// CreatedBefore and CreatedAfter are ignored when zero.
+	CreatedBefore time.Time `query:"createdBefore"`
+	CreatedAfter  time.Time `query:"createdAfter"`
+	// IncludeDrafts also lists drafts.
+	IncludeDrafts bool `query:"includeDrafts"`
+}
+
+type ListBlogsResponse struct {
+	Blogs []*Blog `json:"blogs"`
+}
+
+// ListBlogs lists the blog posts of a site that match every given condition,
+// by ID.
+//
+//encore:api private method=GET path=/sites/:siteID/blogs
+func ListBlogs(ctx context.Context, siteID uint, p *ListBlogsParams) (*ListBlogsResponse, error) {
+	db, err := session(ctx, siteID)
+	if err != nil {
+		return nil, err
+	}
+	var f filter
+	if len(p.IDs) > 0 {
+		f.add("id IN ?", p.IDs)
+	}
+	f.contains("title", p.TitleContains)
+	if !p.CreatedBefore.IsZero() {
+		f.add("created_at < ?", p.CreatedBefore)
+	}
+	if !p.CreatedAfter.IsZero() {
+		f.add("created_at > ?", p.CreatedAfter)
+	}
+	if !p.IncludeDrafts {
+		f.add("draft = ?", false)
+	}
+	blogs := []*Blog{}
+	if err := f.apply(db).Order("id").Find(&blogs).Error; err != nil {
+		return nil, err
+	}
+	return &ListBlogsResponse{Blogs: blogs}, nil
+}
+
+type GetBlogParams struct {
+	// IncludeDrafts also returns a draft.
+	IncludeDrafts bool `query:"includeDrafts"`
+}
+
+// GetBlog returns a blog post, or NotFound.
+//
+//encore:api private method=GET path=/sites/:siteID/blogs/:id
+func GetBlog(ctx context.Context, siteID, id uint, p *GetBlogParams) (*Blog, error) {
+	db, err := session(ctx, siteID)
+	if err != nil {
+		return nil, err
+	}
+	if !p.IncludeDrafts {
+		db = db.Where("draft = ?", false)
+	}
+	var blog Blog
+	if err := db.First(&blog, id).Error; err != nil {
+		return nil, DBError(err)
+	}
+	return &blog, nil
+}
+
+type CreateBlogParams struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	// CreatedAt defaults to now.
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	Draft     bool       `json:"draft"`
+}
+
+// CreateBlog creates a blog post and publishes BlogCreated, followed by
+// BlogPublished unless it is a draft.
+//
+//encore:api private method=POST path=/sites/:siteID/blogs
+func CreateBlog(ctx context.Context, siteID uint, p *CreateBlogParams) (blog *Blog, err error) {
+	err = transaction(ctx, siteID, func(tx *gorm.DB) error {
+		blog, err = createBlog(tx, p)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return blog, nil
+}
+
+func createBlog(tx *gorm.DB, p *CreateBlogParams) (*Blog, error) {
+	blog := &Blog{
+		Title:     p.Title,
+		Content:   p.Content,
+		CreatedAt: time.Now(),
+		Draft:     p.Draft,
+	}
+	if p.CreatedAt != nil {
+		blog.CreatedAt = *p.CreatedAt
+	}
+	if err := tx.Create(blog).Error; err != nil {
+		return nil, err
+	}
+	if err := blogEvent(tx, BlogCreated, blog); err != nil {
+		return nil, err
+	}
+	if !blog.Draft {
+		return blog, blogEvent(tx, BlogPublished, blog)
+	}
+	return blog, nil
+}
+
+// UpdateBlogParams changes the fields that are set.
+type UpdateBlogParams struct {
+	Title   *string `json:"title,omitempty"`
+	Content *string `json:"content,omitempty"`
+	// Draft publishes a draft when set to false.
+	Draft *bool `json:"draft,omitempty"`
+	// ExpectedVersion rejects the update with Aborted unless the post is
+	// still at this version.
+	ExpectedVersion *int `json:"expectedVersion,omitempty"`
+}
+
+// UpdateBlog changes a blog post and publishes BlogUpdated, followed by
+// BlogPublished if it ended its draft.
+//
+//encore:api private method=PATCH path=/sites/:siteID/blogs/:id
+func UpdateBlog(ctx context.Context, siteID, id uint, p *UpdateBlogParams) (blog *Blog, err error) {
+	err = transaction(ctx, siteID, func(tx *gorm.DB) error {
+		blog, err = updateBlog(tx, id, p)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return blog, nil
+}
+
+func updateBlog(tx *gorm.DB, id uint, p *UpdateBlogParams) (*Blog, error) {
+	var blog Blog
+	if err := tx.First(&blog, id).Error; err != nil {
+		return nil, err
+	}
+	if err := checkVersion(&blog, p.ExpectedVersion); err != nil {
+		return nil, err
+	}
+	if p.Title != nil {
+		blog.Title = *p.Title
+	}
+	if p.Content != nil {
+		blog.Content = *p.Content
+	}
+	wasDraft := blog.Draft
+	if p.Draft != nil {
+		blog.Draft = *p.Draft
+	}
+	if err := saveVersioned(tx, &blog, id); err != nil {
+		return nil, err
+	}
+	if err := blogEvent(tx, BlogUpdated, &blog); err != nil {
+		return nil, err
+	}
+	if wasDraft && !blog.Draft {
+		return &blog, blogEvent(tx, BlogPublished, &blog)
+	}
+	return &blog, nil
+}
+
+// DeleteBlog deletes a blog post and publishes BlogDeleted. It returns
+// the deleted post.
+//
+//encore:api private method=DELETE path=/sites/:siteID/blogs/:id
+func DeleteBlog(ctx context.Context, siteID, id uint) (blog *Blog, err error) {
+	err = transaction(ctx, siteID, func(tx *gorm.DB) error {
+		blog, err = deleteBlog(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return blog, nil
+}
+
+func deleteBlog(tx *gorm.DB, id uint) (*Blog, error) {
+	// RETURNING fills in the deleted record for the event.
+	var blog Blog
+	res := tx.Clauses(clause.Returning{}).Delete(&blog, id)
+	if res.Error != nil {
+		return nil, stillReferenced(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &blog, blogEvent(tx, BlogDeleted, &blog)
+}
+
+// BlogOp is one operation of a batch. Exactly one of its fields is set.
+type BlogOp struct {
+	Create *CreateBlogParams `json:"create,omitempty"`
+	Update *UpdateBlogOp     `json:"update,omitempty"`
+	Delete *DeleteOp         `json:"delete,omitempty"`
+}
+
+type UpdateBlogOp struct {
+	ID     uint             `json:"id"`
+	Params UpdateBlogParams `json:"params"`
+}
+
+type BatchBlogsParams struct {
+	Ops []*BlogOp `json:"ops"`
+	// Atomic rolls back every operation if any of them fails.
+	Atomic bool `json:"atomic"`
+}
+
+type BatchBlogsResponse struct {
+	// Committed reports whether the operations without an error took
+	// effect.
+	Committed bool `json:"committed"`
+	// Results holds the outcome of each operation, in order.
+	Results []*BlogResult `json:"results"`
+}
+
+type BlogResult struct {
+	// Blog is the created, updated or deleted post.
+	Blog  *Blog      `json:"blog,omitempty"`
+	Error *ItemError `json:"error,omitempty"`
+}
+
+// BatchBlogs runs the operations in a single transaction, see runBatch.
+//
+//encore:api private method=POST path=/sites/:siteID/batch/blogs
+func BatchBlogs(ctx context.Context, siteID uint, p *BatchBlogsParams) (*BatchBlogsResponse, error) {
+	results := make([]*BlogResult, len(p.Ops))
+	failures, committed, err := runBatch(ctx, siteID, len(p.Ops), p.Atomic, func(tx *gorm.DB, i int) (err error) {
+		res := &BlogResult{}
+		switch op := p.Ops[i]; {
+		case op.Create != nil:
+			res.Blog, err = createBlog(tx, op.Create)
+		case op.Update != nil:
+			res.Blog, err = updateBlog(tx, op.Update.ID, &op.Update.Params)
+		case op.Delete != nil:
+			res.Blog, err = deleteBlog(tx, op.Delete.ID)
+		default:
+			err = errEmptyOp
+		}
+		results[i] = res
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		if failures[i] != nil || !committed {
+			results[i] = &BlogResult{Error: failures[i]}
+		}
+	}
+	return &BatchBlogsResponse{Committed: committed, Results: results}, nil
+}
@@ -0,0 +1,16 @@
+// Domain events wait in the outbox_events table until they are published.
+Outbox: {
+	PollSeconds:    30
+	RetentionHours: 168
+}
+
+// Delivery of domain events to webhooks, retried with exponential backoff.
+Webhooks: {
+	TimeoutSeconds: 10
+	MaxAttempts:    8
+	PollSeconds:    15
+	RetentionDays:  30
+
+	// Webhooks may only reach public https endpoints, unless this is set.
+	AllowPrivateTargets: false
+}
@@ -0,0 +1,32 @@
+package app
+
+import "encore.dev/config"
+
+// Config is the configuration of the app service, see config.cue.
+type Config struct {
+	// Outbox publishes the domain events written by the endpoints.
+	Outbox struct {
+		// PollSeconds is how often the outbox is checked for events that
+		// were not published right after their transaction.
+		PollSeconds config.Int
+		// RetentionHours is how long published events are kept.
+		RetentionHours config.Int
+	}
+
+	// Webhooks sends the domain events to the webhooks of each site.
+	Webhooks struct {
+		// TimeoutSeconds bounds each attempt to deliver an event.
+		TimeoutSeconds config.Int
+		// MaxAttempts is how often a delivery is tried before it fails.
+		MaxAttempts config.Int
+		// PollSeconds is how often deliveries due for a retry are sent.
+		PollSeconds config.Int
+		// RetentionDays is how long finished deliveries stay in the log.
+		RetentionDays config.Int
+		// AllowPrivateTargets lets webhooks use http:// and reach private,
+		// loopback and link-local addresses, e.g. for local development.
+		AllowPrivateTargets config.Bool
+	}
+}
+
+var cfg = config.Load[*Config]()
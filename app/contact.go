@@ -0,0 +1,130 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Statuses of contact messages. Archived messages are neither read nor
+// unread.
+const (
+	MessageUnread   = "UNREAD"
+	MessageRead     = "READ"
+	MessageArchived = "ARCHIVED"
+)
+
+type ListContactMessagesParams struct {
+	// Status limits the list to messages of that status. Empty lists the
+	// messages that are not archived.
+	Status string `query:"status"`
+	// Limit is the most messages listed, if set.
+	Limit int `query:"limit"`
+}
+
+type ListContactMessagesResponse struct {
+	Messages []*ContactMessage `json:"messages"`
+}
+
+// ListContactMessages lists the contact messages of a site, newest first.
+//
+//encore:api private method=GET path=/sites/:siteID/contact-messages
+func ListContactMessages(ctx context.Context, siteID uint, p *ListContactMessagesParams) (*ListContactMessagesResponse, error) {
+	db, err := session(ctx, siteID)
+	if err != nil {
+		return nil, err
+	}
+	q := db.Order("id DESC")
+	if p.Limit > 0 {
+		q = q.Limit(p.Limit)
+	}
+	switch p.Status {
+	case "":
+		q = q.Where("archived_at IS NULL")
+	case MessageUnread:
+		q = q.Where("archived_at IS NULL AND read_at IS NULL")
+	case MessageRead:
+		q = q.Where("archived_at IS NULL AND read_at IS NOT NULL")
+	case MessageArchived:
+		q = q.Where("archived_at IS NOT NULL")
+	default:
+		return nil, invalidArgument("status", "unknown status "+p.Status)
+	}
+	msgs := []*ContactMessage{}
+	if err := q.Find(&msgs).Error; err != nil {
+		return nil, err
+	}
+	return &ListContactMessagesResponse{Messages: msgs}, nil
+}
+
+type CreateContactMessageParams struct {
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// CreateContactMessage stores a message sent through the contact form of a
+// site.
+//
+//encore:api private method=POST path=/sites/:siteID/contact-messages
+func CreateContactMessage(ctx context.Context, siteID uint, p *CreateContactMessageParams) (*ContactMessage, error) {
+	msg := &ContactMessage{Name: p.Name, Email: p.Email, Subject: p.Subject, Body: p.Body}
+	err := transaction(ctx, siteID, func(tx *gorm.DB) error {
+		return tx.Create(msg).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// UpdateContactMessageParams changes the flags that are set.
+type UpdateContactMessageParams struct {
+	Read     *bool `json:"read,omitempty"`
+	Archived *bool `json:"archived,omitempty"`
+}
+
+// UpdateContactMessage marks a contact message as read or archived, or
+// undoes that. Marking it again keeps the time it was first marked.
+//
+//encore:api private method=PATCH path=/sites/:siteID/contact-messages/:id
+func UpdateContactMessage(ctx context.Context, siteID, id uint, p *UpdateContactMessageParams) (*ContactMessage, error) {
+	var msg ContactMessage
+	err := transaction(ctx, siteID, func(tx *gorm.DB) error {
+		if err := tx.First(&msg, id).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		update := map[string]any{}
+		if p.Read != nil {
+			msg.ReadAt = mark(msg.ReadAt, *p.Read, now)
+			update["read_at"] = msg.ReadAt
+		}
+		if p.Archived != nil {
+			msg.ArchivedAt = mark(msg.ArchivedAt, *p.Archived, now)
+			update["archived_at"] = msg.ArchivedAt
+		}
+		if len(update) == 0 {
+			return nil
+		}
+		return tx.Model(&msg).Updates(update).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// mark returns when a flag was set: at if it already was, now if it is set
+// now, and nil if it is cleared.
+func mark(at *time.Time, set bool, now time.Time) *time.Time {
+	switch {
+	case !set:
+		return nil
+	case at != nil:
+		return at
+	}
+	return &now
+}
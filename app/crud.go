@@ -0,0 +1,140 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate go run ./scripts/crudgen
+
+// The endpoints in crud_gen.go are generated by scripts/crudgen for the models
+// marked //crud:generate, see "Generated Content Types" in the README. They
+// share the helpers below, which publish their changes to ContentEvents.
+
+// crudRecord is implemented by the generated models.
+type crudRecord interface {
+	// crudKey returns the type name in the global ID of the record, its
+	// primary key and its site.
+	crudKey() (typ string, id, siteID uint)
+}
+
+// contentEvent enqueues the ContentEvent of typ about record.
+func contentEvent(tx *gorm.DB, typ EventType, record crudRecord) error {
+	snapshot, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	name, id, siteID := record.crudKey()
+	return enqueue(tx, TopicContentEvents, &ContentEvent{
+		Meta:   newEventMeta(typ, siteID),
+		Type:   name,
+		ID:     GlobalID(name, id),
+		Record: snapshot,
+	})
+}
+
+// listRecords lists the records of a site that match f, by ID. With after
+// set, only records with a greater ID are listed, and with limit set, at most
+// that many.
+func listRecords[T any](ctx context.Context, siteID uint, f *filter, after uint, limit int) ([]*T, error) {
+	db, err := session(ctx, siteID)
+	if err != nil {
+		return nil, err
+	}
+	if after != 0 {
+		f.add("id > ?", after)
+	}
+	db = f.apply(db).Order("id")
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	records := []*T{}
+	if err := db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// getRecord returns the record id of a site, or NotFound.
+func getRecord[T any](ctx context.Context, siteID, id uint) (*T, error) {
+	db, err := session(ctx, siteID)
+	if err != nil {
+		return nil, err
+	}
+	var record T
+	if err := db.First(&record, id).Error; err != nil {
+		return nil, DBError(err)
+	}
+	return &record, nil
+}
+
+// createRecord inserts record into a site and publishes typ.
+func createRecord[T any, P interface {
+	*T
+	crudRecord
+}](ctx context.Context, siteID uint, typ EventType, record P) (P, error) {
+	err := transaction(ctx, siteID, func(tx *gorm.DB) error {
+		if err := tx.Create(record).Error; err != nil {
+			return err
+		}
+		return contentEvent(tx, typ, record)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// updateRecord applies patch to the record id of a site, see saveVersioned,
+// and publishes typ. With expected set, the update is rejected with Aborted
+// unless the record is still at that version.
+func updateRecord[T any, P interface {
+	*T
+	versioned
+	crudRecord
+}](ctx context.Context, siteID, id uint, typ EventType, expected *int, patch func(P)) (P, error) {
+	record := P(new(T))
+	err := transaction(ctx, siteID, func(tx *gorm.DB) error {
+		if err := tx.First(record, id).Error; err != nil {
+			return err
+		}
+		if err := checkVersion(record, expected); err != nil {
+			return err
+		}
+		patch(record)
+		if err := saveVersioned(tx, record, id); err != nil {
+			return err
+		}
+		return contentEvent(tx, typ, record)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// deleteRecord deletes the record id of a site, publishes typ and returns the
+// record.
+func deleteRecord[T any, P interface {
+	*T
+	crudRecord
+}](ctx context.Context, siteID, id uint, typ EventType) (P, error) {
+	record := P(new(T))
+	err := transaction(ctx, siteID, func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.Returning{}).Delete(record, id)
+		if res.Error != nil {
+			return stillReferenced(res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return contentEvent(tx, typ, record)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
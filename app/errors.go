@@ -0,0 +1,99 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"encore.dev/beta/errs"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Postgres SQLSTATE codes that are caused by client input rather than by a bug.
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+// FieldDetails names the request field an error refers to.
+type FieldDetails struct {
+	Field string `json:"field"`
+}
+
+func (FieldDetails) ErrDetails() {}
+
+// VersionDetails is attached to the Aborted error of an update that was based
+// on another version of the record than the current one.
+type VersionDetails struct {
+	Current int `json:"current"`
+}
+
+func (VersionDetails) ErrDetails() {}
+
+// invalidArgument returns an InvalidArgument error about the given field.
+func invalidArgument(field, msg string) error {
+	return &errs.Error{Code: errs.InvalidArgument, Message: msg, Details: FieldDetails{Field: field}}
+}
+
+// versionConflict returns the error of an update based on a stale version.
+func versionConflict(current int) error {
+	return &errs.Error{
+		Code:    errs.Aborted,
+		Message: fmt.Sprintf("record was modified concurrently and is now at version %d", current),
+		Details: VersionDetails{Current: current},
+	}
+}
+
+// DBError turns the database errors that are caused by the request, such as a
+// missing record or a duplicate value, into errs.Error values with a fitting
+// code. Other errors are returned unchanged.
+func DBError(err error) error {
+	var pgErr *pgconn.PgError
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return &errs.Error{Code: errs.NotFound, Message: "not found"}
+	case errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation:
+		field := constraintField(pgErr.TableName, pgErr.ConstraintName)
+		return &errs.Error{Code: errs.AlreadyExists, Message: field + " is already taken", Details: FieldDetails{Field: field}}
+	case errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation:
+		field := foreignKeyField(pgErr.ConstraintName)
+		return &errs.Error{Code: errs.InvalidArgument, Message: field + " does not refer to an existing record", Details: FieldDetails{Field: field}}
+	}
+	return err
+}
+
+// stillReferenced turns the foreign key violation of a delete into an Aborted
+// error. Postgres reports the referencing table and constraint for violations
+// on either end of a foreign key, so only the statement tells them apart:
+// a delete violates a key by removing a record others still refer to.
+func stillReferenced(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+		return &errs.Error{Code: errs.Aborted, Message: "record is still referenced by " + pgErr.TableName}
+	}
+	return err
+}
+
+// foreignKeyField derives the field name from a GORM foreign key name, which
+// is made of the referenced table and the association, e.g.
+// "fk_users_projects" becomes "userID".
+func foreignKeyField(constraint string) string {
+	table, _, _ := strings.Cut(strings.TrimPrefix(constraint, "fk_"), "_")
+	return strings.TrimSuffix(table, "s") + "ID"
+}
+
+// constraintField derives the field name from a GORM index name, e.g.
+// "idx_users_email" on "users" becomes "email".
+func constraintField(table, constraint string) string {
+	name := strings.TrimPrefix(constraint, "idx_"+table+"_")
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
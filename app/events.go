@@ -0,0 +1,253 @@
+package app
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"encore.dev/beta/auth"
+	"encore.dev/pubsub"
+	"gorm.io/gorm"
+)
+
+// Names of the topics domain events are published to.
+const (
+	TopicUserEvents    = "user-events"
+	TopicProjectEvents = "project-events"
+	TopicBlogEvents    = "blog-events"
+	TopicResumeEvents  = "resume-events"
+	TopicContentEvents = "content-events"
+)
+
+// Topics of the domain events. Events are published at least once after the
+// change they describe has been committed, so subscribers should use
+// EventMeta.ID to ignore redeliveries.
+var (
+	UserEvents    = pubsub.NewTopic[*UserEvent](TopicUserEvents, pubsub.TopicConfig{DeliveryGuarantee: pubsub.AtLeastOnce})
+	ProjectEvents = pubsub.NewTopic[*ProjectEvent](TopicProjectEvents, pubsub.TopicConfig{DeliveryGuarantee: pubsub.AtLeastOnce})
+	BlogEvents    = pubsub.NewTopic[*BlogEvent](TopicBlogEvents, pubsub.TopicConfig{DeliveryGuarantee: pubsub.AtLeastOnce})
+	ResumeEvents  = pubsub.NewTopic[*ResumeEvent](TopicResumeEvents, pubsub.TopicConfig{DeliveryGuarantee: pubsub.AtLeastOnce})
+	// ContentEvents carries the events of the generated content types.
+	ContentEvents = pubsub.NewTopic[*ContentEvent](TopicContentEvents, pubsub.TopicConfig{DeliveryGuarantee: pubsub.AtLeastOnce})
+)
+
+// EventType tells what happened to the record of an event.
+type EventType string
+
+const (
+	UserCreated    EventType = "UserCreated"
+	UserUpdated    EventType = "UserUpdated"
+	UserDeleted    EventType = "UserDeleted"
+	ProjectCreated EventType = "ProjectCreated"
+	ProjectUpdated EventType = "ProjectUpdated"
+	ProjectDeleted EventType = "ProjectDeleted"
+	BlogCreated    EventType = "BlogCreated"
+	BlogUpdated    EventType = "BlogUpdated"
+	BlogDeleted    EventType = "BlogDeleted"
+	BlogPublished  EventType = "BlogPublished"
+	ResumeCreated  EventType = "ResumeCreated"
+	ResumeUpdated  EventType = "ResumeUpdated"
+	ResumeDeleted  EventType = "ResumeDeleted"
+)
+
+// EventTypes lists every EventType, including those of the generated content
+// types in crud_gen.go.
+var EventTypes = slices.Concat([]EventType{
+	UserCreated, UserUpdated, UserDeleted,
+	ProjectCreated, ProjectUpdated, ProjectDeleted,
+	BlogCreated, BlogUpdated, BlogDeleted, BlogPublished,
+	ResumeCreated, ResumeUpdated, ResumeDeleted,
+}, crudEventTypes)
+
+// EventMeta is common to all domain events.
+type EventMeta struct {
+	// ID is unique to the event and stays the same on redelivery.
+	ID   string
+	Type EventType
+	// SiteID is the site the record belongs to.
+	SiteID uint
+	// Actor is the auth UID of the caller that made the change: "admin",
+	// "apikey:<id>", or empty for anonymous callers.
+	Actor      string
+	OccurredAt time.Time
+}
+
+// UserEvent is published to UserEvents when a user is created, updated or
+// deleted.
+type UserEvent struct {
+	Meta EventMeta
+	User UserSnapshot
+	// Deletion describes what happened to the projects of a deleted user.
+	Deletion *UserDeletion `json:",omitempty"`
+}
+
+type UserSnapshot struct {
+	ID        string
+	Name      string
+	Email     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Version   int
+}
+
+type UserDeletion struct {
+	// Strategy is the DeleteUserStrategy that was used.
+	Strategy string
+	// ProjectIDs are the projects the user owned, which were deleted with
+	// CASCADE or moved to ReassignedTo with REASSIGN.
+	ProjectIDs   []string
+	ReassignedTo *string `json:",omitempty"`
+}
+
+// ProjectEvent is published to ProjectEvents when a project is created,
+// updated or deleted.
+type ProjectEvent struct {
+	Meta    EventMeta
+	Project ProjectSnapshot
+}
+
+type ProjectSnapshot struct {
+	ID          string
+	UserID      string
+	Title       string
+	Description string
+	Hidden      bool
+	UpdatedAt   time.Time
+	Version     int
+}
+
+// BlogEvent is published to BlogEvents when a blog post is created, updated,
+// deleted or published. BlogPublished follows the BlogCreated or BlogUpdated
+// of the change that made a post public: its creation unless it is a draft,
+// or the update that ended its draft.
+type BlogEvent struct {
+	Meta EventMeta
+	Blog BlogSnapshot
+}
+
+type BlogSnapshot struct {
+	ID        string
+	Title     string
+	Content   string
+	CreatedAt time.Time
+	Draft     bool
+	UpdatedAt time.Time
+	Version   int
+}
+
+// ResumeEvent is published to ResumeEvents when a resume section is created,
+// updated or deleted.
+type ResumeEvent struct {
+	Meta   EventMeta
+	Resume ResumeSnapshot
+}
+
+type ResumeSnapshot struct {
+	ID          string
+	Title       string
+	Description string
+	Category    string
+	StartDate   *time.Time
+	EndDate     *time.Time
+	UpdatedAt   time.Time
+	Version     int
+}
+
+// ContentEvent is published to ContentEvents when a record of a generated
+// content type is created, updated or deleted. Meta.Type is the type name
+// followed by Created, Updated or Deleted, e.g. TestimonialCreated.
+type ContentEvent struct {
+	Meta EventMeta
+	// Type is the GraphQL type of the record, and ID its global ID.
+	Type string
+	ID   string
+	// Record is the JSON of the model, with its Go field names.
+	Record json.RawMessage
+}
+
+func newEventMeta(typ EventType, siteID uint) EventMeta {
+	var id [16]byte
+	rand.Read(id[:])
+	// The auth data of the caller is passed along service calls.
+	uid, _ := auth.UserID()
+	return EventMeta{
+		ID:         hex.EncodeToString(id[:]),
+		Type:       typ,
+		SiteID:     siteID,
+		Actor:      string(uid),
+		OccurredAt: time.Now().UTC(),
+	}
+}
+
+// enqueue adds msg to the outbox, to be published to topic once tx commits.
+// tx must be a transaction that also makes the change msg describes.
+func enqueue(tx *gorm.DB, topic string, msg any) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&OutboxEvent{Topic: topic, Payload: payload}).Error
+}
+
+func userEvent(tx *gorm.DB, typ EventType, u *User, deletion *UserDeletion) error {
+	return enqueue(tx, TopicUserEvents, &UserEvent{
+		Meta: newEventMeta(typ, u.SiteID),
+		User: UserSnapshot{
+			ID:        GlobalID(TypeUser, u.ID),
+			Name:      u.Name,
+			Email:     u.Email,
+			CreatedAt: u.CreatedAt,
+			UpdatedAt: u.UpdatedAt,
+			Version:   u.Version,
+		},
+		Deletion: deletion,
+	})
+}
+
+func projectEvent(tx *gorm.DB, typ EventType, p *Project) error {
+	return enqueue(tx, TopicProjectEvents, &ProjectEvent{
+		Meta: newEventMeta(typ, p.SiteID),
+		Project: ProjectSnapshot{
+			ID:          GlobalID(TypeProject, p.ID),
+			UserID:      GlobalID(TypeUser, p.UserID),
+			Title:       p.Title,
+			Description: p.Description,
+			Hidden:      p.Hidden,
+			UpdatedAt:   p.UpdatedAt,
+			Version:     p.Version,
+		},
+	})
+}
+
+func blogEvent(tx *gorm.DB, typ EventType, b *Blog) error {
+	return enqueue(tx, TopicBlogEvents, &BlogEvent{
+		Meta: newEventMeta(typ, b.SiteID),
+		Blog: BlogSnapshot{
+			ID:        GlobalID(TypeBlog, b.ID),
+			Title:     b.Title,
+			Content:   b.Content,
+			CreatedAt: b.CreatedAt,
+			Draft:     b.Draft,
+			UpdatedAt: b.UpdatedAt,
+			Version:   b.Version,
+		},
+	})
+}
+
+func resumeEvent(tx *gorm.DB, typ EventType, rs *Resume) error {
+	return enqueue(tx, TopicResumeEvents, &ResumeEvent{
+		Meta: newEventMeta(typ, rs.SiteID),
+		Resume: ResumeSnapshot{
+			ID:          GlobalID(TypeResume, rs.ID),
+			Title:       rs.Title,
+			Description: rs.Description,
+			Category:    rs.Category,
+			StartDate:   rs.StartDate,
+			EndDate:     rs.EndDate,
+			UpdatedAt:   rs.UpdatedAt,
+			Version:     rs.Version,
+		},
+	})
+}
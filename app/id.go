@@ -0,0 +1,39 @@
+package app
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+)
+
+// Type names used as the prefix of global IDs.
+const (
+	TypeUser    = "User"
+	TypeProject = "Project"
+	TypeBlog    = "Blog"
+	TypeResume  = "Resume"
+)
+
+// GlobalID returns the opaque ID of a record: the base64 encoding of its type
+// name and primary key, e.g. "User:42". Prefixing the type keeps IDs unique
+// across tables, so that the record can be found again by its ID alone.
+func GlobalID(typ string, pk uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(typ + ":" + strconv.FormatUint(uint64(pk), 10)))
+}
+
+// DecodeGlobalID splits a global ID into its type name and primary key.
+func DecodeGlobalID(id string) (typ string, pk uint, ok bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(id)
+	if err != nil {
+		return "", 0, false
+	}
+	typ, key, found := strings.Cut(string(raw), ":")
+	if !found || typ == "" {
+		return "", 0, false
+	}
+	n, err := strconv.ParseUint(key, 10, 64)
+	if err != nil || n == 0 {
+		return "", 0, false
+	}
+	return typ, uint(n), true
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"encore.dev/cron"
+)
+
+// cacheInvalidationRetention is how long invalidations are kept. Instances
+// that poll less often than that may miss some.
+const cacheInvalidationRetention = time.Hour
+
+type InvalidateCacheParams struct {
+	Tags []string `json:"tags"`
+}
+
+// InvalidateCache records that the cached responses tagged with any of Tags
+// are stale, for every instance of the graphql service to drop them.
+//
+//encore:api private method=POST path=/cache-invalidations
+func InvalidateCache(ctx context.Context, p *InvalidateCacheParams) error {
+	if len(p.Tags) == 0 {
+		return nil
+	}
+	db, err := database()
+	if err != nil {
+		return err
+	}
+	return db.WithContext(ctx).Create(&CacheInvalidation{Tags: strings.Join(p.Tags, " ")}).Error
+}
+
+type CacheInvalidationsParams struct {
+	// Since limits the list to the invalidations recorded after it.
+	Since time.Time `query:"since"`
+}
+
+type CacheInvalidationsResponse struct {
+	// Tags are the tags invalidated since Since, each once.
+	Tags []string `json:"tags"`
+	// Until is the time of the database the list was taken at. Invalidations
+	// whose transaction was still running then may be recorded with an
+	// earlier time, so the next Since should be somewhat before it.
+	Until time.Time `json:"until"`
+}
+
+// CacheInvalidations lists the cache tags invalidated since Since.
+//
+//encore:api private method=GET path=/cache-invalidations
+func CacheInvalidations(ctx context.Context, p *CacheInvalidationsParams) (*CacheInvalidationsResponse, error) {
+	db, err := database()
+	if err != nil {
+		return nil, err
+	}
+	db = db.WithContext(ctx)
+	resp := &CacheInvalidationsResponse{Tags: []string{}}
+	if err := db.Raw("SELECT now()").Scan(&resp.Until).Error; err != nil {
+		return nil, err
+	}
+	var lists []string
+	err = db.Model(&CacheInvalidation{}).Where("invalidated_at > ?", p.Since).Pluck("tags", &lists).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, tags := range lists {
+		resp.Tags = append(resp.Tags, strings.Fields(tags)...)
+	}
+	slices.Sort(resp.Tags)
+	resp.Tags = slices.Compact(resp.Tags)
+	return resp, nil
+}
+
+var _ = cron.NewJob("prune-cache-invalidations", cron.JobConfig{
+	Title:    "Delete old cache invalidations",
+	Every:    1 * cron.Hour,
+	Endpoint: PruneCacheInvalidations,
+})
+
+// PruneCacheInvalidations deletes the invalidations older than the retention.
+//
+//encore:api private method=POST path=/cache-invalidations/prune
+func PruneCacheInvalidations(ctx context.Context) error {
+	db, err := database()
+	if err != nil {
+		return err
+	}
+	return db.WithContext(ctx).
+		Where("invalidated_at < ?", time.Now().Add(-cacheInvalidationRetention)).
+		Delete(&CacheInvalidation{}).Error
+}
@@ -0,0 +1,2 @@
+-- reverse: modify "resumes" table
+ALTER TABLE "resumes" DROP COLUMN "end_date", DROP COLUMN "start_date";
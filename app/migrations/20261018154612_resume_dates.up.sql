@@ -0,0 +1,2 @@
+-- modify "resumes" table
+ALTER TABLE "resumes" ADD COLUMN "start_date" date NULL, ADD COLUMN "end_date" date NULL;
@@ -0,0 +1,2 @@
+-- reverse: modify "projects" table
+ALTER TABLE "projects" DROP CONSTRAINT "fk_users_projects";
@@ -0,0 +1,15 @@
+-- Projects whose user was deleted before the foreign key existed would
+-- violate it. They are not deleted here: the migration stops and lists them,
+-- so that an operator can reassign or delete them before running it again.
+DO $$
+DECLARE
+  orphans text;
+BEGIN
+  SELECT string_agg("id"::text, ', ' ORDER BY "id") INTO orphans
+  FROM "projects" WHERE "user_id" IS NOT NULL AND "user_id" NOT IN (SELECT "id" FROM "users");
+  IF orphans IS NOT NULL THEN
+    RAISE EXCEPTION 'projects % refer to users that do not exist; reassign them to an existing user or delete them, then migrate again', orphans;
+  END IF;
+END $$;
+-- modify "projects" table
+ALTER TABLE "projects" ADD CONSTRAINT "fk_users_projects" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE RESTRICT;
@@ -0,0 +1,8 @@
+-- reverse: modify "users" table
+ALTER TABLE "users" DROP COLUMN "updated_at", DROP COLUMN "version";
+-- reverse: modify "resumes" table
+ALTER TABLE "resumes" DROP COLUMN "updated_at", DROP COLUMN "version";
+-- reverse: modify "projects" table
+ALTER TABLE "projects" DROP COLUMN "updated_at", DROP COLUMN "version";
+-- reverse: modify "blogs" table
+ALTER TABLE "blogs" DROP COLUMN "updated_at", DROP COLUMN "version";
@@ -0,0 +1,13 @@
+-- modify "blogs" table
+ALTER TABLE "blogs" ADD COLUMN "version" bigint NOT NULL DEFAULT 1, ADD COLUMN "updated_at" timestamptz NULL;
+-- modify "projects" table
+ALTER TABLE "projects" ADD COLUMN "version" bigint NOT NULL DEFAULT 1, ADD COLUMN "updated_at" timestamptz NULL;
+-- modify "resumes" table
+ALTER TABLE "resumes" ADD COLUMN "version" bigint NOT NULL DEFAULT 1, ADD COLUMN "updated_at" timestamptz NULL;
+-- modify "users" table
+ALTER TABLE "users" ADD COLUMN "version" bigint NOT NULL DEFAULT 1, ADD COLUMN "updated_at" timestamptz NULL;
+-- backfill "updated_at" of existing rows
+UPDATE "blogs" SET "updated_at" = COALESCE("created_at", now());
+UPDATE "projects" SET "updated_at" = now();
+UPDATE "resumes" SET "updated_at" = now();
+UPDATE "users" SET "updated_at" = COALESCE("created_at", now());
@@ -0,0 +1,15 @@
+-- reverse: modify "users" table
+DROP INDEX "idx_users_email";
+CREATE UNIQUE INDEX "idx_users_email" ON "users" ("email");
+ALTER TABLE "users" DROP CONSTRAINT "fk_sites_users", DROP COLUMN "site_id";
+-- reverse: modify "resumes" table
+DROP INDEX "idx_resumes_site_id";
+ALTER TABLE "resumes" DROP CONSTRAINT "fk_sites_resumes", DROP COLUMN "site_id";
+-- reverse: modify "projects" table
+DROP INDEX "idx_projects_site_id";
+ALTER TABLE "projects" DROP CONSTRAINT "fk_sites_projects", DROP COLUMN "site_id";
+-- reverse: modify "blogs" table
+DROP INDEX "idx_blogs_site_id";
+ALTER TABLE "blogs" DROP CONSTRAINT "fk_sites_blogs", DROP COLUMN "site_id";
+-- reverse: create "sites" table
+DROP TABLE "sites";
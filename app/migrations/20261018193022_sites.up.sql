@@ -0,0 +1,40 @@
+-- create "sites" table
+CREATE TABLE "sites" (
+  "id" bigserial NOT NULL,
+  "slug" text NOT NULL,
+  "name" text NULL,
+  "domain" text NULL,
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("id")
+);
+-- create index "idx_sites_domain" to table: "sites"
+CREATE UNIQUE INDEX "idx_sites_domain" ON "sites" ("domain");
+-- create index "idx_sites_slug" to table: "sites"
+CREATE UNIQUE INDEX "idx_sites_slug" ON "sites" ("slug");
+-- existing content belongs to the default site
+INSERT INTO "sites" ("slug", "name", "created_at") VALUES ('default', 'Default', now());
+-- modify "blogs" table
+ALTER TABLE "blogs" ADD COLUMN "site_id" bigint NULL;
+UPDATE "blogs" SET "site_id" = (SELECT "id" FROM "sites" WHERE "slug" = 'default');
+ALTER TABLE "blogs" ALTER COLUMN "site_id" SET NOT NULL, ADD CONSTRAINT "fk_sites_blogs" FOREIGN KEY ("site_id") REFERENCES "sites" ("id") ON UPDATE CASCADE ON DELETE RESTRICT;
+-- create index "idx_blogs_site_id" to table: "blogs"
+CREATE INDEX "idx_blogs_site_id" ON "blogs" ("site_id");
+-- modify "projects" table
+ALTER TABLE "projects" ADD COLUMN "site_id" bigint NULL;
+UPDATE "projects" SET "site_id" = (SELECT "id" FROM "sites" WHERE "slug" = 'default');
+ALTER TABLE "projects" ALTER COLUMN "site_id" SET NOT NULL, ADD CONSTRAINT "fk_sites_projects" FOREIGN KEY ("site_id") REFERENCES "sites" ("id") ON UPDATE CASCADE ON DELETE RESTRICT;
+-- create index "idx_projects_site_id" to table: "projects"
+CREATE INDEX "idx_projects_site_id" ON "projects" ("site_id");
+-- modify "resumes" table
+ALTER TABLE "resumes" ADD COLUMN "site_id" bigint NULL;
+UPDATE "resumes" SET "site_id" = (SELECT "id" FROM "sites" WHERE "slug" = 'default');
+ALTER TABLE "resumes" ALTER COLUMN "site_id" SET NOT NULL, ADD CONSTRAINT "fk_sites_resumes" FOREIGN KEY ("site_id") REFERENCES "sites" ("id") ON UPDATE CASCADE ON DELETE RESTRICT;
+-- create index "idx_resumes_site_id" to table: "resumes"
+CREATE INDEX "idx_resumes_site_id" ON "resumes" ("site_id");
+-- modify "users" table
+ALTER TABLE "users" ADD COLUMN "site_id" bigint NULL;
+UPDATE "users" SET "site_id" = (SELECT "id" FROM "sites" WHERE "slug" = 'default');
+ALTER TABLE "users" ALTER COLUMN "site_id" SET NOT NULL, ADD CONSTRAINT "fk_sites_users" FOREIGN KEY ("site_id") REFERENCES "sites" ("id") ON UPDATE CASCADE ON DELETE RESTRICT;
+-- emails are unique per site
+DROP INDEX "idx_users_email";
+CREATE UNIQUE INDEX "idx_users_email" ON "users" ("site_id", "email");
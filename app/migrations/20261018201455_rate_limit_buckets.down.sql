@@ -0,0 +1,2 @@
+-- reverse: create "rate_limit_buckets" table
+DROP TABLE "rate_limit_buckets";
@@ -0,0 +1,7 @@
+-- create "rate_limit_buckets" table
+CREATE TABLE "rate_limit_buckets" (
+  "key" text NOT NULL,
+  "tokens" double precision NULL,
+  "updated_at" timestamptz NULL,
+  PRIMARY KEY ("key")
+);
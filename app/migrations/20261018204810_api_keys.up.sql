@@ -0,0 +1,19 @@
+-- create "api_keys" table
+CREATE TABLE "api_keys" (
+  "id" bigserial NOT NULL,
+  "site_id" bigint NOT NULL,
+  "name" text NULL,
+  "prefix" text NULL,
+  "hash" text NOT NULL,
+  "scopes" text NULL,
+  "created_at" timestamptz NULL,
+  "expires_at" timestamptz NULL,
+  "last_used_at" timestamptz NULL,
+  "revoked_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_sites_api_keys" FOREIGN KEY ("site_id") REFERENCES "sites" ("id") ON UPDATE CASCADE ON DELETE RESTRICT
+);
+-- create index "idx_api_keys_hash" to table: "api_keys"
+CREATE UNIQUE INDEX "idx_api_keys_hash" ON "api_keys" ("hash");
+-- create index "idx_api_keys_site_id" to table: "api_keys"
+CREATE INDEX "idx_api_keys_site_id" ON "api_keys" ("site_id");
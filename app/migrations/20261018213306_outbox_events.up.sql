@@ -0,0 +1,17 @@
+-- create "outbox_events" table
+CREATE TABLE "outbox_events" (
+  "id" bigserial NOT NULL,
+  "site_id" bigint NOT NULL,
+  "topic" text NOT NULL,
+  "payload" jsonb NOT NULL,
+  "created_at" timestamptz NULL,
+  "published_at" timestamptz NULL,
+  "attempts" bigint NOT NULL DEFAULT 0,
+  "last_error" text NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_sites_outbox" FOREIGN KEY ("site_id") REFERENCES "sites" ("id") ON UPDATE CASCADE ON DELETE RESTRICT
+);
+-- create index "idx_outbox_events_pending" to table: "outbox_events"
+CREATE INDEX "idx_outbox_events_pending" ON "outbox_events" ("published_at") WHERE (published_at IS NULL);
+-- create index "idx_outbox_events_site_id" to table: "outbox_events"
+CREATE INDEX "idx_outbox_events_site_id" ON "outbox_events" ("site_id");
@@ -0,0 +1,4 @@
+-- reverse: create "webhook_deliveries" table
+DROP TABLE "webhook_deliveries";
+-- reverse: create "webhooks" table
+DROP TABLE "webhooks";
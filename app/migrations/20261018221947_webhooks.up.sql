@@ -0,0 +1,37 @@
+-- create "webhooks" table
+CREATE TABLE "webhooks" (
+  "id" bigserial NOT NULL,
+  "site_id" bigint NOT NULL,
+  "url" text NOT NULL,
+  "events" text NOT NULL,
+  "secret" text NOT NULL,
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_sites_webhooks" FOREIGN KEY ("site_id") REFERENCES "sites" ("id") ON UPDATE CASCADE ON DELETE RESTRICT
+);
+-- create index "idx_webhooks_site_id" to table: "webhooks"
+CREATE INDEX "idx_webhooks_site_id" ON "webhooks" ("site_id");
+-- create "webhook_deliveries" table
+CREATE TABLE "webhook_deliveries" (
+  "id" bigserial NOT NULL,
+  "site_id" bigint NOT NULL,
+  "webhook_id" bigint NOT NULL,
+  "event_id" text NOT NULL,
+  "event_type" text NOT NULL,
+  "payload" jsonb NOT NULL,
+  "status" text NOT NULL,
+  "attempts" bigint NOT NULL DEFAULT 0,
+  "next_attempt_at" timestamptz NULL,
+  "response_status" bigint NULL,
+  "last_error" text NULL,
+  "created_at" timestamptz NULL,
+  "delivered_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_webhooks_deliveries" FOREIGN KEY ("webhook_id") REFERENCES "webhooks" ("id") ON UPDATE CASCADE ON DELETE CASCADE
+);
+-- create index "idx_webhook_deliveries_due" to table: "webhook_deliveries"
+CREATE INDEX "idx_webhook_deliveries_due" ON "webhook_deliveries" ("status", "next_attempt_at");
+-- create index "idx_webhook_deliveries_event" to table: "webhook_deliveries"
+CREATE UNIQUE INDEX "idx_webhook_deliveries_event" ON "webhook_deliveries" ("webhook_id", "event_id");
+-- create index "idx_webhook_deliveries_site_id" to table: "webhook_deliveries"
+CREATE INDEX "idx_webhook_deliveries_site_id" ON "webhook_deliveries" ("site_id");
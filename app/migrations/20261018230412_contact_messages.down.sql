@@ -0,0 +1,2 @@
+-- reverse: create "contact_messages" table
+DROP TABLE "contact_messages";
@@ -0,0 +1,16 @@
+-- create "contact_messages" table
+CREATE TABLE "contact_messages" (
+  "id" bigserial NOT NULL,
+  "site_id" bigint NOT NULL,
+  "name" text NOT NULL,
+  "email" text NOT NULL,
+  "subject" text NOT NULL,
+  "body" text NOT NULL,
+  "created_at" timestamptz NULL,
+  "read_at" timestamptz NULL,
+  "archived_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_sites_messages" FOREIGN KEY ("site_id") REFERENCES "sites" ("id") ON UPDATE CASCADE ON DELETE RESTRICT
+);
+-- create index "idx_contact_messages_site_id" to table: "contact_messages"
+CREATE INDEX "idx_contact_messages_site_id" ON "contact_messages" ("site_id");
@@ -0,0 +1,2 @@
+-- reverse: create "newsletter_subscribers" table
+DROP TABLE "newsletter_subscribers";
@@ -0,0 +1,13 @@
+-- create "newsletter_subscribers" table
+CREATE TABLE "newsletter_subscribers" (
+  "id" bigserial NOT NULL,
+  "site_id" bigint NOT NULL,
+  "email" text NOT NULL,
+  "subscribed_at" timestamptz NOT NULL,
+  "confirmed_at" timestamptz NULL,
+  "unsubscribed_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_sites_subscribers" FOREIGN KEY ("site_id") REFERENCES "sites" ("id") ON UPDATE CASCADE ON DELETE RESTRICT
+);
+-- create index "idx_newsletter_subscribers_email" to table: "newsletter_subscribers"
+CREATE UNIQUE INDEX "idx_newsletter_subscribers_email" ON "newsletter_subscribers" ("site_id", "email");
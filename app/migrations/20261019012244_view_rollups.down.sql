@@ -0,0 +1,2 @@
+-- reverse: create "view_rollups" table
+DROP TABLE "view_rollups";
@@ -0,0 +1,13 @@
+-- create "view_rollups" table
+CREATE TABLE "view_rollups" (
+  "site_id" bigint NOT NULL,
+  "entity_type" text NOT NULL,
+  "entity_id" bigint NOT NULL,
+  "day" date NOT NULL,
+  "referrer" text NOT NULL,
+  "views" bigint NOT NULL DEFAULT 0,
+  PRIMARY KEY ("site_id", "entity_type", "entity_id", "day", "referrer"),
+  CONSTRAINT "fk_sites_views" FOREIGN KEY ("site_id") REFERENCES "sites" ("id") ON UPDATE CASCADE ON DELETE RESTRICT
+);
+-- create index "idx_view_rollups_day" to table: "view_rollups"
+CREATE INDEX "idx_view_rollups_day" ON "view_rollups" ("site_id", "entity_type", "day");
@@ -0,0 +1,4 @@
+-- reverse: create "blog_reaction_counts" table
+DROP TABLE "blog_reaction_counts";
+-- reverse: create "blog_reactions" table
+DROP TABLE "blog_reactions";
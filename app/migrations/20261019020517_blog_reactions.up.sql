@@ -0,0 +1,28 @@
+-- create "blog_reactions" table
+CREATE TABLE "blog_reactions" (
+  "id" bigserial NOT NULL,
+  "site_id" bigint NOT NULL,
+  "blog_id" bigint NOT NULL,
+  "kind" text NOT NULL,
+  "reactor" text NOT NULL,
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_blogs_reactions" FOREIGN KEY ("blog_id") REFERENCES "blogs" ("id") ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT "fk_sites_reactions" FOREIGN KEY ("site_id") REFERENCES "sites" ("id") ON UPDATE CASCADE ON DELETE RESTRICT
+);
+-- create index "idx_blog_reactions_reactor" to table: "blog_reactions"
+CREATE UNIQUE INDEX "idx_blog_reactions_reactor" ON "blog_reactions" ("blog_id", "reactor", "kind");
+-- create index "idx_blog_reactions_site_id" to table: "blog_reactions"
+CREATE INDEX "idx_blog_reactions_site_id" ON "blog_reactions" ("site_id");
+-- create "blog_reaction_counts" table
+CREATE TABLE "blog_reaction_counts" (
+  "site_id" bigint NOT NULL,
+  "blog_id" bigint NOT NULL,
+  "kind" text NOT NULL,
+  "count" bigint NOT NULL DEFAULT 0,
+  PRIMARY KEY ("blog_id", "kind"),
+  CONSTRAINT "fk_blogs_reaction_counts" FOREIGN KEY ("blog_id") REFERENCES "blogs" ("id") ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT "fk_sites_reaction_counts" FOREIGN KEY ("site_id") REFERENCES "sites" ("id") ON UPDATE CASCADE ON DELETE RESTRICT
+);
+-- create index "idx_blog_reaction_counts_site_id" to table: "blog_reaction_counts"
+CREATE INDEX "idx_blog_reaction_counts_site_id" ON "blog_reaction_counts" ("site_id");
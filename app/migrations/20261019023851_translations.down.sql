@@ -0,0 +1,6 @@
+-- reverse: create "resume_translations" table
+DROP TABLE "resume_translations";
+-- reverse: create "project_translations" table
+DROP TABLE "project_translations";
+-- reverse: create "blog_translations" table
+DROP TABLE "blog_translations";
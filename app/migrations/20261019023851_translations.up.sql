@@ -0,0 +1,51 @@
+-- create "blog_translations" table
+CREATE TABLE "blog_translations" (
+  "id" bigserial NOT NULL,
+  "site_id" bigint NOT NULL,
+  "blog_id" bigint NOT NULL,
+  "locale" text NOT NULL,
+  "title" text NOT NULL,
+  "content" text NOT NULL,
+  "updated_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_blogs_translations" FOREIGN KEY ("blog_id") REFERENCES "blogs" ("id") ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT "fk_sites_blog_translations" FOREIGN KEY ("site_id") REFERENCES "sites" ("id") ON UPDATE CASCADE ON DELETE RESTRICT
+);
+-- create index "idx_blog_translations_locale" to table: "blog_translations"
+CREATE UNIQUE INDEX "idx_blog_translations_locale" ON "blog_translations" ("blog_id", "locale");
+-- create index "idx_blog_translations_site_id" to table: "blog_translations"
+CREATE INDEX "idx_blog_translations_site_id" ON "blog_translations" ("site_id");
+-- create "project_translations" table
+CREATE TABLE "project_translations" (
+  "id" bigserial NOT NULL,
+  "site_id" bigint NOT NULL,
+  "project_id" bigint NOT NULL,
+  "locale" text NOT NULL,
+  "title" text NOT NULL,
+  "description" text NOT NULL,
+  "updated_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_projects_translations" FOREIGN KEY ("project_id") REFERENCES "projects" ("id") ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT "fk_sites_project_translations" FOREIGN KEY ("site_id") REFERENCES "sites" ("id") ON UPDATE CASCADE ON DELETE RESTRICT
+);
+-- create index "idx_project_translations_locale" to table: "project_translations"
+CREATE UNIQUE INDEX "idx_project_translations_locale" ON "project_translations" ("project_id", "locale");
+-- create index "idx_project_translations_site_id" to table: "project_translations"
+CREATE INDEX "idx_project_translations_site_id" ON "project_translations" ("site_id");
+-- create "resume_translations" table
+CREATE TABLE "resume_translations" (
+  "id" bigserial NOT NULL,
+  "site_id" bigint NOT NULL,
+  "resume_id" bigint NOT NULL,
+  "locale" text NOT NULL,
+  "title" text NOT NULL,
+  "description" text NOT NULL,
+  "updated_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_resumes_translations" FOREIGN KEY ("resume_id") REFERENCES "resumes" ("id") ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT "fk_sites_resume_translations" FOREIGN KEY ("site_id") REFERENCES "sites" ("id") ON UPDATE CASCADE ON DELETE RESTRICT
+);
+-- create index "idx_resume_translations_locale" to table: "resume_translations"
+CREATE UNIQUE INDEX "idx_resume_translations_locale" ON "resume_translations" ("resume_id", "locale");
+-- create index "idx_resume_translations_site_id" to table: "resume_translations"
+CREATE INDEX "idx_resume_translations_site_id" ON "resume_translations" ("site_id");
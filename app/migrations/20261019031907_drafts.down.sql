@@ -0,0 +1,4 @@
+-- reverse: modify "projects" table
+ALTER TABLE "projects" DROP COLUMN "hidden";
+-- reverse: modify "blogs" table
+ALTER TABLE "blogs" DROP COLUMN "draft";
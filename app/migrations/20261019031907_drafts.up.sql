@@ -0,0 +1,4 @@
+-- modify "blogs" table
+ALTER TABLE "blogs" ADD COLUMN "draft" boolean NOT NULL DEFAULT false;
+-- modify "projects" table
+ALTER TABLE "projects" ADD COLUMN "hidden" boolean NOT NULL DEFAULT false;
@@ -0,0 +1,2 @@
+-- reverse: create "newsletter_issues" table
+DROP TABLE "newsletter_issues";
@@ -0,0 +1,15 @@
+-- create "newsletter_issues" table
+CREATE TABLE "newsletter_issues" (
+  "id" bigserial NOT NULL,
+  "site_id" bigint NOT NULL,
+  "event_id" text NOT NULL,
+  "subject" text NOT NULL,
+  "recipients" bigint NOT NULL,
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_sites_newsletter_issues" FOREIGN KEY ("site_id") REFERENCES "sites" ("id") ON UPDATE CASCADE ON DELETE RESTRICT
+);
+-- create index "idx_newsletter_issues_event_id" to table: "newsletter_issues"
+CREATE UNIQUE INDEX "idx_newsletter_issues_event_id" ON "newsletter_issues" ("event_id");
+-- create index "idx_newsletter_issues_site_id" to table: "newsletter_issues"
+CREATE INDEX "idx_newsletter_issues_site_id" ON "newsletter_issues" ("site_id");
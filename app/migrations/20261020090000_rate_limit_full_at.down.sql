@@ -0,0 +1,4 @@
+-- reverse: create index "idx_rate_limit_buckets_full_at" to table: "rate_limit_buckets"
+DROP INDEX "idx_rate_limit_buckets_full_at";
+-- reverse: modify "rate_limit_buckets" table
+ALTER TABLE "rate_limit_buckets" DROP COLUMN "full_at";
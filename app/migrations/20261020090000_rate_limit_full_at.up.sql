@@ -0,0 +1,6 @@
+-- modify "rate_limit_buckets" table
+ALTER TABLE "rate_limit_buckets" ADD COLUMN "full_at" timestamptz NULL;
+-- The rate of the existing buckets is unknown: they are pruned on the next run.
+UPDATE "rate_limit_buckets" SET "full_at" = "updated_at";
+-- create index "idx_rate_limit_buckets_full_at" to table: "rate_limit_buckets"
+CREATE INDEX "idx_rate_limit_buckets_full_at" ON "rate_limit_buckets" ("full_at");
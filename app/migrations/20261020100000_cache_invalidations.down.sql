@@ -0,0 +1,2 @@
+-- reverse: create "cache_invalidations" table
+DROP TABLE "cache_invalidations";
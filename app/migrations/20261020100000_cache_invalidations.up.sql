@@ -0,0 +1,9 @@
+-- create "cache_invalidations" table
+CREATE TABLE "cache_invalidations" (
+  "id" bigserial NOT NULL,
+  "tags" text NOT NULL,
+  "invalidated_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("id")
+);
+-- create index "idx_cache_invalidations_invalidated_at" to table: "cache_invalidations"
+CREATE INDEX "idx_cache_invalidations_invalidated_at" ON "cache_invalidations" ("invalidated_at");
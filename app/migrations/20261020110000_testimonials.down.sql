@@ -0,0 +1,2 @@
+-- reverse: create "testimonials" table
+DROP TABLE "testimonials";
@@ -0,0 +1,16 @@
+-- create "testimonials" table
+CREATE TABLE "testimonials" (
+  "id" bigserial NOT NULL,
+  "site_id" bigint NOT NULL,
+  "author" text NOT NULL,
+  "quote" text NOT NULL,
+  "rating" bigint NOT NULL DEFAULT 0,
+  "given_on" date NULL,
+  "website" text NULL,
+  "created_at" timestamptz NULL,
+  "version" bigint NOT NULL DEFAULT 1,
+  "updated_at" timestamptz NULL,
+  PRIMARY KEY ("id")
+);
+-- create index "idx_testimonials_site_id" to table: "testimonials"
+CREATE INDEX "idx_testimonials_site_id" ON "testimonials" ("site_id");
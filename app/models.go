@@ -2,31 +2,332 @@ package app
 
 import "time"
 
+// Versioned is embedded in every model for optimistic concurrency control.
+// Version starts at 1 and is incremented by every update, so an update can be
+// made conditional on the version it was based on.
+type Versioned struct {
+	Version   int `gorm:"not null;default:1"`
+	UpdatedAt time.Time
+}
+
+// VersionInfo gives access to the embedded Versioned of a model.
+func (v *Versioned) VersionInfo() *Versioned { return v }
+
+// Site is one hosted portfolio. Every other model belongs to exactly one site
+// and is only visible to requests for that site, see WithSite.
+type Site struct {
+	ID        uint   `gorm:"primaryKey"`
+	Slug      string `gorm:"not null;uniqueIndex"`
+	Name      string
+	Domain    *string `gorm:"uniqueIndex"`
+	CreatedAt time.Time
+	// The associations are only declared for the foreign keys.
+	Users               []User                 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Projects            []Project              `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Blogs               []Blog                 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Resumes             []Resume               `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	ApiKeys             []ApiKey               `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Outbox              []OutboxEvent          `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Webhooks            []Webhook              `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Messages            []ContactMessage       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Subscribers         []NewsletterSubscriber `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	NewsletterIssues    []NewsletterIssue      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Views               []ViewRollup           `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Reactions           []BlogReaction         `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	ReactionCounts      []BlogReactionCount    `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	BlogTranslations    []BlogTranslation      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	ProjectTranslations []ProjectTranslation   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	ResumeTranslations  []ResumeTranslation    `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
 // Contoh tabel untuk portofolio
 type User struct {
 	ID        uint `gorm:"primaryKey"`
+	SiteID    uint `gorm:"not null;uniqueIndex:idx_users_email,priority:1"`
 	Name      string
-	Email     string `gorm:"uniqueIndex"`
+	Email     string `gorm:"uniqueIndex:idx_users_email,priority:2"`
 	CreatedAt time.Time
+	// Projects is only declared for the foreign key; the GraphQL layer decides
+	// what happens to them when the user is deleted.
+	Projects []Project `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Versioned
 }
 
 type Project struct {
 	ID          uint `gorm:"primaryKey"`
+	SiteID      uint `gorm:"not null;index"`
 	Title       string
 	Description string
 	UserID      uint
+	// Hidden projects are only shown to editors and through preview links.
+	Hidden bool `gorm:"not null;default:false"`
+	// Translations is only declared for the foreign key.
+	Translations []ProjectTranslation `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Versioned
 }
 
 type Blog struct {
 	ID        uint `gorm:"primaryKey"`
+	SiteID    uint `gorm:"not null;index"`
 	Title     string
 	Content   string
 	CreatedAt time.Time
+	// Drafts are only shown to editors and through preview links.
+	Draft bool `gorm:"not null;default:false"`
+	// The reactions and translations are only declared for the foreign
+	// keys, which delete them together with the post.
+	Reactions      []BlogReaction      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	ReactionCounts []BlogReactionCount `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Translations   []BlogTranslation   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Versioned
 }
 
 type Resume struct {
 	ID          uint `gorm:"primaryKey"`
+	SiteID      uint `gorm:"not null;index"`
 	Title       string
 	Description string
 	Category    string
+	StartDate   *time.Time `gorm:"type:date"`
+	EndDate     *time.Time `gorm:"type:date"`
+	// Translations is only declared for the foreign key.
+	Translations []ResumeTranslation `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Versioned
+}
+
+// ApiKey is a long-lived credential of a headless client. Only the SHA-256
+// hash of the key is stored.
+type ApiKey struct {
+	ID     uint `gorm:"primaryKey"`
+	SiteID uint `gorm:"not null;index"`
+	Name   string
+	Prefix string
+	Hash   string `gorm:"not null;uniqueIndex"`
+	// Scopes is the space-separated list of granted scopes.
+	Scopes     string
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// RateLimitBucket is the token bucket of one API client, shared between the
+// instances of the graphql service when its rate limit store is "postgres".
+type RateLimitBucket struct {
+	Key       string  `gorm:"primaryKey"`
+	Tokens    float64 `gorm:"type:double precision"`
+	UpdatedAt time.Time
+	// FullAt is when the bucket holds Burst tokens again. From then on it is
+	// no different from a missing bucket, and it is pruned.
+	FullAt time.Time `gorm:"index"`
+}
+
+// CacheInvalidation records the cache tags a write through one instance of
+// the graphql service invalidated, so that the other instances drop them too.
+type CacheInvalidation struct {
+	ID uint `gorm:"primaryKey"`
+	// Tags is the space-separated list of invalidated tags.
+	Tags          string    `gorm:"not null"`
+	InvalidatedAt time.Time `gorm:"not null;default:now();index"`
+}
+
+// OutboxEvent is a domain event waiting to be published to Pub/Sub. It is
+// written in the same transaction as the change it describes, so events are
+// neither lost nor sent for changes that were rolled back.
+type OutboxEvent struct {
+	ID     uint `gorm:"primaryKey"`
+	SiteID uint `gorm:"not null;index"`
+	// Topic is the name of the Pub/Sub topic the event goes to.
+	Topic string `gorm:"not null"`
+	// Payload is the JSON encoded message.
+	Payload   []byte `gorm:"type:jsonb;not null"`
+	CreatedAt time.Time
+	// PublishedAt is set once the event has been published; until then the
+	// event is pending.
+	PublishedAt *time.Time `gorm:"index:idx_outbox_events_pending,where:published_at IS NULL"`
+	Attempts    int        `gorm:"not null;default:0"`
+	LastError   string
+}
+
+// Webhook is an endpoint that is sent the domain events of its site.
+type Webhook struct {
+	ID     uint   `gorm:"primaryKey"`
+	SiteID uint   `gorm:"not null;index"`
+	URL    string `gorm:"not null"`
+	// Events is the space-separated list of event types sent to the webhook.
+	Events string `gorm:"not null"`
+	// Secret is the key payloads are signed with. It has to be stored as is
+	// to compute the signatures.
+	Secret     string `gorm:"not null"`
+	CreatedAt  time.Time
+	Deliveries []WebhookDelivery `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// Statuses of a WebhookDelivery.
+const (
+	DeliveryPending   = "PENDING"
+	DeliverySucceeded = "SUCCEEDED"
+	DeliveryFailed    = "FAILED"
+)
+
+// WebhookDelivery is one event sent, or to be sent, to a webhook, and the
+// outcome of the attempts so far.
+type WebhookDelivery struct {
+	ID        uint   `gorm:"primaryKey"`
+	SiteID    uint   `gorm:"not null;index"`
+	WebhookID uint   `gorm:"not null;uniqueIndex:idx_webhook_deliveries_event,priority:1"`
+	EventID   string `gorm:"not null;uniqueIndex:idx_webhook_deliveries_event,priority:2"`
+	EventType string `gorm:"not null"`
+	Payload   []byte `gorm:"type:jsonb;not null"`
+	Status    string `gorm:"not null;index:idx_webhook_deliveries_due,priority:1"`
+	Attempts  int    `gorm:"not null;default:0"`
+	// NextAttemptAt is when a pending delivery is tried next.
+	NextAttemptAt  *time.Time `gorm:"index:idx_webhook_deliveries_due,priority:2"`
+	ResponseStatus *int
+	LastError      *string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+// ContactMessage is a message sent through the contact form of a site.
+type ContactMessage struct {
+	ID         uint   `gorm:"primaryKey"`
+	SiteID     uint   `gorm:"not null;index"`
+	Name       string `gorm:"not null"`
+	Email      string `gorm:"not null"`
+	Subject    string `gorm:"not null"`
+	Body       string `gorm:"not null"`
+	CreatedAt  time.Time
+	ReadAt     *time.Time
+	ArchivedAt *time.Time
 }
+
+// NewsletterSubscriber is an email address that asked for the newsletter of a
+// site. The timestamps record the consent of the subscriber.
+type NewsletterSubscriber struct {
+	ID     uint   `gorm:"primaryKey"`
+	SiteID uint   `gorm:"not null;uniqueIndex:idx_newsletter_subscribers_email,priority:1"`
+	Email  string `gorm:"not null;uniqueIndex:idx_newsletter_subscribers_email,priority:2"`
+	// SubscribedAt is when the address last asked to subscribe.
+	SubscribedAt time.Time `gorm:"not null"`
+	// ConfirmedAt is when the address confirmed that request.
+	ConfirmedAt *time.Time
+	// UnsubscribedAt is when the address last unsubscribed. It is cleared by
+	// a later confirmation.
+	UnsubscribedAt *time.Time
+}
+
+// Active reports whether the newsletter is sent to s.
+func (s *NewsletterSubscriber) Active() bool {
+	return s.ConfirmedAt != nil && s.UnsubscribedAt == nil
+}
+
+// NewsletterIssue records that the emails about an event were queued, so that
+// a redelivery of the event does not mail the subscribers again.
+type NewsletterIssue struct {
+	ID      uint   `gorm:"primaryKey"`
+	SiteID  uint   `gorm:"not null;index"`
+	EventID string `gorm:"not null;uniqueIndex"`
+	Subject string `gorm:"not null"`
+	// Recipients is how many emails were queued.
+	Recipients int `gorm:"not null"`
+	CreatedAt  time.Time
+}
+
+// ViewRollup counts the views of a record on one day from one referrer. Only
+// these counts are stored, never anything identifying the viewers.
+type ViewRollup struct {
+	SiteID uint `gorm:"primaryKey;autoIncrement:false;index:idx_view_rollups_day,priority:1"`
+	// EntityType is the GraphQL type of the viewed record, e.g. "Blog".
+	EntityType string    `gorm:"primaryKey;index:idx_view_rollups_day,priority:2"`
+	EntityID   uint      `gorm:"primaryKey;autoIncrement:false"`
+	Day        time.Time `gorm:"primaryKey;type:date;index:idx_view_rollups_day,priority:3"`
+	// Referrer is the host of the referring page, empty for direct visits.
+	Referrer string `gorm:"primaryKey"`
+	Views    int64  `gorm:"not null;default:0"`
+}
+
+// BlogReaction is a reaction of one visitor to a blog post. Each visitor can
+// react once with each kind.
+type BlogReaction struct {
+	ID     uint   `gorm:"primaryKey"`
+	SiteID uint   `gorm:"not null;index"`
+	BlogID uint   `gorm:"not null;uniqueIndex:idx_blog_reactions_reactor,priority:1"`
+	Kind   string `gorm:"not null;uniqueIndex:idx_blog_reactions_reactor,priority:3"`
+	// Reactor identifies the visitor: "user:<auth UID>" for callers with
+	// credentials, "anon:<fingerprint>" for anonymous ones.
+	Reactor   string `gorm:"not null;uniqueIndex:idx_blog_reactions_reactor,priority:2"`
+	CreatedAt time.Time
+}
+
+// BlogReactionCount is the number of BlogReactions of a kind to a post, kept
+// up to date by the mutations that add and remove them.
+type BlogReactionCount struct {
+	SiteID uint   `gorm:"not null;index"`
+	BlogID uint   `gorm:"primaryKey;autoIncrement:false"`
+	Kind   string `gorm:"primaryKey"`
+	Count  int64  `gorm:"not null;default:0"`
+}
+
+// BlogTranslation holds the text of a blog post in another locale than the
+// one of the post itself.
+type BlogTranslation struct {
+	ID        uint   `gorm:"primaryKey"`
+	SiteID    uint   `gorm:"not null;index"`
+	BlogID    uint   `gorm:"not null;uniqueIndex:idx_blog_translations_locale,priority:1"`
+	Locale    string `gorm:"not null;uniqueIndex:idx_blog_translations_locale,priority:2"`
+	Title     string `gorm:"not null"`
+	Content   string `gorm:"not null"`
+	UpdatedAt time.Time
+}
+
+// ProjectTranslation holds the text of a project in another locale than the
+// one of the project itself.
+type ProjectTranslation struct {
+	ID          uint   `gorm:"primaryKey"`
+	SiteID      uint   `gorm:"not null;index"`
+	ProjectID   uint   `gorm:"not null;uniqueIndex:idx_project_translations_locale,priority:1"`
+	Locale      string `gorm:"not null;uniqueIndex:idx_project_translations_locale,priority:2"`
+	Title       string `gorm:"not null"`
+	Description string `gorm:"not null"`
+	UpdatedAt   time.Time
+}
+
+// ResumeTranslation holds the text of a resume section in another locale
+// than the one of the section itself.
+type ResumeTranslation struct {
+	ID          uint   `gorm:"primaryKey"`
+	SiteID      uint   `gorm:"not null;index"`
+	ResumeID    uint   `gorm:"not null;uniqueIndex:idx_resume_translations_locale,priority:1"`
+	Locale      string `gorm:"not null;uniqueIndex:idx_resume_translations_locale,priority:2"`
+	Title       string `gorm:"not null"`
+	Description string `gorm:"not null"`
+	UpdatedAt   time.Time
+}
+
+// Testimonial is a quote from a client or colleague.
+//
+//crud:generate
+type Testimonial struct {
+	ID     uint `gorm:"primaryKey"`
+	SiteID uint `gorm:"not null;index"`
+	// Author is who gave the testimonial.
+	Author string `gorm:"not null" crud:"filter,minLength=1,maxLength=100"`
+	// Quote is what they said.
+	Quote string `gorm:"not null" crud:"maxLength=2000"`
+	// Rating is from 1 to 5, or 0 if there is none.
+	Rating int `gorm:"not null;default:0" crud:"optional"`
+	// GivenOn is when the testimonial was given.
+	GivenOn *time.Time `gorm:"type:date" crud:"filter"`
+	// Website is a page of the author.
+	Website   *string `crud:"format=url"`
+	CreatedAt time.Time
+	Versioned
+}
+
+// IsEntity marks the models the graphql service resolves as Apollo Federation
+// entities.
+func (User) IsEntity()    {}
+func (Project) IsEntity() {}
+func (Blog) IsEntity()    {}
+func (Resume) IsEntity()  {}
@@ -0,0 +1,177 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"encore.dev/pubsub"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// newsletterResendAfter is how long a pending subscription waits before
+// another confirmation email can be requested for it.
+const newsletterResendAfter = 10 * time.Minute
+
+const TopicNewsletterEmails = "newsletter-emails"
+
+// NewsletterEmails carries the emails to subscribers. They are queued through
+// the outbox together with the change that caused them.
+var NewsletterEmails = pubsub.NewTopic[*NewsletterEmail](TopicNewsletterEmails, pubsub.TopicConfig{DeliveryGuarantee: pubsub.AtLeastOnce})
+
+// NewsletterEmail is an email to a newsletter subscriber.
+type NewsletterEmail struct {
+	SiteID       uint
+	SubscriberID uint
+	// Confirmation marks the email asking to confirm a subscription, whose
+	// text is up to the sender. Other emails are only sent to confirmed
+	// subscribers.
+	Confirmation bool
+	Subject      string
+	Text         string
+}
+
+type SubscribeNewsletterParams struct {
+	Email string `json:"email"`
+}
+
+// SubscribeNewsletter records that an address asked for the newsletter of a
+// site and queues the email asking to confirm it. Addresses that are already
+// subscribed are left alone, without telling the caller.
+//
+//encore:api private method=POST path=/sites/:siteID/newsletter/subscribers
+func SubscribeNewsletter(ctx context.Context, siteID uint, p *SubscribeNewsletterParams) error {
+	email := strings.ToLower(strings.TrimSpace(p.Email))
+	now := time.Now()
+	return transaction(ctx, siteID, func(tx *gorm.DB) error {
+		var sub NewsletterSubscriber
+		err := tx.Where("email = ?", email).Take(&sub).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			sub = NewsletterSubscriber{Email: email, SubscribedAt: now}
+			err = tx.Create(&sub).Error
+		case err != nil:
+			return err
+		case sub.Active(), now.Sub(sub.SubscribedAt) < newsletterResendAfter:
+			return nil
+		default:
+			sub.SubscribedAt = now
+			err = tx.Model(&sub).Update("subscribed_at", now).Error
+		}
+		if err != nil {
+			return err
+		}
+		return enqueue(tx, TopicNewsletterEmails, &NewsletterEmail{
+			SiteID:       siteID,
+			SubscriberID: sub.ID,
+			Confirmation: true,
+		})
+	})
+}
+
+// GetNewsletterSubscriber returns a newsletter subscriber of a site, or
+// NotFound.
+//
+//encore:api private method=GET path=/sites/:siteID/newsletter/subscribers/:id
+func GetNewsletterSubscriber(ctx context.Context, siteID, id uint) (*NewsletterSubscriber, error) {
+	db, err := session(ctx, siteID)
+	if err != nil {
+		return nil, err
+	}
+	var sub NewsletterSubscriber
+	if err := db.First(&sub, id).Error; err != nil {
+		return nil, DBError(err)
+	}
+	return &sub, nil
+}
+
+type ConfirmNewsletterSubscriberParams struct {
+	// SubscribedAt is the Unix time of the subscription request being
+	// confirmed. Confirmations of superseded requests fail.
+	SubscribedAt int64 `json:"subscribedAt"`
+}
+
+// ConfirmNewsletterSubscriber confirms a subscription to the newsletter of a
+// site. It returns NotFound unless the subscriber exists and last asked to
+// subscribe at SubscribedAt.
+//
+//encore:api private method=POST path=/sites/:siteID/newsletter/subscribers/:id/confirm
+func ConfirmNewsletterSubscriber(ctx context.Context, siteID, id uint, p *ConfirmNewsletterSubscriberParams) error {
+	return transaction(ctx, siteID, func(tx *gorm.DB) error {
+		var sub NewsletterSubscriber
+		if err := tx.First(&sub, id).Error; err != nil {
+			return err
+		}
+		if sub.SubscribedAt.Unix() != p.SubscribedAt {
+			return gorm.ErrRecordNotFound
+		}
+		if sub.Active() {
+			return nil
+		}
+		return tx.Model(&sub).Updates(map[string]any{"confirmed_at": time.Now(), "unsubscribed_at": nil}).Error
+	})
+}
+
+// UnsubscribeNewsletter ends a subscription, whichever site it belongs to.
+// Unsubscribing twice keeps the time of the first.
+//
+//encore:api private method=POST path=/newsletter-subscribers/:id/unsubscribe
+func UnsubscribeNewsletter(ctx context.Context, id uint) error {
+	db, err := database()
+	if err != nil {
+		return err
+	}
+	// The unsubscribe link stands for one subscriber of one site.
+	return db.WithContext(AcrossSites(ctx)).Model(&NewsletterSubscriber{}).
+		Where("id = ? AND unsubscribed_at IS NULL", id).
+		Update("unsubscribed_at", time.Now()).Error
+}
+
+type QueueNewsletterIssueParams struct {
+	// EventID is the domain event the issue is about. Each event is mailed
+	// once, however often it is delivered.
+	EventID string `json:"eventId"`
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+}
+
+// QueueNewsletterIssue queues an email to every confirmed subscriber of a
+// site, unless the issue of EventID was queued already.
+//
+//encore:api private method=POST path=/sites/:siteID/newsletter/issues
+func QueueNewsletterIssue(ctx context.Context, siteID uint, p *QueueNewsletterIssueParams) error {
+	if p.EventID == "" {
+		return invalidArgument("eventId", "eventId must not be empty")
+	}
+	if p.Subject == "" {
+		return invalidArgument("subject", "subject must not be empty")
+	}
+	return transaction(ctx, siteID, func(tx *gorm.DB) error {
+		var ids []uint
+		err := tx.Model(&NewsletterSubscriber{}).
+			Where("confirmed_at IS NOT NULL AND unsubscribed_at IS NULL").
+			Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+		issue := &NewsletterIssue{EventID: p.EventID, Subject: p.Subject, Recipients: len(ids)}
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(issue)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		for _, id := range ids {
+			err := enqueue(tx, TopicNewsletterEmails, &NewsletterEmail{
+				SiteID:       siteID,
+				SubscriberID: id,
+				Subject:      p.Subject,
+				Text:         p.Text,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"encore.dev/pubsub"
+	"encore.dev/rlog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// outboxBatch is the most events published per transaction.
+const outboxBatch = 100
+
+// outboxTopics publishes the payload of an outbox event to its topic.
+var outboxTopics = map[string]func(ctx context.Context, payload []byte) error{
+	TopicUserEvents:    publishJSON(UserEvents),
+	TopicProjectEvents: publishJSON(ProjectEvents),
+	TopicBlogEvents:    publishJSON(BlogEvents),
+	TopicResumeEvents:  publishJSON(ResumeEvents),
+	TopicContentEvents: publishJSON(ContentEvents),
+
+	TopicNewsletterEmails: publishJSON(NewsletterEmails),
+}
+
+func publishJSON[T any](topic *pubsub.Topic[*T]) func(context.Context, []byte) error {
+	return func(ctx context.Context, payload []byte) error {
+		var msg T
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			return err
+		}
+		_, err := topic.Publish(ctx, &msg)
+		return err
+	}
+}
+
+// outbox publishes the events written by the transactions of this instance.
+var outbox = &outboxRelay{poller: newPoller()}
+
+// outboxRelay publishes the pending events of the outbox. It runs right after
+// every transaction and every Outbox.PollSeconds, which picks up events whose
+// publishing failed or whose instance went away before publishing them.
+type outboxRelay struct {
+	poller
+}
+
+// run publishes events until close is called.
+func (o *outboxRelay) run() {
+	o.poller.run(time.Duration(cfg.Outbox.PollSeconds())*time.Second, func(tick bool) {
+		if tick {
+			o.prune()
+		}
+		o.drain()
+	})
+}
+
+// drain publishes batches of pending events until there are none left.
+func (o *outboxRelay) drain() {
+	ctx := AcrossSites(context.Background())
+	for {
+		tried, published, err := o.publish(ctx)
+		if err != nil {
+			rlog.Error("app: publish outbox events", "err", err)
+			return
+		}
+		// Stop when the outbox is empty or only holds failing events.
+		if tried < outboxBatch || published == 0 {
+			return
+		}
+	}
+}
+
+// publish publishes one batch of pending events, oldest first, and returns
+// how many events it tried and how many of them it published. The events are
+// locked while they are published, so that several instances of the service
+// never publish the same event at the same time. An event whose publishing
+// fails stays pending and is retried on the next run.
+func (o *outboxRelay) publish(ctx context.Context) (tried, published int, err error) {
+	db, err := database()
+	if err != nil {
+		return 0, 0, err
+	}
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var events []OutboxEvent
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL").
+			Order("id").
+			Limit(outboxBatch).
+			Find(&events).Error
+		if err != nil {
+			return err
+		}
+		tried, published = len(events), 0
+		for _, e := range events {
+			var err error
+			if publish, ok := outboxTopics[e.Topic]; ok {
+				err = publish(ctx, e.Payload)
+			} else {
+				err = fmt.Errorf("unknown topic %q", e.Topic)
+			}
+			update := map[string]any{"published_at": time.Now()}
+			if err != nil {
+				rlog.Warn("app: publish outbox event", "id", e.ID, "topic", e.Topic, "err", err)
+				update = map[string]any{"attempts": gorm.Expr("attempts + 1"), "last_error": err.Error()}
+			} else {
+				published++
+			}
+			if err := tx.Model(&e).Updates(update).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return tried, published, err
+}
+
+// prune deletes the events that were published longer than
+// Outbox.RetentionHours ago.
+func (o *outboxRelay) prune() {
+	db, err := database()
+	if err == nil {
+		retention := time.Duration(cfg.Outbox.RetentionHours()) * time.Hour
+		err = db.WithContext(AcrossSites(context.Background())).
+			Where("published_at < ?", time.Now().Add(-retention)).
+			Delete(&OutboxEvent{}).Error
+	}
+	if err != nil {
+		rlog.Error("app: prune outbox events", "err", err)
+	}
+}
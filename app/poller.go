@@ -0,0 +1,51 @@
+package app
+
+import "time"
+
+// poller runs background work whenever it is notified and on every tick of
+// an interval, one run at a time, until it is closed.
+type poller struct {
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newPoller() poller {
+	return poller{
+		wake: make(chan struct{}, 1),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+// run calls work until close is called. tick tells whether the run is due to
+// the interval rather than to notify.
+func (p *poller) run(interval time.Duration, work func(tick bool)) {
+	defer close(p.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-p.wake:
+			work(false)
+		case <-ticker.C:
+			work(true)
+		}
+	}
+}
+
+// close stops the poller and waits for the current run.
+func (p *poller) close() {
+	close(p.stop)
+	<-p.done
+}
+
+// notify schedules a run without waiting for it.
+func (p *poller) notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,254 @@
+package app
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ListProjectsParams struct {
+	// IDs limits the list to these projects.
+	IDs           []uint `query:"id"`
+	UserID        uint   `query:"userId"`
+	TitleContains string `query:"titleContains"`
+	// IncludeHidden also lists hidden projects.
+	IncludeHidden bool `query:"includeHidden"`
+}
+
+type ListProjectsResponse struct {
+	Projects []*Project `json:"projects"`
+}
+
+// ListProjects lists the projects of a site that match every given
+// condition, by ID.
+//
+//encore:api private method=GET path=/sites/:siteID/projects
+func ListProjects(ctx context.Context, siteID uint, p *ListProjectsParams) (*ListProjectsResponse, error) {
+	db, err := session(ctx, siteID)
+	if err != nil {
+		return nil, err
+	}
+	var f filter
+	if len(p.IDs) > 0 {
+		f.add("id IN ?", p.IDs)
+	}
+	if p.UserID != 0 {
+		f.add("user_id = ?", p.UserID)
+	}
+	f.contains("title", p.TitleContains)
+	if !p.IncludeHidden {
+		f.add("hidden = ?", false)
+	}
+	projects := []*Project{}
+	if err := f.apply(db).Order("id").Find(&projects).Error; err != nil {
+		return nil, err
+	}
+	return &ListProjectsResponse{Projects: projects}, nil
+}
+
+type GetProjectParams struct {
+	// IncludeHidden also returns a hidden project.
+	IncludeHidden bool `query:"includeHidden"`
+}
+
+// GetProject returns a project, or NotFound.
+//
+//encore:api private method=GET path=/sites/:siteID/projects/:id
+func GetProject(ctx context.Context, siteID, id uint, p *GetProjectParams) (*Project, error) {
+	db, err := session(ctx, siteID)
+	if err != nil {
+		return nil, err
+	}
+	if !p.IncludeHidden {
+		db = db.Where("hidden = ?", false)
+	}
+	var project Project
+	if err := db.First(&project, id).Error; err != nil {
+		return nil, DBError(err)
+	}
+	return &project, nil
+}
+
+type CreateProjectParams struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// UserID is the owner, which must exist.
+	UserID uint `json:"userID"`
+	Hidden bool `json:"hidden"`
+}
+
+// CreateProject creates a project and publishes ProjectCreated.
+//
+//encore:api private method=POST path=/sites/:siteID/projects
+func CreateProject(ctx context.Context, siteID uint, p *CreateProjectParams) (project *Project, err error) {
+	err = transaction(ctx, siteID, func(tx *gorm.DB) error {
+		project, err = createProject(tx, p)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+func createProject(tx *gorm.DB, p *CreateProjectParams) (*Project, error) {
+	if err := requireUser(tx, "userID", p.UserID); err != nil {
+		return nil, err
+	}
+	project := &Project{
+		Title:       p.Title,
+		Description: p.Description,
+		UserID:      p.UserID,
+		Hidden:      p.Hidden,
+	}
+	if err := tx.Create(project).Error; err != nil {
+		return nil, err
+	}
+	return project, projectEvent(tx, ProjectCreated, project)
+}
+
+// UpdateProjectParams changes the fields that are set.
+type UpdateProjectParams struct {
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+	UserID      *uint   `json:"userID,omitempty"`
+	Hidden      *bool   `json:"hidden,omitempty"`
+	// ExpectedVersion rejects the update with Aborted unless the project is
+	// still at this version.
+	ExpectedVersion *int `json:"expectedVersion,omitempty"`
+}
+
+// UpdateProject changes a project and publishes ProjectUpdated.
+//
+//encore:api private method=PATCH path=/sites/:siteID/projects/:id
+func UpdateProject(ctx context.Context, siteID, id uint, p *UpdateProjectParams) (project *Project, err error) {
+	err = transaction(ctx, siteID, func(tx *gorm.DB) error {
+		project, err = updateProject(tx, id, p)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+func updateProject(tx *gorm.DB, id uint, p *UpdateProjectParams) (*Project, error) {
+	var project Project
+	if err := tx.First(&project, id).Error; err != nil {
+		return nil, err
+	}
+	if err := checkVersion(&project, p.ExpectedVersion); err != nil {
+		return nil, err
+	}
+	if p.Title != nil {
+		project.Title = *p.Title
+	}
+	if p.Description != nil {
+		project.Description = *p.Description
+	}
+	if p.UserID != nil {
+		if err := requireUser(tx, "userID", *p.UserID); err != nil {
+			return nil, err
+		}
+		project.UserID = *p.UserID
+	}
+	if p.Hidden != nil {
+		project.Hidden = *p.Hidden
+	}
+	if err := saveVersioned(tx, &project, id); err != nil {
+		return nil, err
+	}
+	return &project, projectEvent(tx, ProjectUpdated, &project)
+}
+
+// DeleteProject deletes a project and publishes ProjectDeleted. It returns
+// the deleted project.
+//
+//encore:api private method=DELETE path=/sites/:siteID/projects/:id
+func DeleteProject(ctx context.Context, siteID, id uint) (project *Project, err error) {
+	err = transaction(ctx, siteID, func(tx *gorm.DB) error {
+		project, err = deleteProject(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+func deleteProject(tx *gorm.DB, id uint) (*Project, error) {
+	// RETURNING fills in the deleted record for the event.
+	var project Project
+	res := tx.Clauses(clause.Returning{}).Delete(&project, id)
+	if res.Error != nil {
+		return nil, stillReferenced(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &project, projectEvent(tx, ProjectDeleted, &project)
+}
+
+// ProjectOp is one operation of a batch. Exactly one of its fields is set.
+type ProjectOp struct {
+	Create *CreateProjectParams `json:"create,omitempty"`
+	Update *UpdateProjectOp     `json:"update,omitempty"`
+	Delete *DeleteOp            `json:"delete,omitempty"`
+}
+
+type UpdateProjectOp struct {
+	ID     uint                `json:"id"`
+	Params UpdateProjectParams `json:"params"`
+}
+
+type BatchProjectsParams struct {
+	Ops []*ProjectOp `json:"ops"`
+	// Atomic rolls back every operation if any of them fails.
+	Atomic bool `json:"atomic"`
+}
+
+type BatchProjectsResponse struct {
+	// Committed reports whether the operations without an error took
+	// effect.
+	Committed bool `json:"committed"`
+	// Results holds the outcome of each operation, in order.
+	Results []*ProjectResult `json:"results"`
+}
+
+type ProjectResult struct {
+	// Project is the created, updated or deleted project.
+	Project *Project   `json:"project,omitempty"`
+	Error   *ItemError `json:"error,omitempty"`
+}
+
+// BatchProjects runs the operations in a single transaction, see runBatch.
+//
+//encore:api private method=POST path=/sites/:siteID/batch/projects
+func BatchProjects(ctx context.Context, siteID uint, p *BatchProjectsParams) (*BatchProjectsResponse, error) {
+	results := make([]*ProjectResult, len(p.Ops))
+	failures, committed, err := runBatch(ctx, siteID, len(p.Ops), p.Atomic, func(tx *gorm.DB, i int) (err error) {
+		res := &ProjectResult{}
+		switch op := p.Ops[i]; {
+		case op.Create != nil:
+			res.Project, err = createProject(tx, op.Create)
+		case op.Update != nil:
+			res.Project, err = updateProject(tx, op.Update.ID, &op.Update.Params)
+		case op.Delete != nil:
+			res.Project, err = deleteProject(tx, op.Delete.ID)
+		default:
+			err = errEmptyOp
+		}
+		results[i] = res
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		if failures[i] != nil || !committed {
+			results[i] = &ProjectResult{Error: failures[i]}
+		}
+	}
+	return &BatchProjectsResponse{Committed: committed, Results: results}, nil
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"encore.dev/cron"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BucketRate describes a token bucket that holds at most Burst tokens and
+// refills at Rate tokens per second.
+type BucketRate struct {
+	Rate  float64 `json:"rate"`
+	Burst float64 `json:"burst"`
+}
+
+// Spend refills a bucket that held tokens at last, then takes cost from it.
+// It returns the new balance and, if there were not enough tokens, how long
+// until there are.
+func (b BucketRate) Spend(tokens float64, last, now time.Time, cost float64) (float64, time.Duration) {
+	tokens = math.Min(b.Burst, tokens+now.Sub(last).Seconds()*b.Rate)
+	if tokens < cost {
+		return tokens, time.Duration((cost - tokens) / b.Rate * float64(time.Second))
+	}
+	return tokens - cost, 0
+}
+
+// FullAt returns when a bucket that holds tokens at now is full again.
+func (b BucketRate) FullAt(tokens float64, now time.Time) time.Time {
+	if tokens >= b.Burst || b.Rate <= 0 {
+		return now
+	}
+	return now.Add(time.Duration((b.Burst - tokens) / b.Rate * float64(time.Second)))
+}
+
+type TakeTokensParams struct {
+	// Key names the bucket, e.g. after the client it limits.
+	Key    string     `json:"key"`
+	Cost   float64    `json:"cost"`
+	Bucket BucketRate `json:"bucket"`
+}
+
+type TakeTokensResponse struct {
+	// WaitMs is 0 if the tokens were taken. Otherwise nothing was taken, and
+	// it is how long until there are enough tokens, in milliseconds.
+	WaitMs int64 `json:"waitMs"`
+}
+
+// TakeTokens removes Cost tokens from the bucket of Key in the
+// rate_limit_buckets table, which all instances of a service share.
+//
+//encore:api private method=POST path=/rate-limit/take
+func TakeTokens(ctx context.Context, p *TakeTokensParams) (*TakeTokensResponse, error) {
+	db, err := database()
+	if err != nil {
+		return nil, err
+	}
+	var wait time.Duration
+	now := time.Now()
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		b := RateLimitBucket{Key: p.Key, Tokens: p.Bucket.Burst, UpdatedAt: now, FullAt: now}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&b).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&b, "key = ?", p.Key).Error; err != nil {
+			return err
+		}
+		var tokens float64
+		tokens, wait = p.Bucket.Spend(b.Tokens, b.UpdatedAt, now, p.Cost)
+		return tx.Model(&b).Updates(map[string]any{
+			"tokens":     tokens,
+			"updated_at": now,
+			"full_at":    p.Bucket.FullAt(tokens, now),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Round up, so that the caller does not come back too early.
+	return &TakeTokensResponse{WaitMs: (wait + time.Millisecond - 1).Milliseconds()}, nil
+}
+
+// Full buckets are deleted every hour, since TakeTokens creates them again
+// with Burst tokens, so that the table only holds the clients that are being
+// limited.
+var _ = cron.NewJob("prune-rate-limit-buckets", cron.JobConfig{
+	Title:    "Delete full rate limit buckets",
+	Every:    1 * cron.Hour,
+	Endpoint: PruneRateLimitBuckets,
+})
+
+// PruneRateLimitBuckets deletes the rate limit buckets that have refilled.
+//
+//encore:api private method=POST path=/rate-limit/prune
+func PruneRateLimitBuckets(ctx context.Context) error {
+	db, err := database()
+	if err != nil {
+		return err
+	}
+	return db.WithContext(ctx).Where("full_at <= ?", time.Now()).Delete(&RateLimitBucket{}).Error
+}
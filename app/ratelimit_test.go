@@ -0,0 +1,59 @@
+//go:build encore_app
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBucketFullAt(t *testing.T) {
+	now := time.Date(2026, 10, 20, 12, 0, 0, 0, time.UTC)
+	b := BucketRate{Rate: 20, Burst: 500}
+	tests := []struct {
+		tokens float64
+		want   time.Duration
+	}{
+		{500, 0},
+		{600, 0},
+		{480, time.Second},
+		{0, 25 * time.Second},
+		{-10, 25*time.Second + 500*time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := b.FullAt(tt.tokens, now).Sub(now); got != tt.want {
+			t.Errorf("FullAt(%v) = now+%v, want now+%v", tt.tokens, got, tt.want)
+		}
+	}
+	if got := (BucketRate{Burst: 10}).FullAt(0, now); !got.Equal(now) {
+		t.Errorf("FullAt without a rate = %v, want now", got)
+	}
+}
+
+func TestPruneRateLimitBuckets(t *testing.T) {
+	ctx := context.Background()
+	rate := BucketRate{Rate: 1000, Burst: 1}
+	slow := BucketRate{Rate: 0.001, Burst: 1}
+	for key, b := range map[string]BucketRate{"prune-fast": rate, "prune-slow": slow} {
+		if _, err := TakeTokens(ctx, &TakeTokensParams{Key: key, Cost: 1, Bucket: b}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	if err := PruneRateLimitBuckets(ctx); err != nil {
+		t.Fatal(err)
+	}
+	db, err := database()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	err = db.Model(&RateLimitBucket{}).Where("key LIKE 'prune-%'").Pluck("key", &keys).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != "prune-slow" {
+		t.Errorf("buckets left = %v, want [prune-slow]", keys)
+	}
+}
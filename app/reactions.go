@@ -0,0 +1,199 @@
+package app
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReactionsResponse struct {
+	// Counts holds the count of every kind of reaction the post got.
+	Counts []*ReactionCount `json:"counts"`
+	// Mine are the kinds of reaction the reactor used.
+	Mine []string `json:"mine"`
+}
+
+type ReactionCount struct {
+	Kind  string `json:"kind"`
+	Count int64  `json:"count"`
+}
+
+type ListReactionsParams struct {
+	Reactor string `query:"reactor"`
+}
+
+// ListReactions returns the reactions to a blog post of a site, as seen by
+// Reactor.
+//
+//encore:api private method=GET path=/sites/:siteID/blogs/:id/reactions
+func ListReactions(ctx context.Context, siteID, id uint, p *ListReactionsParams) (*ReactionsResponse, error) {
+	db, err := session(ctx, siteID)
+	if err != nil {
+		return nil, err
+	}
+	return reactions(db, id, p.Reactor)
+}
+
+type ListBlogReactionsParams struct {
+	// BlogIDs are the posts to list the reactions to.
+	BlogIDs []uint `query:"blogId"`
+	Reactor string `query:"reactor"`
+}
+
+type ListBlogReactionsResponse struct {
+	// Reactions holds the reactions to each post, in the order of BlogIDs.
+	Reactions []*ReactionsResponse `json:"reactions"`
+}
+
+// ListBlogReactions returns the reactions to several blog posts of a site, as
+// seen by Reactor, with two queries for all of them.
+//
+//encore:api private method=GET path=/sites/:siteID/reactions
+func ListBlogReactions(ctx context.Context, siteID uint, p *ListBlogReactionsParams) (*ListBlogReactionsResponse, error) {
+	db, err := session(ctx, siteID)
+	if err != nil {
+		return nil, err
+	}
+	byID, err := reactionsOf(db, p.BlogIDs, p.Reactor)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListBlogReactionsResponse{Reactions: make([]*ReactionsResponse, len(p.BlogIDs))}
+	for i, id := range p.BlogIDs {
+		resp.Reactions[i] = byID[id]
+	}
+	return resp, nil
+}
+
+func reactions(db *gorm.DB, blogID uint, reactor string) (*ReactionsResponse, error) {
+	byID, err := reactionsOf(db, []uint{blogID}, reactor)
+	if err != nil {
+		return nil, err
+	}
+	return byID[blogID], nil
+}
+
+// reactionsOf returns the reactions to the posts blogIDs as seen by reactor,
+// by post.
+func reactionsOf(db *gorm.DB, blogIDs []uint, reactor string) (map[uint]*ReactionsResponse, error) {
+	byID := make(map[uint]*ReactionsResponse, len(blogIDs))
+	for _, id := range blogIDs {
+		byID[id] = &ReactionsResponse{Counts: []*ReactionCount{}, Mine: []string{}}
+	}
+	if len(blogIDs) == 0 {
+		return byID, nil
+	}
+	var counts []BlogReactionCount
+	err := db.Model(&BlogReactionCount{}).
+		Select("blog_id, kind, count").
+		Where("blog_id IN ? AND count > 0", blogIDs).
+		Order("blog_id, kind").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range counts {
+		byID[c.BlogID].Counts = append(byID[c.BlogID].Counts, &ReactionCount{Kind: c.Kind, Count: c.Count})
+	}
+	var mine []BlogReaction
+	err = db.Select("blog_id, kind").
+		Where("blog_id IN ? AND reactor = ?", blogIDs, reactor).
+		Order("blog_id, kind").
+		Find(&mine).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range mine {
+		byID[r.BlogID].Mine = append(byID[r.BlogID].Mine, r.Kind)
+	}
+	return byID, nil
+}
+
+type ReactParams struct {
+	Kind    string `json:"kind"`
+	Reactor string `json:"reactor"`
+	// IncludeDrafts allows reacting to drafts.
+	IncludeDrafts bool `json:"includeDrafts"`
+}
+
+// React adds the reaction of Reactor to a blog post of a site and counts it,
+// unless it exists already. It returns the reactions afterwards.
+//
+//encore:api private method=POST path=/sites/:siteID/blogs/:id/reactions
+func React(ctx context.Context, siteID, id uint, p *ReactParams) (resp *ReactionsResponse, err error) {
+	err = transaction(ctx, siteID, func(tx *gorm.DB) error {
+		if err := requireBlog(tx, id, p.IncludeDrafts); err != nil {
+			return err
+		}
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&BlogReaction{BlogID: id, Kind: p.Kind, Reactor: p.Reactor})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 {
+			err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "blog_id"}, {Name: "kind"}},
+				DoUpdates: clause.Assignments(map[string]any{
+					"count": gorm.Expr("blog_reaction_counts.count + 1"),
+				}),
+			}).Create(&BlogReactionCount{BlogID: id, Kind: p.Kind, Count: 1}).Error
+			if err != nil {
+				return err
+			}
+		}
+		resp, err = reactions(tx, id, p.Reactor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type UnreactParams struct {
+	Reactor string `query:"reactor"`
+	// IncludeDrafts allows reacting to drafts.
+	IncludeDrafts bool `query:"includeDrafts"`
+}
+
+// Unreact removes the reaction of Reactor to a blog post of a site and
+// uncounts it, if it exists. It returns the reactions afterwards.
+//
+//encore:api private method=DELETE path=/sites/:siteID/blogs/:id/reactions/:kind
+func Unreact(ctx context.Context, siteID, id uint, kind string, p *UnreactParams) (resp *ReactionsResponse, err error) {
+	err = transaction(ctx, siteID, func(tx *gorm.DB) error {
+		if err := requireBlog(tx, id, p.IncludeDrafts); err != nil {
+			return err
+		}
+		res := tx.Where("blog_id = ? AND kind = ? AND reactor = ?", id, kind, p.Reactor).
+			Delete(&BlogReaction{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 {
+			err := tx.Model(&BlogReactionCount{}).
+				Where("blog_id = ? AND kind = ?", id, kind).
+				Update("count", gorm.Expr("count - 1")).Error
+			if err != nil {
+				return err
+			}
+		}
+		resp, err = reactions(tx, id, p.Reactor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// requireBlog returns NotFound unless the blog post id exists, and with
+// includeDrafts unset, is not a draft.
+func requireBlog(tx *gorm.DB, id uint, includeDrafts bool) error {
+	q := tx.Select("id")
+	if !includeDrafts {
+		q = q.Where("draft = ?", false)
+	}
+	return q.First(&Blog{}, id).Error
+}
@@ -0,0 +1,241 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ListResumesParams struct {
+	// IDs limits the list to these sections.
+	IDs           []uint `query:"id"`
+	Category      string `query:"category"`
+	TitleContains string `query:"titleContains"`
+}
+
+type ListResumesResponse struct {
+	Resumes []*Resume `json:"resumes"`
+}
+
+// ListResumes lists the resume sections of a site that match every given
+// condition, by ID.
+//
+//encore:api private method=GET path=/sites/:siteID/resumes
+func ListResumes(ctx context.Context, siteID uint, p *ListResumesParams) (*ListResumesResponse, error) {
+	db, err := session(ctx, siteID)
+	if err != nil {
+		return nil, err
+	}
+	var f filter
+	if len(p.IDs) > 0 {
+		f.add("id IN ?", p.IDs)
+	}
+	if p.Category != "" {
+		f.add("category = ?", p.Category)
+	}
+	f.contains("title", p.TitleContains)
+	resumes := []*Resume{}
+	if err := f.apply(db).Order("id").Find(&resumes).Error; err != nil {
+		return nil, err
+	}
+	return &ListResumesResponse{Resumes: resumes}, nil
+}
+
+// GetResume returns a resume section, or NotFound.
+//
+//encore:api private method=GET path=/sites/:siteID/resumes/:id
+func GetResume(ctx context.Context, siteID, id uint) (*Resume, error) {
+	db, err := session(ctx, siteID)
+	if err != nil {
+		return nil, err
+	}
+	var resume Resume
+	if err := db.First(&resume, id).Error; err != nil {
+		return nil, DBError(err)
+	}
+	return &resume, nil
+}
+
+type CreateResumeParams struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Category    string     `json:"category"`
+	StartDate   *time.Time `json:"startDate,omitempty"`
+	EndDate     *time.Time `json:"endDate,omitempty"`
+}
+
+// CreateResume creates a resume section and publishes ResumeCreated.
+//
+//encore:api private method=POST path=/sites/:siteID/resumes
+func CreateResume(ctx context.Context, siteID uint, p *CreateResumeParams) (resume *Resume, err error) {
+	err = transaction(ctx, siteID, func(tx *gorm.DB) error {
+		resume, err = createResume(tx, p)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resume, nil
+}
+
+func createResume(tx *gorm.DB, p *CreateResumeParams) (*Resume, error) {
+	resume := &Resume{
+		Title:       p.Title,
+		Description: p.Description,
+		Category:    p.Category,
+		StartDate:   p.StartDate,
+		EndDate:     p.EndDate,
+	}
+	if err := tx.Create(resume).Error; err != nil {
+		return nil, err
+	}
+	return resume, resumeEvent(tx, ResumeCreated, resume)
+}
+
+// UpdateResumeParams changes the fields that are set.
+type UpdateResumeParams struct {
+	Title       *string    `json:"title,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	Category    *string    `json:"category,omitempty"`
+	StartDate   *time.Time `json:"startDate,omitempty"`
+	EndDate     *time.Time `json:"endDate,omitempty"`
+	// ExpectedVersion rejects the update with Aborted unless the section is
+	// still at this version.
+	ExpectedVersion *int `json:"expectedVersion,omitempty"`
+}
+
+// UpdateResume changes a resume section and publishes ResumeUpdated.
+//
+//encore:api private method=PATCH path=/sites/:siteID/resumes/:id
+func UpdateResume(ctx context.Context, siteID, id uint, p *UpdateResumeParams) (resume *Resume, err error) {
+	err = transaction(ctx, siteID, func(tx *gorm.DB) error {
+		resume, err = updateResume(tx, id, p)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resume, nil
+}
+
+func updateResume(tx *gorm.DB, id uint, p *UpdateResumeParams) (*Resume, error) {
+	var resume Resume
+	if err := tx.First(&resume, id).Error; err != nil {
+		return nil, err
+	}
+	if err := checkVersion(&resume, p.ExpectedVersion); err != nil {
+		return nil, err
+	}
+	if p.Title != nil {
+		resume.Title = *p.Title
+	}
+	if p.Description != nil {
+		resume.Description = *p.Description
+	}
+	if p.Category != nil {
+		resume.Category = *p.Category
+	}
+	if p.StartDate != nil {
+		resume.StartDate = p.StartDate
+	}
+	if p.EndDate != nil {
+		resume.EndDate = p.EndDate
+	}
+	if err := saveVersioned(tx, &resume, id); err != nil {
+		return nil, err
+	}
+	return &resume, resumeEvent(tx, ResumeUpdated, &resume)
+}
+
+// DeleteResume deletes a resume section and publishes ResumeDeleted. It
+// returns the deleted section.
+//
+//encore:api private method=DELETE path=/sites/:siteID/resumes/:id
+func DeleteResume(ctx context.Context, siteID, id uint) (resume *Resume, err error) {
+	err = transaction(ctx, siteID, func(tx *gorm.DB) error {
+		resume, err = deleteResume(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resume, nil
+}
+
+func deleteResume(tx *gorm.DB, id uint) (*Resume, error) {
+	// RETURNING fills in the deleted record for the event.
+	var resume Resume
+	res := tx.Clauses(clause.Returning{}).Delete(&resume, id)
+	if res.Error != nil {
+		return nil, stillReferenced(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &resume, resumeEvent(tx, ResumeDeleted, &resume)
+}
+
+// ResumeOp is one operation of a batch. Exactly one of its fields is set.
+type ResumeOp struct {
+	Create *CreateResumeParams `json:"create,omitempty"`
+	Update *UpdateResumeOp     `json:"update,omitempty"`
+	Delete *DeleteOp           `json:"delete,omitempty"`
+}
+
+type UpdateResumeOp struct {
+	ID     uint               `json:"id"`
+	Params UpdateResumeParams `json:"params"`
+}
+
+type BatchResumesParams struct {
+	Ops []*ResumeOp `json:"ops"`
+	// Atomic rolls back every operation if any of them fails.
+	Atomic bool `json:"atomic"`
+}
+
+type BatchResumesResponse struct {
+	// Committed reports whether the operations without an error took
+	// effect.
+	Committed bool `json:"committed"`
+	// Results holds the outcome of each operation, in order.
+	Results []*ResumeResult `json:"results"`
+}
+
+type ResumeResult struct {
+	// Resume is the created, updated or deleted section.
+	Resume *Resume    `json:"resume,omitempty"`
+	Error  *ItemError `json:"error,omitempty"`
+}
+
+// BatchResumes runs the operations in a single transaction, see runBatch.
+//
+//encore:api private method=POST path=/sites/:siteID/batch/resumes
+func BatchResumes(ctx context.Context, siteID uint, p *BatchResumesParams) (*BatchResumesResponse, error) {
+	results := make([]*ResumeResult, len(p.Ops))
+	failures, committed, err := runBatch(ctx, siteID, len(p.Ops), p.Atomic, func(tx *gorm.DB, i int) (err error) {
+		res := &ResumeResult{}
+		switch op := p.Ops[i]; {
+		case op.Create != nil:
+			res.Resume, err = createResume(tx, op.Create)
+		case op.Update != nil:
+			res.Resume, err = updateResume(tx, op.Update.ID, &op.Update.Params)
+		case op.Delete != nil:
+			res.Resume, err = deleteResume(tx, op.Delete.ID)
+		default:
+			err = errEmptyOp
+		}
+		results[i] = res
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		if failures[i] != nil || !committed {
+			results[i] = &ResumeResult{Error: failures[i]}
+		}
+	}
+	return &BatchResumesResponse{Committed: committed, Results: results}, nil
+}
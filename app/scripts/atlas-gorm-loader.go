@@ -11,14 +11,30 @@ import (
 
 // Define the models to generate migrations for.
 var models = []any{
+	&app.Site{},
 	&app.User{},
 	&app.Project{},
 	&app.Blog{},
 	&app.Resume{},
+	&app.ApiKey{},
+	&app.RateLimitBucket{},
+	&app.CacheInvalidation{},
+	&app.OutboxEvent{},
+	&app.Webhook{},
+	&app.WebhookDelivery{},
+	&app.ContactMessage{},
+	&app.NewsletterSubscriber{},
+	&app.NewsletterIssue{},
+	&app.ViewRollup{},
+	&app.BlogReaction{},
+	&app.BlogReactionCount{},
+	&app.BlogTranslation{},
+	&app.ProjectTranslation{},
+	&app.ResumeTranslation{},
 }
 
 func main() {
-	stmts, err := gormschema.New("postgres").Load(models...)
+	stmts, err := gormschema.New("postgres").Load(append(models, app.CRUDModels...)...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to load gorm schema: %v\n", err)
 		os.Exit(1)
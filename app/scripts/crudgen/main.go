@@ -0,0 +1,596 @@
+// Command crudgen generates the CRUD layer of the app models marked
+// //crud:generate: the endpoints of the app service in app/crud_gen.go, and
+// the schema, resolvers and their helpers of the graphql service in
+// graphql/crud.graphqls, graphql/crud.resolvers.go and graphql/crud_gen.go.
+//
+// It runs in the app directory through go generate, before gqlgen. See
+// "Generated Content Types" in the README for the annotations.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"gorm.io/gorm/schema"
+)
+
+const directive = "//crud:generate"
+
+// Output files, relative to the app directory.
+const (
+	appFile       = "crud_gen.go"
+	schemaFile    = "../graphql/crud.graphqls"
+	resolversFile = "../graphql/crud.resolvers.go"
+	helpersFile   = "../graphql/crud_gen.go"
+)
+
+const generatedBy = "Code generated by crudgen from the app models marked //crud:generate. DO NOT EDIT."
+
+// gqlgenVersion is the gqlgen version named in the header of the resolvers
+// file, which gqlgen rewrites with its own version.
+const gqlgenVersion = "v0.17.81"
+
+func main() {
+	models, err := load(".")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "crudgen:", err)
+		os.Exit(1)
+	}
+	if err := generate(models); err != nil {
+		fmt.Fprintln(os.Stderr, "crudgen:", err)
+		os.Exit(1)
+	}
+}
+
+// model is a struct marked //crud:generate.
+type model struct {
+	// Name is the name of the struct, which is also the GraphQL type name
+	// and the type of its global IDs.
+	Name string
+	// Plural is the plural of Name, e.g. Testimonials.
+	Plural string
+	Doc    string
+	Fields []*field
+}
+
+// field is a column of a model that is exposed through GraphQL.
+type field struct {
+	Name   string
+	JSON   string
+	Column string
+	Doc    string
+	// Type is the Go type without the pointer, e.g. string or time.Time.
+	Type    string
+	Pointer bool
+	// Date is set for time fields stored as a date.
+	Date bool
+	// ReadOnly fields are set by the database, such as CreatedAt.
+	ReadOnly bool
+	// Optional non-pointer fields may be left out when creating a record.
+	Optional bool
+	Filter   bool
+	// Constraint holds the arguments of the @constraint directive of inputs.
+	Constraint string
+}
+
+// filter is a condition of the list endpoint of a model.
+type filter struct {
+	Param  string
+	JSON   string
+	Type   string
+	GQL    string
+	Column string
+	// Op is "contains" or a comparison operator.
+	Op string
+}
+
+// goTypes maps the supported Go types to their GraphQL types.
+var goTypes = map[string]string{
+	"string":    "String",
+	"bool":      "Boolean",
+	"int":       "Int",
+	"float64":   "Float",
+	"time.Time": "DateTime",
+}
+
+// load finds the models marked //crud:generate in the package in dir.
+func load(dir string) ([]*model, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	var models []*model
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") || filepath.Base(path) == appFile {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				doc := ts.Doc
+				if doc == nil && len(gen.Specs) == 1 {
+					doc = gen.Doc
+				}
+				opts, ok := directiveOptions(doc)
+				if !ok {
+					continue
+				}
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok {
+					return nil, fmt.Errorf("%s: %s is marked %s but is not a struct", fset.Position(ts.Pos()), ts.Name.Name, directive)
+				}
+				m, err := newModel(ts.Name.Name, doc.Text(), opts, st)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", fset.Position(ts.Pos()), err)
+				}
+				models = append(models, m)
+			}
+		}
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].Name < models[j].Name })
+	return models, nil
+}
+
+// directiveOptions returns the key=value options of the //crud:generate line
+// of doc, if there is one.
+func directiveOptions(doc *ast.CommentGroup) (map[string]string, bool) {
+	if doc == nil {
+		return nil, false
+	}
+	for _, c := range doc.List {
+		rest, ok := strings.CutPrefix(c.Text, directive)
+		if !ok || rest != "" && rest[0] != ' ' {
+			continue
+		}
+		opts := make(map[string]string)
+		for _, opt := range strings.Fields(rest) {
+			k, v, _ := strings.Cut(opt, "=")
+			opts[k] = v
+		}
+		return opts, true
+	}
+	return nil, false
+}
+
+func newModel(name, doc string, opts map[string]string, st *ast.StructType) (*model, error) {
+	m := &model{Name: name, Plural: plural(name), Doc: doc}
+	for k, v := range opts {
+		switch k {
+		case "plural":
+			m.Plural = v
+		default:
+			return nil, fmt.Errorf("%s: unknown option %q", name, k)
+		}
+	}
+	var versioned, site bool
+	for _, f := range st.Fields.List {
+		if len(f.Names) == 0 {
+			if id, ok := f.Type.(*ast.Ident); ok && id.Name == "Versioned" {
+				versioned = true
+				continue
+			}
+			return nil, fmt.Errorf("%s: only Versioned may be embedded", name)
+		}
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			s, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			tag = reflect.StructTag(s)
+		}
+		for _, n := range f.Names {
+			switch {
+			case n.Name == "ID":
+				continue
+			case n.Name == "SiteID":
+				site = true
+				continue
+			case tag.Get("crud") == "-" || !n.IsExported():
+				continue
+			}
+			fd, err := newField(n.Name, f.Doc.Text(), f.Type, tag)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", name, n.Name, err)
+			}
+			m.Fields = append(m.Fields, fd)
+		}
+	}
+	switch {
+	case !versioned:
+		return nil, fmt.Errorf("%s must embed Versioned", name)
+	case !site:
+		return nil, fmt.Errorf("%s must have a SiteID field", name)
+	case len(m.Writable()) == 0:
+		return nil, fmt.Errorf("%s has no field that can be written", name)
+	}
+	return m, nil
+}
+
+func newField(name, doc string, expr ast.Expr, tag reflect.StructTag) (*field, error) {
+	f := &field{
+		Name:     name,
+		JSON:     lowerFirst(name),
+		Column:   schema.NamingStrategy{}.ColumnName("", name),
+		Doc:      doc,
+		ReadOnly: name == "CreatedAt" || name == "UpdatedAt",
+		Date:     strings.Contains(tag.Get("gorm"), "type:date"),
+	}
+	if star, ok := expr.(*ast.StarExpr); ok {
+		f.Pointer = true
+		expr = star.X
+	}
+	switch t := expr.(type) {
+	case *ast.Ident:
+		f.Type = t.Name
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok {
+			f.Type = pkg.Name + "." + t.Sel.Name
+		}
+	}
+	if _, ok := goTypes[f.Type]; !ok {
+		return nil, fmt.Errorf(`unsupported type; tag the field crud:"-" to leave it out`)
+	}
+	if f.Date && f.Type != "time.Time" {
+		return nil, fmt.Errorf("only time.Time can be stored as a date")
+	}
+	var constraint []string
+	if opts := tag.Get("crud"); opts != "" {
+		for _, opt := range strings.Split(opts, ",") {
+			k, v, _ := strings.Cut(opt, "=")
+			switch k {
+			case "readonly":
+				f.ReadOnly = true
+			case "optional":
+				f.Optional = true
+			case "filter":
+				if f.Type != "string" && f.Type != "time.Time" {
+					return nil, fmt.Errorf("only string and time fields can be filtered")
+				}
+				f.Filter = true
+			case "minLength", "maxLength":
+				if _, err := strconv.Atoi(v); err != nil || f.Type != "string" {
+					return nil, fmt.Errorf("%s needs a string field and a number", k)
+				}
+				constraint = append(constraint, k+": "+v)
+			case "format":
+				if v != "email" && v != "url" || f.Type != "string" {
+					return nil, fmt.Errorf("format needs a string field and email or url")
+				}
+				constraint = append(constraint, "format: "+strings.ToUpper(v))
+			default:
+				return nil, fmt.Errorf("unknown crud option %q", k)
+			}
+		}
+	}
+	f.Constraint = strings.Join(constraint, ", ")
+	return f, nil
+}
+
+// Writable returns the fields that can be set by creates and updates.
+func (m *model) Writable() []*field {
+	var fields []*field
+	for _, f := range m.Fields {
+		if !f.ReadOnly {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// Filters returns the conditions of the list endpoint, other than the IDs.
+func (m *model) Filters() []*filter {
+	var filters []*filter
+	for _, f := range m.Fields {
+		switch {
+		case !f.Filter:
+		case f.Type == "string":
+			filters = append(filters, &filter{Param: f.Name + "Contains", JSON: f.JSON + "Contains", Type: "string", GQL: "String", Column: f.Column, Op: "contains"})
+		default:
+			gql := f.GQLType()
+			filters = append(filters,
+				&filter{Param: f.Name + "Before", JSON: f.JSON + "Before", Type: "time.Time", GQL: gql, Column: f.Column, Op: "<"},
+				&filter{Param: f.Name + "After", JSON: f.JSON + "After", Type: "time.Time", GQL: gql, Column: f.Column, Op: ">"},
+			)
+		}
+	}
+	return filters
+}
+
+// Var is the name of the GraphQL type with a lowercase first letter, e.g.
+// testimonial.
+func (m *model) Var() string { return lowerFirst(m.Name) }
+
+// PluralVar is Var for the plural.
+func (m *model) PluralVar() string { return lowerFirst(m.Plural) }
+
+// Words is the name in lowercase words, e.g. "blog post".
+func (m *model) Words() string { return words(m.Name) }
+
+// PluralWords is Words for the plural.
+func (m *model) PluralWords() string { return words(m.Plural) }
+
+// Path is the path segment of the endpoints, e.g. blog-posts.
+func (m *model) Path() string { return strings.ReplaceAll(words(m.Plural), " ", "-") }
+
+// Scope is the suffix of the read: and write: scopes, e.g. blogPosts.
+func (m *model) Scope() string { return m.PluralVar() }
+
+// NeedsTime reports whether the endpoints of m use package time.
+func (m *model) NeedsTime() bool {
+	for _, f := range m.Fields {
+		if f.Type == "time.Time" && (!f.ReadOnly || f.Filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// GoType is the type of the field in the model and create params.
+func (f *field) GoType() string {
+	if f.Pointer {
+		return "*" + f.Type
+	}
+	return f.Type
+}
+
+// GQLType is the named GraphQL type of the field.
+func (f *field) GQLType() string {
+	if f.Date {
+		return "Date"
+	}
+	return goTypes[f.Type]
+}
+
+// Omit reports whether the field may be left out of a create.
+func (f *field) Omit() bool { return f.Pointer || f.Optional }
+
+// InputGoType is the Go type gqlgen generates for the field of an input.
+func (f *field) InputGoType(create bool) string {
+	if create && !f.Omit() {
+		return f.Type
+	}
+	return "*" + f.Type
+}
+
+func generate(models []*model) error {
+	files, err := render(models)
+	if err != nil {
+		return err
+	}
+	for _, path := range outputs {
+		src := files[path]
+		if src == nil {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		if err := os.WriteFile(path, src, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outputs lists the files render writes.
+var outputs = []string{appFile, helpersFile, schemaFile, resolversFile}
+
+// render returns the contents of the output files for models, by path. Files
+// that models do not need are left out, and removed by generate.
+func render(models []*model) (map[string][]byte, error) {
+	needsTime := false
+	for _, m := range models {
+		needsTime = needsTime || m.NeedsTime()
+	}
+	data := struct {
+		Models        []*model
+		NeedsTime     bool
+		GeneratedBy   string
+		GqlgenVersion string
+	}{models, needsTime, generatedBy, gqlgenVersion}
+
+	files := make(map[string][]byte)
+	var err error
+	if files[appFile], err = renderGo(appFile, appTemplate, data); err != nil {
+		return nil, err
+	}
+	if files[helpersFile], err = renderGo(helpersFile, helpersTemplate, data); err != nil {
+		return nil, err
+	}
+	if len(models) == 0 {
+		// gqlgen cannot load an empty schema file, and without one there are
+		// no resolvers.
+		return files, nil
+	}
+	files[schemaFile] = []byte(schemaSource(models))
+	if files[resolversFile], err = renderGo(resolversFile, resolversTemplate, data); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func renderGo(path string, tmpl *template.Template, data any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w\n%s", path, err, buf.Bytes())
+	}
+	return src, nil
+}
+
+// schemaSource returns the GraphQL schema of models.
+func schemaSource(models []*model) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", generatedBy)
+	for _, m := range models {
+		read := fmt.Sprintf(`@hasScope(scope: "read:%s")`, m.Scope())
+		write := fmt.Sprintf(`@hasScope(scope: "write:%s")`, m.Scope())
+
+		b.WriteString("\n")
+		writeDescription(&b, "", m.Doc)
+		fmt.Fprintf(&b, "type %s implements Node @goModel(model: \"encore.app/app.%s\") %s @cacheControl(maxAge: 300) {\n", m.Name, m.Name, read)
+		b.WriteString("  id: ID!\n")
+		for _, f := range m.Fields {
+			writeDescription(&b, "  ", f.Doc)
+			args := ""
+			if f.GQLType() == "DateTime" {
+				args = "(format: String, timezone: String)"
+			}
+			nonNull := "!"
+			if f.Pointer {
+				nonNull = ""
+			}
+			fmt.Fprintf(&b, "  %s%s: %s%s\n", f.JSON, args, f.GQLType(), nonNull)
+		}
+		b.WriteString("  \"Incremented by every update. Pass it as expectedVersion to detect concurrent edits.\"\n")
+		b.WriteString("  version: Int!\n")
+		b.WriteString("  updatedAt(format: String, timezone: String): DateTime!\n")
+		b.WriteString("}\n")
+
+		fmt.Fprintf(&b, "\ntype %sConnection @cacheControl(maxAge: 300) {\n", m.Name)
+		fmt.Fprintf(&b, "  edges: [%sEdge!]!\n", m.Name)
+		b.WriteString("  pageInfo: PageInfo!\n}\n")
+		fmt.Fprintf(&b, "\ntype %sEdge @cacheControl(maxAge: 300) {\n", m.Name)
+		fmt.Fprintf(&b, "  \"Pass it as after to list the %s that follow.\"\n", m.PluralWords())
+		b.WriteString("  cursor: String!\n")
+		fmt.Fprintf(&b, "  node: %s!\n}\n", m.Name)
+
+		fmt.Fprintf(&b, "\ninput Create%sInput {\n", m.Name)
+		for _, f := range m.Writable() {
+			nonNull := "!"
+			if f.Omit() {
+				nonNull = ""
+			}
+			fmt.Fprintf(&b, "  %s: %s%s%s\n", f.JSON, f.GQLType(), nonNull, constraint(f))
+		}
+		b.WriteString("}\n")
+		fmt.Fprintf(&b, "\ninput Update%sInput {\n", m.Name)
+		for _, f := range m.Writable() {
+			fmt.Fprintf(&b, "  %s: %s%s\n", f.JSON, f.GQLType(), constraint(f))
+		}
+		b.WriteString("  \"Reject the update with CONFLICT unless the record is still at this version.\"\n")
+		b.WriteString("  expectedVersion: Int\n}\n")
+		if filters := m.Filters(); len(filters) > 0 {
+			fmt.Fprintf(&b, "\ninput %sFilter {\n", m.Name)
+			for _, f := range filters {
+				fmt.Fprintf(&b, "  %s: %s\n", f.JSON, f.GQL)
+			}
+			b.WriteString("}\n")
+		}
+
+		for _, op := range []string{"Create", "Update"} {
+			fmt.Fprintf(&b, "\ntype %s%sPayload {\n", op, m.Name)
+			fmt.Fprintf(&b, "  %s: %s\n", m.Var(), m.Name)
+			b.WriteString("  userErrors: [UserError!]!\n  clientMutationId: String\n}\n")
+		}
+		fmt.Fprintf(&b, "\ntype Delete%sPayload {\n", m.Name)
+		b.WriteString("  deletedId: ID\n  userErrors: [UserError!]!\n  clientMutationId: String\n}\n")
+
+		where := ""
+		if len(m.Filters()) > 0 {
+			where = fmt.Sprintf(", where: %sFilter", m.Name)
+		}
+		b.WriteString("\nextend type Query {\n")
+		fmt.Fprintf(&b, "  \"The %s by ID, first at a time after the cursor after.\"\n", m.PluralWords())
+		fmt.Fprintf(&b, "  %s(first: Int = 20, after: String @constraint(maxLength: 200)%s): %sConnection! %s\n", m.PluralVar(), where, m.Name, read)
+		fmt.Fprintf(&b, "  %s(id: ID!): %s\n}\n", m.Var(), m.Name)
+		b.WriteString("\nextend type Mutation {\n")
+		fmt.Fprintf(&b, "  %sCreate(input: Create%sInput!, clientMutationId: String): Create%sPayload! %s\n", m.Var(), m.Name, m.Name, write)
+		fmt.Fprintf(&b, "  %sUpdate(id: ID!, input: Update%sInput!, clientMutationId: String): Update%sPayload! %s\n", m.Var(), m.Name, m.Name, write)
+		fmt.Fprintf(&b, "  %sDelete(id: ID!, clientMutationId: String): Delete%sPayload! %s\n}\n", m.Var(), m.Name, write)
+	}
+	return b.String()
+}
+
+func constraint(f *field) string {
+	if f.Constraint == "" {
+		return ""
+	}
+	return " @constraint(" + f.Constraint + ")"
+}
+
+// writeDescription writes doc, a Go doc comment, as a GraphQL description.
+func writeDescription(b *strings.Builder, indent, doc string) {
+	doc = strings.TrimSpace(doc)
+	if doc == "" {
+		return
+	}
+	if !strings.Contains(doc, "\n") && !strings.Contains(doc, `"`) {
+		fmt.Fprintf(b, "%s%q\n", indent, doc)
+		return
+	}
+	fmt.Fprintf(b, "%s\"\"\"\n", indent)
+	for _, line := range strings.Split(doc, "\n") {
+		fmt.Fprintf(b, "%s%s\n", indent, strings.ReplaceAll(line, `"""`, `\"""`))
+	}
+	fmt.Fprintf(b, "%s\"\"\"\n", indent)
+}
+
+// lowerFirst lowercases the leading initialism or letter of a Go name, e.g.
+// ID to id, URLPath to urlPath and UserID to userID.
+func lowerFirst(s string) string {
+	r := []rune(s)
+	n := 0
+	for n < len(r) && unicode.IsUpper(r[n]) {
+		n++
+	}
+	switch {
+	case n == len(r):
+		return strings.ToLower(s)
+	case n > 1:
+		// The last capital starts the next word.
+		n--
+	}
+	return strings.ToLower(string(r[:n])) + string(r[n:])
+}
+
+// words splits a Go name into lowercase words, e.g. BlogPost to "blog post".
+func words(s string) string {
+	var b strings.Builder
+	r := []rune(s)
+	for i, c := range r {
+		if i > 0 && unicode.IsUpper(c) && (unicode.IsLower(r[i-1]) || i+1 < len(r) && unicode.IsLower(r[i+1])) {
+			b.WriteRune(' ')
+		}
+		b.WriteRune(unicode.ToLower(c))
+	}
+	return b.String()
+}
+
+// plural returns the English plural of a noun, which the plural option
+// overrides where this gets it wrong.
+func plural(s string) string {
+	switch {
+	case strings.HasSuffix(s, "y") && len(s) > 1 && !strings.ContainsRune("aeiou", rune(s[len(s)-2])):
+		return s[:len(s)-1] + "ies"
+	case strings.HasSuffix(s, "s"), strings.HasSuffix(s, "x"), strings.HasSuffix(s, "z"),
+		strings.HasSuffix(s, "ch"), strings.HasSuffix(s, "sh"):
+		return s + "es"
+	}
+	return s + "s"
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+// goldenFile returns the golden file of the output path, e.g.
+// testdata/graphql_crud_gen.go.golden for ../graphql/crud_gen.go.
+func goldenFile(path string) string {
+	name := strings.ReplaceAll(strings.TrimPrefix(path, "../"), "/", "_")
+	return filepath.Join("testdata", name+".golden")
+}
+
+func renderFixture(t *testing.T) map[string][]byte {
+	t.Helper()
+	models, err := load("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 2 {
+		t.Fatalf("loaded %d models from testdata, want 2", len(models))
+	}
+	files, err := render(models)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestGolden(t *testing.T) {
+	files := renderFixture(t)
+	for _, path := range outputs {
+		got, ok := files[path]
+		if !ok {
+			t.Errorf("%s was not rendered", path)
+			continue
+		}
+		golden := goldenFile(path)
+		if *update {
+			if err := os.WriteFile(golden, got, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s differs from %s; run go test -update if the change is intended", path, golden)
+		}
+	}
+}
+
+// TestGeneratedAppCompiles type-checks the generated endpoints together with
+// the fixture models and the rest of the app package.
+func TestGeneratedAppCompiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the app package")
+	}
+	files := renderFixture(t)
+	appDir, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixture, err := filepath.Abs("testdata/models.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated := filepath.Join(t.TempDir(), appFile)
+	if err := os.WriteFile(generated, files[appFile], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	overlay, err := json.Marshal(map[string]any{"Replace": map[string]string{
+		filepath.Join(appDir, appFile):                  generated,
+		filepath.Join(appDir, "crudgen_fixture_gen.go"): fixture,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	overlayFile := filepath.Join(t.TempDir(), "overlay.json")
+	if err := os.WriteFile(overlayFile, overlay, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("go", "vet", "-overlay", overlayFile, ".")
+	cmd.Dir = appDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go vet: %v\n%s", err, out)
+	}
+}
+
+// TestGeneratedGraphQLCompiles runs gqlgen with the schema generated for the
+// fixture models, and builds the graphql package with the result, in a copy of
+// the module.
+func TestGeneratedGraphQLCompiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs gqlgen and builds the graphql package")
+	}
+	files := renderFixture(t)
+	root, err := filepath.Abs("../../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	module := t.TempDir()
+	if err := copyModule(root, module); err != nil {
+		t.Fatal(err)
+	}
+	appDir := filepath.Join(module, "app")
+	fixture, err := os.ReadFile("testdata/models.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files["crudgen_fixture_gen.go"] = fixture
+	for path, content := range files {
+		if err := os.WriteFile(filepath.Join(appDir, path), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{
+		// gqlgen reports load failures through the log, which it discards
+		// unless it is verbose.
+		{"run", "github.com/99designs/gqlgen", "--verbose", "generate"},
+		{"build", "./graphql/..."},
+	} {
+		cmd := exec.Command("go", args...)
+		cmd.Dir = module
+		// gqlgen may need go.sum entries of its own dependencies.
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+		out, err := cmd.CombinedOutput()
+		if err != nil && bytes.Contains(out, []byte("without types was imported")) {
+			// The pinned golang.org/x/tools cannot load packages compiled
+			// by a newer Go release.
+			t.Skipf("gqlgen cannot load packages with %s:\n%s", runtime.Version(), out)
+		}
+		if err != nil {
+			t.Fatalf("go %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+}
+
+// copyModule copies the files of the module at src to dst, without its git
+// history.
+func copyModule(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(filepath.Join(dst, rel), 0o755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dst, rel), content, 0o644)
+	})
+}
+
+func TestGeneratedSchemaParses(t *testing.T) {
+	files := renderFixture(t)
+	if _, err := parser.ParseSchema(&ast.Source{Name: schemaFile, Input: string(files[schemaFile])}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRenderWithoutModels(t *testing.T) {
+	files, err := render(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := files[schemaFile]; ok {
+		t.Errorf("%s was rendered without models", schemaFile)
+	}
+	if _, ok := files[resolversFile]; ok {
+		t.Errorf("%s was rendered without models", resolversFile)
+	}
+	for _, path := range []string{appFile, helpersFile} {
+		if files[path] == nil {
+			t.Errorf("%s was not rendered", path)
+		}
+	}
+}
@@ -0,0 +1,378 @@
+package main
+
+import "text/template"
+
+var appTemplate = template.Must(template.New("app").Parse(`// {{.GeneratedBy}}
+
+package app
+{{if .Models}}
+import (
+	"context"
+{{- if .NeedsTime}}
+	"time"
+{{- end}}
+)
+{{end}}
+// CRUDModels lists the models with generated endpoints, for the migrations.
+var CRUDModels = []any{
+{{- range .Models}}
+	&{{.Name}}{},
+{{- end}}
+}
+
+// crudEventTypes lists the EventTypes of the generated models.
+var crudEventTypes = []EventType{
+{{- range .Models}}
+	{{.Name}}Created, {{.Name}}Updated, {{.Name}}Deleted,
+{{- end}}
+}
+{{range .Models}}
+// Type{{.Name}} is the type name in the global IDs of {{.PluralWords}}.
+const Type{{.Name}} = "{{.Name}}"
+
+// Events of {{.PluralWords}}, published to ContentEvents.
+const (
+	{{.Name}}Created EventType = "{{.Name}}Created"
+	{{.Name}}Updated EventType = "{{.Name}}Updated"
+	{{.Name}}Deleted EventType = "{{.Name}}Deleted"
+)
+
+func (r *{{.Name}}) crudKey() (typ string, id, siteID uint) {
+	return Type{{.Name}}, r.ID, r.SiteID
+}
+
+type List{{.Plural}}Params struct {
+	// IDs limits the list to these {{.PluralWords}}.
+	IDs []uint ` + "`" + `query:"id"` + "`" + `
+	// After only lists {{.PluralWords}} with a greater ID, and Limit at most
+	// that many. Both are ignored when zero.
+	After uint ` + "`" + `query:"after"` + "`" + `
+	Limit int  ` + "`" + `query:"limit"` + "`" + `
+{{- range .Filters}}
+	{{.Param}} {{.Type}} ` + "`" + `query:"{{.JSON}}"` + "`" + `
+{{- end}}
+}
+
+type List{{.Plural}}Response struct {
+	{{.Plural}} []*{{.Name}} ` + "`" + `json:"{{.PluralVar}}"` + "`" + `
+}
+
+// List{{.Plural}} lists the {{.PluralWords}} of a site that match every given
+// condition, by ID.
+//
+//encore:api private method=GET path=/sites/:siteID/{{.Path}}
+func List{{.Plural}}(ctx context.Context, siteID uint, p *List{{.Plural}}Params) (*List{{.Plural}}Response, error) {
+	var f filter
+	if len(p.IDs) > 0 {
+		f.add("id IN ?", p.IDs)
+	}
+{{- range .Filters}}
+{{- if eq .Op "contains"}}
+	f.contains("{{.Column}}", p.{{.Param}})
+{{- else}}
+	if !p.{{.Param}}.IsZero() {
+		f.add("{{.Column}} {{.Op}} ?", p.{{.Param}})
+	}
+{{- end}}
+{{- end}}
+	records, err := listRecords[{{.Name}}](ctx, siteID, &f, p.After, p.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return &List{{.Plural}}Response{ {{- .Plural}}: records}, nil
+}
+
+// Get{{.Name}} returns a {{.Words}}, or NotFound.
+//
+//encore:api private method=GET path=/sites/:siteID/{{.Path}}/:id
+func Get{{.Name}}(ctx context.Context, siteID, id uint) (*{{.Name}}, error) {
+	return getRecord[{{.Name}}](ctx, siteID, id)
+}
+
+type Create{{.Name}}Params struct {
+{{- range .Writable}}
+	{{.Name}} {{.GoType}} ` + "`" + `json:"{{.JSON}}{{if .Omit}},omitempty{{end}}"` + "`" + `
+{{- end}}
+}
+
+// Create{{.Name}} creates a {{.Words}} and publishes {{.Name}}Created.
+//
+//encore:api private method=POST path=/sites/:siteID/{{.Path}}
+func Create{{.Name}}(ctx context.Context, siteID uint, p *Create{{.Name}}Params) (*{{.Name}}, error) {
+	return createRecord(ctx, siteID, {{.Name}}Created, &{{.Name}}{
+{{- range .Writable}}
+		{{.Name}}: p.{{.Name}},
+{{- end}}
+	})
+}
+
+// Update{{.Name}}Params changes the fields that are set.
+type Update{{.Name}}Params struct {
+{{- range .Writable}}
+	{{.Name}} *{{.Type}} ` + "`" + `json:"{{.JSON}},omitempty"` + "`" + `
+{{- end}}
+	// ExpectedVersion rejects the update with Aborted unless the {{.Words}} is
+	// still at this version.
+	ExpectedVersion *int ` + "`" + `json:"expectedVersion,omitempty"` + "`" + `
+}
+
+// Update{{.Name}} changes a {{.Words}} and publishes {{.Name}}Updated.
+//
+//encore:api private method=PATCH path=/sites/:siteID/{{.Path}}/:id
+func Update{{.Name}}(ctx context.Context, siteID, id uint, p *Update{{.Name}}Params) (*{{.Name}}, error) {
+	return updateRecord[{{.Name}}](ctx, siteID, id, {{.Name}}Updated, p.ExpectedVersion, func(record *{{.Name}}) {
+{{- range .Writable}}
+		if p.{{.Name}} != nil {
+			record.{{.Name}} = {{if not .Pointer}}*{{end}}p.{{.Name}}
+		}
+{{- end}}
+	})
+}
+
+// Delete{{.Name}} deletes a {{.Words}}, publishes {{.Name}}Deleted and
+// returns it.
+//
+//encore:api private method=DELETE path=/sites/:siteID/{{.Path}}/:id
+func Delete{{.Name}}(ctx context.Context, siteID, id uint) (*{{.Name}}, error) {
+	return deleteRecord[{{.Name}}](ctx, siteID, id, {{.Name}}Deleted)
+}
+{{end}}`))
+
+var helpersTemplate = template.Must(template.New("helpers").Parse(`// {{.GeneratedBy}}
+
+package graphql
+
+import (
+	"context"
+{{if .Models}}
+	"encore.app/app"
+{{- end}}
+	"encore.app/graphql/model"
+)
+{{if .Models}}
+// Type names used as the prefix of global IDs.
+const (
+{{- range .Models}}
+	type{{.Name}} = "{{.Name}}"
+{{- end}}
+)
+{{end}}
+// crudNode returns the type and primary key of a record of a generated type.
+func crudNode(n any) (typ string, pk uint, ok bool) {
+{{- if .Models}}
+	switch n := n.(type) {
+{{- range .Models}}
+	case *app.{{.Name}}:
+		return type{{.Name}}, n.ID, true
+{{- end}}
+	}
+{{- end}}
+	return "", 0, false
+}
+
+// crudConnection returns the type of the records of a generated connection
+// type.
+func crudConnection(name string) (typ string, ok bool) {
+{{- if .Models}}
+	switch name {
+{{- range .Models}}
+	case "{{.Name}}Connection":
+		return type{{.Name}}, true
+{{- end}}
+	}
+{{- end}}
+	return "", false
+}
+
+// loadCRUDNodes adds the records of a generated type to found, see loadNodes.
+func loadCRUDNodes(ctx context.Context, found map[nodeKey]model.Node, typ string, pks []uint) error {
+{{- if .Models}}
+	switch typ {
+{{- range .Models}}
+	case type{{.Name}}:
+		resp, err := app.List{{.Plural}}(ctx, siteOf(ctx), &app.List{{.Plural}}Params{IDs: pks})
+		if err != nil {
+			return err
+		}
+		addNodes(found, typ, resp.{{.Plural}}, func(record *app.{{.Name}}) uint { return record.ID })
+{{- end}}
+	}
+{{- end}}
+	return nil
+}
+{{range $m := .Models}}
+func (r *Resolver) list{{.Plural}}(ctx context.Context, first *int, after *string{{if .Filters}}, where *model.{{.Name}}Filter{{end}}) (*model.{{.Name}}Connection, error) {
+	limit, afterPK, err := pageArgs(type{{.Name}}, first, after)
+	if err != nil {
+		return nil, err
+	}
+	p := &app.List{{.Plural}}Params{After: afterPK, Limit: limit}
+{{- if .Filters}}
+	if where != nil {
+{{- range .Filters}}
+		if where.{{.Param}} != nil {
+			p.{{.Param}} = *where.{{.Param}}
+		}
+{{- end}}
+	}
+{{- end}}
+	resp, err := app.List{{.Plural}}(ctx, siteOf(ctx), p)
+	if err != nil {
+		return nil, err
+	}
+	records, pageInfo := paginate(type{{.Name}}, resp.{{.Plural}}, limit, func(record *app.{{.Name}}) uint { return record.ID })
+	conn := &model.{{.Name}}Connection{Edges: make([]*model.{{.Name}}Edge, len(records)), PageInfo: pageInfo}
+	for i, record := range records {
+		conn.Edges[i] = &model.{{.Name}}Edge{Cursor: globalID(type{{.Name}}, record.ID), Node: record}
+	}
+	return conn, nil
+}
+
+func (r *Resolver) get{{.Name}}(ctx context.Context, id string) (*app.{{.Name}}, error) {
+	pk, err := parseID("id", type{{.Name}}, id)
+	if err != nil {
+		return nil, err
+	}
+	record, err := app.Get{{.Name}}(ctx, siteOf(ctx), pk)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	return record, err
+}
+
+func (r *Resolver) create{{.Name}}(ctx context.Context, input model.Create{{.Name}}Input, clientMutationID *string) (*model.Create{{.Name}}Payload, error) {
+	payload := &model.Create{{.Name}}Payload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() (err error) {
+		p := &app.Create{{.Name}}Params{
+{{- range .Writable}}
+{{- if or .Pointer (not .Omit)}}
+			{{.Name}}: input.{{.Name}},
+{{- end}}
+{{- end}}
+		}
+{{- range .Writable}}
+{{- if and .Omit (not .Pointer)}}
+		if input.{{.Name}} != nil {
+			p.{{.Name}} = *input.{{.Name}}
+		}
+{{- end}}
+{{- end}}
+		payload.{{.Name}}, err = app.Create{{.Name}}(ctx, siteOf(ctx), p)
+		if err != nil {
+			return err
+		}
+		invalidate(ctx, type{{.Name}}, payload.{{.Name}}.ID)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
+func (r *Resolver) update{{.Name}}(ctx context.Context, id string, input model.Update{{.Name}}Input, clientMutationID *string) (*model.Update{{.Name}}Payload, error) {
+	payload := &model.Update{{.Name}}Payload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() (err error) {
+		pk, err := parseID("id", type{{.Name}}, id)
+		if err != nil {
+			return err
+		}
+		payload.{{.Name}}, err = app.Update{{.Name}}(ctx, siteOf(ctx), pk, &app.Update{{.Name}}Params{
+{{- range .Writable}}
+			{{.Name}}: input.{{.Name}},
+{{- end}}
+			ExpectedVersion: input.ExpectedVersion,
+		})
+		if err != nil {
+			return withCurrent(err, func() (model.Node, error) { return app.Get{{.Name}}(ctx, siteOf(ctx), pk) })
+		}
+		invalidate(ctx, type{{.Name}}, pk)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
+func (r *Resolver) delete{{.Name}}(ctx context.Context, id string, clientMutationID *string) (*model.Delete{{.Name}}Payload, error) {
+	payload := &model.Delete{{.Name}}Payload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() error {
+		pk, err := parseID("id", type{{.Name}}, id)
+		if err != nil {
+			return err
+		}
+		if _, err := app.Delete{{.Name}}(ctx, siteOf(ctx), pk); err != nil {
+			return err
+		}
+		invalidate(ctx, type{{.Name}}, pk)
+		payload.DeletedID = &id
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+{{end}}`))
+
+// resolversTemplate follows the layout and order of the resolver files gqlgen
+// writes, so that gqlgen keeps the implementations when it regenerates the
+// file.
+var resolversTemplate = template.Must(template.New("resolvers").Parse(`package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version {{.GqlgenVersion}}
+
+import (
+	"context"
+
+	"encore.app/app"
+	"encore.app/graphql/generated"
+	"encore.app/graphql/model"
+)
+{{range .Models}}
+// {{.Name}}Create is the resolver for the {{.Var}}Create field.
+func (r *mutationResolver) {{.Name}}Create(ctx context.Context, input model.Create{{.Name}}Input, clientMutationID *string) (*model.Create{{.Name}}Payload, error) {
+	return r.create{{.Name}}(ctx, input, clientMutationID)
+}
+
+// {{.Name}}Update is the resolver for the {{.Var}}Update field.
+func (r *mutationResolver) {{.Name}}Update(ctx context.Context, id string, input model.Update{{.Name}}Input, clientMutationID *string) (*model.Update{{.Name}}Payload, error) {
+	return r.update{{.Name}}(ctx, id, input, clientMutationID)
+}
+
+// {{.Name}}Delete is the resolver for the {{.Var}}Delete field.
+func (r *mutationResolver) {{.Name}}Delete(ctx context.Context, id string, clientMutationID *string) (*model.Delete{{.Name}}Payload, error) {
+	return r.delete{{.Name}}(ctx, id, clientMutationID)
+}
+{{end}}
+{{- range .Models}}
+// {{.Plural}} is the resolver for the {{.PluralVar}} field.
+func (r *queryResolver) {{.Plural}}(ctx context.Context, first *int, after *string{{if .Filters}}, where *model.{{.Name}}Filter{{end}}) (*model.{{.Name}}Connection, error) {
+	return r.list{{.Plural}}(ctx, first, after{{if .Filters}}, where{{end}})
+}
+
+// {{.Name}} is the resolver for the {{.Var}} field.
+func (r *queryResolver) {{.Name}}(ctx context.Context, id string) (*app.{{.Name}}, error) {
+	return r.get{{.Name}}(ctx, id)
+}
+{{end}}
+{{- range .Models}}
+// ID is the resolver for the id field.
+func (r *{{.Var}}Resolver) ID(ctx context.Context, obj *app.{{.Name}}) (string, error) {
+	return globalID(type{{.Name}}, obj.ID), nil
+}
+{{end}}
+{{- range .Models}}
+// {{.Name}} returns generated.{{.Name}}Resolver implementation.
+func (r *Resolver) {{.Name}}() generated.{{.Name}}Resolver { return &{{.Var}}Resolver{r} }
+{{end}}
+{{- range .Models}}
+type {{.Var}}Resolver struct{ *Resolver }
+{{- end}}
+`))
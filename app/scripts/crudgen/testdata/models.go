@@ -0,0 +1,34 @@
+package app
+
+import "time"
+
+// Review is what a client thought of a project.
+//
+//crud:generate
+type Review struct {
+	ID     uint `gorm:"primaryKey"`
+	SiteID uint `gorm:"not null;index"`
+	// Author is who wrote the review.
+	Author    string     `crud:"filter,minLength=1,maxLength=100"`
+	Quote     string     `crud:"maxLength=2000"`
+	Rating    int        `crud:"optional"`
+	GivenOn   *time.Time `gorm:"type:date" crud:"filter"`
+	Website   *string    `crud:"format=url"`
+	CreatedAt time.Time
+	Versioned
+}
+
+// Person is someone the site thanks.
+//
+//crud:generate plural=People
+type Person struct {
+	ID       uint   `gorm:"primaryKey"`
+	SiteID   uint   `gorm:"not null;index"`
+	Name     string `crud:"filter"`
+	Email    string `crud:"format=email"`
+	Score    float64
+	Featured bool `crud:"optional"`
+	// Notes are kept out of the API.
+	Notes string `crud:"-"`
+	Versioned
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"encore.dev/beta/errs"
+	"gorm.io/gorm"
+)
+
+type ResolveSiteParams struct {
+	// Slug selects the site by slug. It takes precedence over Host.
+	Slug string `query:"slug"`
+	// Host selects the site whose domain it is.
+	Host string `query:"host"`
+	// Fallback is the slug of the site served on hosts that are not the
+	// domain of any site.
+	Fallback string `query:"fallback"`
+}
+
+// ResolveSite returns the site a request is for: the one named by Slug,
+// otherwise the one whose domain is Host, otherwise the Fallback. It returns
+// NotFound if there is none.
+//
+//encore:api private method=GET path=/resolve-site
+func ResolveSite(ctx context.Context, p *ResolveSiteParams) (*Site, error) {
+	db, err := database()
+	if err != nil {
+		return nil, err
+	}
+	// Sites are not owned by a site, so they need no scope.
+	db = db.WithContext(ctx)
+	var site Site
+	if p.Slug != "" {
+		err := db.Where("slug = ?", p.Slug).First(&site).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &errs.Error{Code: errs.NotFound, Message: fmt.Sprintf("unknown site %q", p.Slug)}
+		}
+		return &site, err
+	}
+	err = db.Where("domain = ?", p.Host).First(&site).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = db.Where("slug = ?", p.Fallback).First(&site).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &errs.Error{Code: errs.NotFound, Message: fmt.Sprintf("no site is hosted at %q", p.Host)}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &site, nil
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrNoSite is returned by queries on site-owned models whose context does not
+// carry a site.
+var ErrNoSite = errors.New("app: query on site-owned model without a site in context")
+
+type (
+	siteKey        struct{}
+	acrossSitesKey struct{}
+)
+
+// WithSite returns a context whose database calls only see and create records
+// of the given site.
+func WithSite(ctx context.Context, siteID uint) context.Context {
+	return context.WithValue(ctx, siteKey{}, siteID)
+}
+
+// SiteFrom returns the site set by WithSite.
+func SiteFrom(ctx context.Context) (uint, bool) {
+	siteID, ok := ctx.Value(siteKey{}).(uint)
+	return siteID, ok
+}
+
+// AcrossSites returns a context whose reads, updates and deletes are not
+// confined to a site. It is only meant for lookups that determine the site in
+// the first place, such as authenticating an API key.
+func AcrossSites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, acrossSitesKey{}, true)
+}
+
+// tenantScope is a GORM plugin that confines every statement on a model with
+// a SiteID field to the site of its context: reads, updates and deletes get a
+// site_id condition and creates get the site assigned. Statements without a
+// site fail with ErrNoSite, so a missing WithSite never leaks other sites'
+// data. Raw SQL is not scoped.
+type tenantScope struct{}
+
+func (tenantScope) Name() string { return "app:tenant_scope" }
+
+func (tenantScope) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("app:tenant_assign", assignSite); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("app:tenant_scope", scopeToSite); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("app:tenant_scope", scopeToSite); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("app:tenant_scope", scopeToSite); err != nil {
+		return err
+	}
+	return cb.Delete().Before("gorm:delete").Register("app:tenant_scope", scopeToSite)
+}
+
+// siteField returns the SiteID field of the statement's model, or nil if the
+// model is not owned by a site.
+func siteField(db *gorm.DB) *schema.Field {
+	if db.Statement.Schema == nil {
+		return nil
+	}
+	return db.Statement.Schema.LookUpField("SiteID")
+}
+
+func scopeToSite(db *gorm.DB) {
+	field := siteField(db)
+	if field == nil || db.Statement.Context.Value(acrossSitesKey{}) != nil {
+		return
+	}
+	siteID, ok := SiteFrom(db.Statement.Context)
+	if !ok {
+		db.AddError(ErrNoSite)
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: siteID},
+	}})
+}
+
+func assignSite(db *gorm.DB) {
+	field := siteField(db)
+	if field == nil {
+		return
+	}
+	siteID, ok := SiteFrom(db.Statement.Context)
+	if !ok {
+		db.AddError(ErrNoSite)
+		return
+	}
+	ctx, rv := db.Statement.Context, db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := field.Set(ctx, reflect.Indirect(rv.Index(i)), siteID); err != nil {
+				db.AddError(err)
+			}
+		}
+	case reflect.Struct:
+		if err := field.Set(ctx, rv, siteID); err != nil {
+			db.AddError(err)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+//go:build encore_app
+
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"encore.dev/beta/errs"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRun returns a handle with the tenant scope that builds statements
+// without running them.
+func dryRun(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(tenantScope{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestScopeToSite(t *testing.T) {
+	db := dryRun(t).WithContext(WithSite(context.Background(), 7))
+	tests := map[string]*gorm.DB{
+		"find":   db.Where("name = ?", "Ada").Find(&[]User{}),
+		"first":  db.First(&Project{}, 3),
+		"update": db.Model(&Blog{}).Where("id = ?", 3).Update("title", "x"),
+		"delete": db.Delete(&Resume{}, 3),
+	}
+	for name, stmt := range tests {
+		if stmt.Error != nil {
+			t.Errorf("%s: %v", name, stmt.Error)
+			continue
+		}
+		sql := stmt.Statement.SQL.String()
+		if !strings.Contains(sql, `."site_id" = $`) {
+			t.Errorf("%s: %s has no site condition", name, sql)
+		}
+		if !hasVar(stmt.Statement.Vars, uint(7)) {
+			t.Errorf("%s: vars %v lack the site", name, stmt.Statement.Vars)
+		}
+	}
+}
+
+func hasVar(vars []any, v any) bool {
+	for _, x := range vars {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func TestScopeToSiteWithoutSite(t *testing.T) {
+	db := dryRun(t).WithContext(context.Background())
+	tests := map[string]*gorm.DB{
+		"find":   db.Find(&[]User{}),
+		"first":  db.First(&Project{}, 3),
+		"update": db.Model(&Blog{}).Where("id = ?", 3).Update("title", "x"),
+		"delete": db.Delete(&Resume{}, 3),
+		"create": db.Create(&Blog{Title: "x"}),
+	}
+	for name, stmt := range tests {
+		if !errors.Is(stmt.Error, ErrNoSite) {
+			t.Errorf("%s: err = %v, want ErrNoSite", name, stmt.Error)
+		}
+	}
+}
+
+func TestScopeToSiteAcrossSites(t *testing.T) {
+	db := dryRun(t).WithContext(AcrossSites(context.Background()))
+	stmt := db.Find(&[]ApiKey{})
+	if stmt.Error != nil {
+		t.Fatal(stmt.Error)
+	}
+	if sql := stmt.Statement.SQL.String(); strings.Contains(sql, "site_id") {
+		t.Errorf("%s is confined to a site", sql)
+	}
+
+	// Models without a site need none.
+	stmt = dryRun(t).WithContext(context.Background()).Where("slug = ?", "a").First(&Site{})
+	if stmt.Error != nil {
+		t.Fatal(stmt.Error)
+	}
+}
+
+func TestAssignSite(t *testing.T) {
+	db := dryRun(t).WithContext(WithSite(context.Background(), 7))
+	blog := &Blog{Title: "x", SiteID: 8}
+	if err := db.Create(blog).Error; err != nil {
+		t.Fatal(err)
+	}
+	if blog.SiteID != 7 {
+		t.Errorf("created blog of site %d, want 7", blog.SiteID)
+	}
+	users := []*User{{Name: "Ada"}, {Name: "Grace", SiteID: 8}}
+	if err := db.Create(users).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, u := range users {
+		if u.SiteID != 7 {
+			t.Errorf("created %s in site %d, want 7", u.Name, u.SiteID)
+		}
+	}
+}
+
+// siteRecords are one record of each site-owned entity.
+type siteRecords struct {
+	user    *User
+	project *Project
+	blog    *Blog
+	resume  *Resume
+}
+
+func createRecords(t *testing.T, site *Site) siteRecords {
+	t.Helper()
+	ctx := context.Background()
+	var r siteRecords
+	var err error
+	if r.user, err = CreateUser(ctx, site.ID, &CreateUserParams{Name: "Ada", Email: "ada@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if r.project, err = CreateProject(ctx, site.ID, &CreateProjectParams{Title: "Engine", UserID: r.user.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if r.blog, err = CreateBlog(ctx, site.ID, &CreateBlogParams{Title: "Notes", Content: "..."}); err != nil {
+		t.Fatal(err)
+	}
+	if r.resume, err = CreateResume(ctx, site.ID, &CreateResumeParams{Title: "Analyst", Category: "work"}); err != nil {
+		t.Fatal(err)
+	}
+	for name, siteID := range map[string]uint{"user": r.user.SiteID, "project": r.project.SiteID, "blog": r.blog.SiteID, "resume": r.resume.SiteID} {
+		if siteID != site.ID {
+			t.Errorf("%s created in site %d, want %d", name, siteID, site.ID)
+		}
+	}
+	return r
+}
+
+func TestSitesAreIsolated(t *testing.T) {
+	ctx := context.Background()
+	a, b := createSite(t, "tenant-a"), createSite(t, "tenant-b")
+	// The same email may be used on both sites.
+	createRecords(t, a)
+	theirs := createRecords(t, b)
+
+	notFound := func(what string, err error) {
+		t.Helper()
+		if errs.Code(err) != errs.NotFound {
+			t.Errorf("%s of site b from site a: err = %v, want NotFound", what, err)
+		}
+	}
+
+	_, err := GetUser(ctx, a.ID, theirs.user.ID)
+	notFound("reading the user", err)
+	_, err = GetProject(ctx, a.ID, theirs.project.ID, &GetProjectParams{IncludeHidden: true})
+	notFound("reading the project", err)
+	_, err = GetBlog(ctx, a.ID, theirs.blog.ID, &GetBlogParams{IncludeDrafts: true})
+	notFound("reading the blog", err)
+	_, err = GetResume(ctx, a.ID, theirs.resume.ID)
+	notFound("reading the resume", err)
+
+	users, err := ListUsers(ctx, a.ID, &ListUsersParams{IDs: []uint{theirs.user.ID}})
+	if err != nil || len(users.Users) != 0 {
+		t.Errorf("listing the user of site b from site a: %v, %v", users, err)
+	}
+	projects, err := ListProjects(ctx, a.ID, &ListProjectsParams{IDs: []uint{theirs.project.ID}, IncludeHidden: true})
+	if err != nil || len(projects.Projects) != 0 {
+		t.Errorf("listing the project of site b from site a: %v, %v", projects, err)
+	}
+	blogs, err := ListBlogs(ctx, a.ID, &ListBlogsParams{IDs: []uint{theirs.blog.ID}, IncludeDrafts: true})
+	if err != nil || len(blogs.Blogs) != 0 {
+		t.Errorf("listing the blog of site b from site a: %v, %v", blogs, err)
+	}
+	resumes, err := ListResumes(ctx, a.ID, &ListResumesParams{IDs: []uint{theirs.resume.ID}})
+	if err != nil || len(resumes.Resumes) != 0 {
+		t.Errorf("listing the resume of site b from site a: %v, %v", resumes, err)
+	}
+
+	title := "taken over"
+	_, err = UpdateUser(ctx, a.ID, theirs.user.ID, &UpdateUserParams{Name: &title})
+	notFound("updating the user", err)
+	_, err = UpdateProject(ctx, a.ID, theirs.project.ID, &UpdateProjectParams{Title: &title})
+	notFound("updating the project", err)
+	_, err = UpdateBlog(ctx, a.ID, theirs.blog.ID, &UpdateBlogParams{Title: &title})
+	notFound("updating the blog", err)
+	_, err = UpdateResume(ctx, a.ID, theirs.resume.ID, &UpdateResumeParams{Title: &title})
+	notFound("updating the resume", err)
+
+	_, err = DeleteProject(ctx, a.ID, theirs.project.ID)
+	notFound("deleting the project", err)
+	_, err = DeleteBlog(ctx, a.ID, theirs.blog.ID)
+	notFound("deleting the blog", err)
+	_, err = DeleteResume(ctx, a.ID, theirs.resume.ID)
+	notFound("deleting the resume", err)
+	_, err = DeleteUser(ctx, a.ID, theirs.user.ID, &DeleteUserParams{Strategy: DeleteCascade})
+	notFound("deleting the user", err)
+
+	// Nor can a record of site a refer to one of site b.
+	_, err = CreateProject(ctx, a.ID, &CreateProjectParams{Title: "Stolen", UserID: theirs.user.ID})
+	if errs.Code(err) != errs.InvalidArgument {
+		t.Errorf("creating a project of a user of site b in site a: err = %v, want InvalidArgument", err)
+	}
+
+	// Site b still has everything, unchanged.
+	user, err := GetUser(ctx, b.ID, theirs.user.ID)
+	if err != nil || user.Name != theirs.user.Name {
+		t.Errorf("user of site b: %v, %v", user, err)
+	}
+	project, err := GetProject(ctx, b.ID, theirs.project.ID, &GetProjectParams{})
+	if err != nil || project.Title != theirs.project.Title {
+		t.Errorf("project of site b: %v, %v", project, err)
+	}
+	blog, err := GetBlog(ctx, b.ID, theirs.blog.ID, &GetBlogParams{})
+	if err != nil || blog.Title != theirs.blog.Title {
+		t.Errorf("blog of site b: %v, %v", blog, err)
+	}
+	resume, err := GetResume(ctx, b.ID, theirs.resume.ID)
+	if err != nil || resume.Title != theirs.resume.Title {
+		t.Errorf("resume of site b: %v, %v", resume, err)
+	}
+}
+
+func TestCountWithoutSite(t *testing.T) {
+	db, err := database()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int64
+	if err := db.WithContext(context.Background()).Model(&Blog{}).Count(&n).Error; !errors.Is(err, ErrNoSite) {
+		t.Fatalf("count without a site: err = %v, want ErrNoSite", err)
+	}
+}
+
+func TestResolveSite(t *testing.T) {
+	ctx := context.Background()
+	site := createSite(t, "resolve")
+	domain := site.Slug + ".example.com"
+	db, err := database()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.WithContext(ctx).Model(site).Update("domain", domain).Error; err != nil {
+		t.Fatal(err)
+	}
+	fallback := createSite(t, "fallback")
+
+	tests := []struct {
+		name string
+		p    ResolveSiteParams
+		want uint
+	}{
+		{"slug", ResolveSiteParams{Slug: site.Slug, Host: "elsewhere.example.com", Fallback: fallback.Slug}, site.ID},
+		{"host", ResolveSiteParams{Host: domain, Fallback: fallback.Slug}, site.ID},
+		{"fallback", ResolveSiteParams{Host: "unknown.example.com", Fallback: fallback.Slug}, fallback.ID},
+	}
+	for _, tt := range tests {
+		got, err := ResolveSite(ctx, &tt.p)
+		if err != nil || got.ID != tt.want {
+			t.Errorf("%s: site %v, %v; want %d", tt.name, got, err, tt.want)
+		}
+	}
+	if _, err := ResolveSite(ctx, &ResolveSiteParams{Slug: "no-such-site", Fallback: fallback.Slug}); errs.Code(err) != errs.NotFound {
+		t.Errorf("unknown slug: err = %v, want NotFound without falling back", err)
+	}
+	if _, err := ResolveSite(ctx, &ResolveSiteParams{Host: "unknown.example.com", Fallback: "no-such-site"}); errs.Code(err) != errs.NotFound {
+		t.Errorf("no fallback: err = %v, want NotFound", err)
+	}
+}
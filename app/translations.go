@@ -0,0 +1,160 @@
+package app
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// listTranslations lists the translations of the records of a site it
+// references through column with the given primary keys, by record and
+// locale. With locales set, only translations into those are listed.
+func listTranslations[T any](ctx context.Context, siteID uint, column string, ids []uint, locales []string) ([]*T, error) {
+	db, err := session(ctx, siteID)
+	if err != nil {
+		return nil, err
+	}
+	var f filter
+	if len(ids) > 0 {
+		f.add(column+" IN ?", ids)
+	}
+	if len(locales) > 0 {
+		f.add("locale IN ?", locales)
+	}
+	translations := []*T{}
+	if err := f.apply(db).Order(column + ", locale").Find(&translations).Error; err != nil {
+		return nil, err
+	}
+	return translations, nil
+}
+
+// upsertTranslation stores the translation t of the record id of a site,
+// which it references through column, replacing the one in the same locale.
+// It returns NotFound if the record does not exist.
+func upsertTranslation[T any](ctx context.Context, siteID uint, record any, id uint, column, body string, t *T) (*T, error) {
+	err := transaction(ctx, siteID, func(tx *gorm.DB) error {
+		if err := tx.Select("id").First(record, id).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: column}, {Name: "locale"}},
+			DoUpdates: clause.AssignmentColumns([]string{"title", body, "updated_at"}),
+		}).Create(t).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+type ListBlogTranslationsParams struct {
+	// BlogIDs limits the list to the translations of these posts.
+	BlogIDs []uint `query:"blogId"`
+	// Locales limits the list to these locales.
+	Locales []string `query:"locale"`
+}
+
+type ListBlogTranslationsResponse struct {
+	Translations []*BlogTranslation `json:"translations"`
+}
+
+// ListBlogTranslations lists the translations of the blog posts of a site, by
+// post and locale.
+//
+//encore:api private method=GET path=/sites/:siteID/blog-translations
+func ListBlogTranslations(ctx context.Context, siteID uint, p *ListBlogTranslationsParams) (*ListBlogTranslationsResponse, error) {
+	translations, err := listTranslations[BlogTranslation](ctx, siteID, "blog_id", p.BlogIDs, p.Locales)
+	if err != nil {
+		return nil, err
+	}
+	return &ListBlogTranslationsResponse{Translations: translations}, nil
+}
+
+type UpsertBlogTranslationParams struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+// UpsertBlogTranslation stores the translation of a blog post of a site into
+// a locale, replacing the previous one.
+//
+//encore:api private method=PUT path=/sites/:siteID/blogs/:id/translations/:locale
+func UpsertBlogTranslation(ctx context.Context, siteID, id uint, locale string, p *UpsertBlogTranslationParams) (*BlogTranslation, error) {
+	t := &BlogTranslation{BlogID: id, Locale: locale, Title: p.Title, Content: p.Content}
+	return upsertTranslation(ctx, siteID, &Blog{}, id, "blog_id", "content", t)
+}
+
+type ListProjectTranslationsParams struct {
+	// ProjectIDs limits the list to the translations of these projects.
+	ProjectIDs []uint `query:"projectId"`
+	// Locales limits the list to these locales.
+	Locales []string `query:"locale"`
+}
+
+type ListProjectTranslationsResponse struct {
+	Translations []*ProjectTranslation `json:"translations"`
+}
+
+// ListProjectTranslations lists the translations of the projects of a site,
+// by project and locale.
+//
+//encore:api private method=GET path=/sites/:siteID/project-translations
+func ListProjectTranslations(ctx context.Context, siteID uint, p *ListProjectTranslationsParams) (*ListProjectTranslationsResponse, error) {
+	translations, err := listTranslations[ProjectTranslation](ctx, siteID, "project_id", p.ProjectIDs, p.Locales)
+	if err != nil {
+		return nil, err
+	}
+	return &ListProjectTranslationsResponse{Translations: translations}, nil
+}
+
+type UpsertProjectTranslationParams struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// UpsertProjectTranslation stores the translation of a project of a site into
+// a locale, replacing the previous one.
+//
+//encore:api private method=PUT path=/sites/:siteID/projects/:id/translations/:locale
+func UpsertProjectTranslation(ctx context.Context, siteID, id uint, locale string, p *UpsertProjectTranslationParams) (*ProjectTranslation, error) {
+	t := &ProjectTranslation{ProjectID: id, Locale: locale, Title: p.Title, Description: p.Description}
+	return upsertTranslation(ctx, siteID, &Project{}, id, "project_id", "description", t)
+}
+
+type ListResumeTranslationsParams struct {
+	// ResumeIDs limits the list to the translations of these sections.
+	ResumeIDs []uint `query:"resumeId"`
+	// Locales limits the list to these locales.
+	Locales []string `query:"locale"`
+}
+
+type ListResumeTranslationsResponse struct {
+	Translations []*ResumeTranslation `json:"translations"`
+}
+
+// ListResumeTranslations lists the translations of the resume sections of a
+// site, by section and locale.
+//
+//encore:api private method=GET path=/sites/:siteID/resume-translations
+func ListResumeTranslations(ctx context.Context, siteID uint, p *ListResumeTranslationsParams) (*ListResumeTranslationsResponse, error) {
+	translations, err := listTranslations[ResumeTranslation](ctx, siteID, "resume_id", p.ResumeIDs, p.Locales)
+	if err != nil {
+		return nil, err
+	}
+	return &ListResumeTranslationsResponse{Translations: translations}, nil
+}
+
+type UpsertResumeTranslationParams struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// UpsertResumeTranslation stores the translation of a resume section of a
+// site into a locale, replacing the previous one.
+//
+//encore:api private method=PUT path=/sites/:siteID/resumes/:id/translations/:locale
+func UpsertResumeTranslation(ctx context.Context, siteID, id uint, locale string, p *UpsertResumeTranslationParams) (*ResumeTranslation, error) {
+	t := &ResumeTranslation{ResumeID: id, Locale: locale, Title: p.Title, Description: p.Description}
+	return upsertTranslation(ctx, siteID, &Resume{}, id, "resume_id", "description", t)
+}
@@ -0,0 +1,348 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"encore.dev/beta/errs"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ListUsersParams struct {
+	// IDs limits the list to these users.
+	IDs          []uint `query:"id"`
+	NameContains string `query:"nameContains"`
+	// CreatedBefore and CreatedAfter are ignored when zero.
+	CreatedBefore time.Time `query:"createdBefore"`
+	CreatedAfter  time.Time `query:"createdAfter"`
+}
+
+type ListUsersResponse struct {
+	Users []*User `json:"users"`
+}
+
+// ListUsers lists the users of a site that match every given condition, by ID.
+//
+//encore:api private method=GET path=/sites/:siteID/users
+func ListUsers(ctx context.Context, siteID uint, p *ListUsersParams) (*ListUsersResponse, error) {
+	db, err := session(ctx, siteID)
+	if err != nil {
+		return nil, err
+	}
+	var f filter
+	if len(p.IDs) > 0 {
+		f.add("id IN ?", p.IDs)
+	}
+	f.contains("name", p.NameContains)
+	if !p.CreatedBefore.IsZero() {
+		f.add("created_at < ?", p.CreatedBefore)
+	}
+	if !p.CreatedAfter.IsZero() {
+		f.add("created_at > ?", p.CreatedAfter)
+	}
+	users := []*User{}
+	if err := f.apply(db).Order("id").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return &ListUsersResponse{Users: users}, nil
+}
+
+// GetUser returns a user, or NotFound.
+//
+//encore:api private method=GET path=/sites/:siteID/users/:id
+func GetUser(ctx context.Context, siteID, id uint) (*User, error) {
+	db, err := session(ctx, siteID)
+	if err != nil {
+		return nil, err
+	}
+	var user User
+	if err := db.First(&user, id).Error; err != nil {
+		return nil, DBError(err)
+	}
+	return &user, nil
+}
+
+type CreateUserParams struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// CreateUser creates a user and publishes UserCreated.
+//
+//encore:api private method=POST path=/sites/:siteID/users
+func CreateUser(ctx context.Context, siteID uint, p *CreateUserParams) (user *User, err error) {
+	err = transaction(ctx, siteID, func(tx *gorm.DB) error {
+		user, err = createUser(tx, p)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func createUser(tx *gorm.DB, p *CreateUserParams) (*User, error) {
+	user := &User{
+		Name:      p.Name,
+		Email:     p.Email,
+		CreatedAt: time.Now(),
+	}
+	if err := tx.Create(user).Error; err != nil {
+		return nil, err
+	}
+	return user, userEvent(tx, UserCreated, user, nil)
+}
+
+// UpdateUserParams changes the fields that are set.
+type UpdateUserParams struct {
+	Name  *string `json:"name,omitempty"`
+	Email *string `json:"email,omitempty"`
+	// ExpectedVersion rejects the update with Aborted unless the user is
+	// still at this version.
+	ExpectedVersion *int `json:"expectedVersion,omitempty"`
+}
+
+// UpdateUser changes a user and publishes UserUpdated.
+//
+//encore:api private method=PATCH path=/sites/:siteID/users/:id
+func UpdateUser(ctx context.Context, siteID, id uint, p *UpdateUserParams) (user *User, err error) {
+	err = transaction(ctx, siteID, func(tx *gorm.DB) error {
+		user, err = updateUser(tx, id, p)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func updateUser(tx *gorm.DB, id uint, p *UpdateUserParams) (*User, error) {
+	var user User
+	if err := tx.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	if err := checkVersion(&user, p.ExpectedVersion); err != nil {
+		return nil, err
+	}
+	if p.Name != nil {
+		user.Name = *p.Name
+	}
+	if p.Email != nil {
+		user.Email = *p.Email
+	}
+	if err := saveVersioned(tx, &user, id); err != nil {
+		return nil, err
+	}
+	return &user, userEvent(tx, UserUpdated, &user, nil)
+}
+
+// DeleteStrategy decides what happens to the projects of a deleted user.
+type DeleteStrategy string
+
+const (
+	// DeleteRestrict refuses to delete a user that still owns projects.
+	DeleteRestrict DeleteStrategy = "RESTRICT"
+	// DeleteCascade deletes the projects together with the user.
+	DeleteCascade DeleteStrategy = "CASCADE"
+	// DeleteReassign moves the projects to another user.
+	DeleteReassign DeleteStrategy = "REASSIGN"
+)
+
+type DeleteUserParams struct {
+	// Strategy defaults to DeleteRestrict.
+	Strategy DeleteStrategy `json:"strategy,omitempty" query:"strategy"`
+	// ToUserID receives the projects with DeleteReassign.
+	ToUserID uint `json:"toUserId,omitempty" query:"toUserId"`
+}
+
+type DeleteUserResponse struct {
+	// User is the deleted user.
+	User     *User         `json:"user"`
+	Deletion *UserDeletion `json:"deletion"`
+}
+
+// DeleteUser deletes a user and handles its projects according to the
+// strategy. It publishes UserDeleted, and ProjectDeleted or ProjectUpdated
+// for each project.
+//
+//encore:api private method=DELETE path=/sites/:siteID/users/:id
+func DeleteUser(ctx context.Context, siteID, id uint, p *DeleteUserParams) (resp *DeleteUserResponse, err error) {
+	err = transaction(ctx, siteID, func(tx *gorm.DB) error {
+		resp, err = deleteUser(tx, id, p)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func deleteUser(tx *gorm.DB, id uint, p *DeleteUserParams) (*DeleteUserResponse, error) {
+	strategy := p.Strategy
+	if strategy == "" {
+		strategy = DeleteRestrict
+	}
+	switch strategy {
+	case DeleteRestrict, DeleteCascade:
+	case DeleteReassign:
+		if p.ToUserID == 0 {
+			return nil, invalidArgument("toUserId", "toUserId is required by the REASSIGN strategy")
+		}
+		if p.ToUserID == id {
+			return nil, invalidArgument("toUserId", "toUserId must not be the deleted user")
+		}
+	default:
+		return nil, invalidArgument("strategy", fmt.Sprintf("unknown strategy %q", strategy))
+	}
+
+	// Locking the user keeps projects from being added to it meanwhile.
+	var user User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	var projects []Project
+	if err := tx.Where("user_id = ?", id).Order("id").Find(&projects).Error; err != nil {
+		return nil, err
+	}
+	deletion := &UserDeletion{Strategy: string(strategy), ProjectIDs: make([]string, len(projects))}
+	owned := make([]uint, len(projects))
+	for i, project := range projects {
+		owned[i] = project.ID
+		deletion.ProjectIDs[i] = GlobalID(TypeProject, project.ID)
+	}
+	switch strategy {
+	case DeleteCascade:
+		if len(owned) > 0 {
+			if err := tx.Delete(&Project{}, owned).Error; err != nil {
+				return nil, err
+			}
+		}
+		for i := range projects {
+			if err := projectEvent(tx, ProjectDeleted, &projects[i]); err != nil {
+				return nil, err
+			}
+		}
+	case DeleteReassign:
+		if err := requireUser(tx, "toUserId", p.ToUserID); err != nil {
+			return nil, err
+		}
+		if len(owned) > 0 {
+			if err := tx.Model(&Project{}).Where("id IN ?", owned).Update("user_id", p.ToUserID).Error; err != nil {
+				return nil, err
+			}
+			if err := tx.Order("id").Find(&projects, owned).Error; err != nil {
+				return nil, err
+			}
+		}
+		for i := range projects {
+			if err := projectEvent(tx, ProjectUpdated, &projects[i]); err != nil {
+				return nil, err
+			}
+		}
+		to := GlobalID(TypeUser, p.ToUserID)
+		deletion.ReassignedTo = &to
+	default:
+		if n := len(owned); n > 0 {
+			return nil, &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: fmt.Sprintf("user still owns %d projects; delete them with CASCADE or move them with REASSIGN", n),
+				Details: FieldDetails{Field: "strategy"},
+			}
+		}
+	}
+	if err := tx.Delete(&user).Error; err != nil {
+		return nil, stillReferenced(err)
+	}
+	if err := userEvent(tx, UserDeleted, &user, deletion); err != nil {
+		return nil, err
+	}
+	return &DeleteUserResponse{User: &user, Deletion: deletion}, nil
+}
+
+// requireUser returns an InvalidArgument error on field unless the user with
+// the given primary key exists.
+func requireUser(tx *gorm.DB, field string, userID uint) error {
+	var n int64
+	if err := tx.Model(&User{}).Where("id = ?", userID).Count(&n).Error; err != nil {
+		return err
+	}
+	if n == 0 {
+		return invalidArgument(field, field+" does not refer to an existing user")
+	}
+	return nil
+}
+
+// UserOp is one operation of a batch. Exactly one of its fields is set.
+type UserOp struct {
+	Create *CreateUserParams `json:"create,omitempty"`
+	Update *UpdateUserOp     `json:"update,omitempty"`
+	Delete *DeleteUserOp     `json:"delete,omitempty"`
+}
+
+type UpdateUserOp struct {
+	ID     uint             `json:"id"`
+	Params UpdateUserParams `json:"params"`
+}
+
+type DeleteUserOp struct {
+	ID     uint             `json:"id"`
+	Params DeleteUserParams `json:"params"`
+}
+
+type BatchUsersParams struct {
+	Ops []*UserOp `json:"ops"`
+	// Atomic rolls back every operation if any of them fails.
+	Atomic bool `json:"atomic"`
+}
+
+type BatchUsersResponse struct {
+	// Committed reports whether the operations without an error took
+	// effect.
+	Committed bool `json:"committed"`
+	// Results holds the outcome of each operation, in order.
+	Results []*UserResult `json:"results"`
+}
+
+type UserResult struct {
+	// User is the created, updated or deleted user.
+	User *User `json:"user,omitempty"`
+	// Deletion is set for deletes.
+	Deletion *UserDeletion `json:"deletion,omitempty"`
+	Error    *ItemError    `json:"error,omitempty"`
+}
+
+// BatchUsers runs the operations in a single transaction, see runBatch.
+//
+//encore:api private method=POST path=/sites/:siteID/batch/users
+func BatchUsers(ctx context.Context, siteID uint, p *BatchUsersParams) (*BatchUsersResponse, error) {
+	results := make([]*UserResult, len(p.Ops))
+	failures, committed, err := runBatch(ctx, siteID, len(p.Ops), p.Atomic, func(tx *gorm.DB, i int) (err error) {
+		res := &UserResult{}
+		switch op := p.Ops[i]; {
+		case op.Create != nil:
+			res.User, err = createUser(tx, op.Create)
+		case op.Update != nil:
+			res.User, err = updateUser(tx, op.Update.ID, &op.Update.Params)
+		case op.Delete != nil:
+			var resp *DeleteUserResponse
+			if resp, err = deleteUser(tx, op.Delete.ID, &op.Delete.Params); err == nil {
+				res.User, res.Deletion = resp.User, resp.Deletion
+			}
+		default:
+			err = errEmptyOp
+		}
+		results[i] = res
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		if failures[i] != nil || !committed {
+			results[i] = &UserResult{Error: failures[i]}
+		}
+	}
+	return &BatchUsersResponse{Committed: committed, Results: results}, nil
+}
@@ -0,0 +1,44 @@
+package app
+
+import "gorm.io/gorm"
+
+type versioned interface {
+	VersionInfo() *Versioned
+}
+
+// checkVersion rejects an update of record, as read from the database, when
+// the caller based it on another version. It must be called before any change
+// is applied to record.
+func checkVersion(record versioned, expected *int) error {
+	if v := record.VersionInfo().Version; expected != nil && *expected != v {
+		return versionConflict(v)
+	}
+	return nil
+}
+
+// saveVersioned writes all fields of record, which was read by its primary key
+// id, and increments its version. The write only succeeds if the row is still
+// at the version that was read, so concurrent updates never overwrite each
+// other silently.
+func saveVersioned[T any, P interface {
+	*T
+	versioned
+}](db *gorm.DB, record P, id uint) error {
+	v := record.VersionInfo()
+	read := v.Version
+	v.Version++
+	res := db.Model(record).Where("version = ?", read).Select("*").Updates(record)
+	if res.Error != nil {
+		v.Version = read
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		v.Version = read
+		current := P(new(T))
+		if err := db.First(current, id).Error; err != nil {
+			return err
+		}
+		return versionConflict(current.VersionInfo().Version)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package app
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// maxViewStatsDays is the longest range ViewStats covers.
+	maxViewStatsDays = 366
+	// maxReferrers is how many referrers ViewStats lists.
+	maxReferrers = 20
+)
+
+// viewedType checks the type of a record whose views are counted.
+func viewedType(typ string) error {
+	if typ != TypeBlog && typ != TypeProject {
+		return invalidArgument("type", "views are only counted for "+TypeBlog+" and "+TypeProject)
+	}
+	return nil
+}
+
+// utcDay returns midnight UTC of the day t falls on in UTC.
+func utcDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+type RecordViewParams struct {
+	// Type and ID name the viewed record.
+	Type string `json:"type"`
+	ID   uint   `json:"id"`
+	// Referrer is the host the visitor came from, empty for direct visits.
+	Referrer string `json:"referrer"`
+	// Day is the UTC day of the view. It defaults to today.
+	Day time.Time `json:"day"`
+}
+
+// RecordView counts a view of a record of a site.
+//
+//encore:api private method=POST path=/sites/:siteID/views
+func RecordView(ctx context.Context, siteID uint, p *RecordViewParams) error {
+	if err := viewedType(p.Type); err != nil {
+		return err
+	}
+	day := p.Day
+	if day.IsZero() {
+		day = time.Now()
+	}
+	return transaction(ctx, siteID, func(tx *gorm.DB) error {
+		return tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "site_id"}, {Name: "entity_type"}, {Name: "entity_id"}, {Name: "day"}, {Name: "referrer"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"views": gorm.Expr("view_rollups.views + 1"),
+			}),
+		}).Create(&ViewRollup{EntityType: p.Type, EntityID: p.ID, Day: utcDay(day), Referrer: p.Referrer, Views: 1}).Error
+	})
+}
+
+type ViewCountsParams struct {
+	Type string `query:"type"`
+	IDs  []uint `query:"id"`
+}
+
+type ViewCountsResponse struct {
+	// Counts holds the views of every record, in the order of the IDs.
+	Counts []int64 `json:"counts"`
+}
+
+// ViewCounts returns all views of records of a site.
+//
+//encore:api private method=GET path=/sites/:siteID/view-counts
+func ViewCounts(ctx context.Context, siteID uint, p *ViewCountsParams) (*ViewCountsResponse, error) {
+	if err := viewedType(p.Type); err != nil {
+		return nil, err
+	}
+	db, err := session(ctx, siteID)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		EntityID uint
+		Views    int64
+	}
+	err = db.Model(&ViewRollup{}).
+		Select("entity_id, SUM(views) AS views").
+		Where("entity_type = ? AND entity_id IN ?", p.Type, p.IDs).
+		Group("entity_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	views := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		views[row.EntityID] = row.Views
+	}
+	counts := make([]int64, len(p.IDs))
+	for i, id := range p.IDs {
+		counts[i] = views[id]
+	}
+	return &ViewCountsResponse{Counts: counts}, nil
+}
+
+type ViewStatsParams struct {
+	Type string `query:"type"`
+	ID   uint   `query:"id"`
+	// From and To are the first and last day covered, in UTC.
+	From time.Time `query:"from"`
+	To   time.Time `query:"to"`
+}
+
+type ViewStatsResponse struct {
+	// Days holds the views of every day from From to To, in order.
+	Days []*DailyViews `json:"days"`
+	// Referrers are the hosts most views came from, most first.
+	Referrers []*ReferrerViews `json:"referrers"`
+}
+
+type DailyViews struct {
+	Day   time.Time `json:"day"`
+	Views int64     `json:"views"`
+}
+
+type ReferrerViews struct {
+	// Referrer is empty for direct visits.
+	Referrer string `json:"referrer"`
+	Views    int64  `json:"views"`
+}
+
+// ViewStats returns the views of a record of a site per day and per referrer.
+//
+//encore:api private method=GET path=/sites/:siteID/view-stats
+func ViewStats(ctx context.Context, siteID uint, p *ViewStatsParams) (*ViewStatsResponse, error) {
+	if err := viewedType(p.Type); err != nil {
+		return nil, err
+	}
+	from, to := utcDay(p.From), utcDay(p.To)
+	if to.Before(from) {
+		return nil, invalidArgument("to", "to must not be before from")
+	}
+	if to.Sub(from) >= maxViewStatsDays*24*time.Hour {
+		return nil, invalidArgument("to", "view stats cover at most "+strconv.Itoa(maxViewStatsDays)+" days")
+	}
+	db, err := session(ctx, siteID)
+	if err != nil {
+		return nil, err
+	}
+	q := db.Model(&ViewRollup{}).
+		Where("entity_type = ? AND entity_id = ? AND day BETWEEN ? AND ?", p.Type, p.ID, from, to)
+
+	var days []struct {
+		Day   time.Time
+		Views int64
+	}
+	if err := q.Session(&gorm.Session{}).Select("day, SUM(views) AS views").Group("day").Scan(&days).Error; err != nil {
+		return nil, err
+	}
+	perDay := make(map[time.Time]int64, len(days))
+	for _, d := range days {
+		perDay[utcDay(d.Day)] = d.Views
+	}
+	stats := &ViewStatsResponse{Days: []*DailyViews{}, Referrers: []*ReferrerViews{}}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		stats.Days = append(stats.Days, &DailyViews{Day: day, Views: perDay[day]})
+	}
+
+	err = q.Session(&gorm.Session{}).
+		Select("referrer, SUM(views) AS views").
+		Group("referrer").
+		Order("views DESC, referrer").
+		Limit(maxReferrers).
+		Scan(&stats.Referrers).Error
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+type PopularBlogsParams struct {
+	// Since is the first day whose views count.
+	Since time.Time `query:"since"`
+	Limit int       `query:"limit"`
+	// IncludeDrafts also lists drafts.
+	IncludeDrafts bool `query:"includeDrafts"`
+}
+
+// PopularBlogs lists the blog posts of a site with the most views since
+// Since, most first.
+//
+//encore:api private method=GET path=/sites/:siteID/popular-blogs
+func PopularBlogs(ctx context.Context, siteID uint, p *PopularBlogsParams) (*ListBlogsResponse, error) {
+	db, err := session(ctx, siteID)
+	if err != nil {
+		return nil, err
+	}
+	// Subqueries are not scoped to the site, see tenantScope.
+	views := db.Model(&ViewRollup{}).
+		Select("entity_id, SUM(views) AS views").
+		Where("site_id = ? AND entity_type = ? AND day >= ?", siteID, TypeBlog, utcDay(p.Since)).
+		Group("entity_id")
+	q := db.Joins("JOIN (?) AS v ON v.entity_id = blogs.id", views).
+		Order("v.views DESC, blogs.id").
+		Limit(p.Limit)
+	if !p.IncludeDrafts {
+		q = q.Where("blogs.draft = ?", false)
+	}
+	blogs := []*Blog{}
+	if err := q.Find(&blogs).Error; err != nil {
+		return nil, err
+	}
+	return &ListBlogsResponse{Blogs: blogs}, nil
+}
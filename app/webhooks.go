@@ -0,0 +1,480 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"encore.dev/pubsub"
+	"encore.dev/rlog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// webhookBatch is the most deliveries claimed at once.
+	webhookBatch = 20
+	// webhookBackoff is the wait before the second attempt of a delivery.
+	// It doubles with every attempt, up to webhookMaxBackoff.
+	webhookBackoff    = 30 * time.Second
+	webhookMaxBackoff = 6 * time.Hour
+)
+
+// Every domain event is offered to the webhooks of its site.
+var (
+	_ = pubsub.NewSubscription(UserEvents, "user-events-webhooks", pubsub.SubscriptionConfig[*UserEvent]{
+		Handler: pubsub.MethodHandler((*Service).QueueUserWebhooks),
+	})
+	_ = pubsub.NewSubscription(ProjectEvents, "project-events-webhooks", pubsub.SubscriptionConfig[*ProjectEvent]{
+		Handler: pubsub.MethodHandler((*Service).QueueProjectWebhooks),
+	})
+	_ = pubsub.NewSubscription(BlogEvents, "blog-events-webhooks", pubsub.SubscriptionConfig[*BlogEvent]{
+		Handler: pubsub.MethodHandler((*Service).QueueBlogWebhooks),
+	})
+	_ = pubsub.NewSubscription(ResumeEvents, "resume-events-webhooks", pubsub.SubscriptionConfig[*ResumeEvent]{
+		Handler: pubsub.MethodHandler((*Service).QueueResumeWebhooks),
+	})
+	_ = pubsub.NewSubscription(ContentEvents, "content-events-webhooks", pubsub.SubscriptionConfig[*ContentEvent]{
+		Handler: pubsub.MethodHandler((*Service).QueueContentWebhooks),
+	})
+)
+
+func (s *Service) QueueUserWebhooks(ctx context.Context, e *UserEvent) error {
+	return s.queueWebhooks(ctx, e.Meta, e)
+}
+
+func (s *Service) QueueProjectWebhooks(ctx context.Context, e *ProjectEvent) error {
+	return s.queueWebhooks(ctx, e.Meta, e)
+}
+
+func (s *Service) QueueBlogWebhooks(ctx context.Context, e *BlogEvent) error {
+	return s.queueWebhooks(ctx, e.Meta, e)
+}
+
+func (s *Service) QueueResumeWebhooks(ctx context.Context, e *ResumeEvent) error {
+	return s.queueWebhooks(ctx, e.Meta, e)
+}
+
+func (s *Service) QueueContentWebhooks(ctx context.Context, e *ContentEvent) error {
+	return s.queueWebhooks(ctx, e.Meta, e)
+}
+
+// queueWebhooks queues a delivery of msg to every webhook of its site that
+// asked for events of its type, and has them sent.
+func (s *Service) queueWebhooks(ctx context.Context, meta EventMeta, msg any) error {
+	var n int
+	err := transaction(ctx, meta.SiteID, func(tx *gorm.DB) (err error) {
+		n, err = queueDeliveries(tx, meta, msg)
+		return err
+	})
+	if n > 0 {
+		s.webhooks.notify()
+	}
+	return err
+}
+
+func queueDeliveries(tx *gorm.DB, meta EventMeta, msg any) (int, error) {
+	var hooks []Webhook
+	if err := tx.Find(&hooks).Error; err != nil {
+		return 0, err
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	var deliveries []WebhookDelivery
+	for _, hook := range hooks {
+		if !slices.Contains(strings.Fields(hook.Events), string(meta.Type)) {
+			continue
+		}
+		deliveries = append(deliveries, WebhookDelivery{
+			WebhookID:     hook.ID,
+			EventID:       meta.ID,
+			EventType:     string(meta.Type),
+			Payload:       payload,
+			Status:        DeliveryPending,
+			NextAttemptAt: &now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return 0, nil
+	}
+	// A redelivered event is only queued once per webhook.
+	err = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error
+	return len(deliveries), err
+}
+
+// webhookDispatcher sends the pending deliveries. It runs when deliveries are
+// queued and every interval, which picks up the retries.
+type webhookDispatcher struct {
+	poller
+	client      *http.Client
+	maxAttempts int
+	interval    time.Duration
+	retention   time.Duration
+}
+
+// newWebhookDispatcher returns a dispatcher whose client refuses to connect to
+// private addresses, unless allowPrivate is set.
+func newWebhookDispatcher(timeout time.Duration, maxAttempts int, interval, retention time.Duration, allowPrivate bool) *webhookDispatcher {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivate {
+		// Checking the address that is actually dialed also covers host
+		// names that resolve to private addresses, and redirects.
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: publicOnly}
+		transport.DialContext = dialer.DialContext
+		transport.Proxy = nil
+	}
+	return &webhookDispatcher{
+		poller:      newPoller(),
+		client:      &http.Client{Timeout: timeout, Transport: transport},
+		maxAttempts: maxAttempts,
+		interval:    interval,
+		retention:   retention,
+	}
+}
+
+// run sends deliveries until close is called.
+func (d *webhookDispatcher) run() {
+	d.poller.run(d.interval, func(tick bool) {
+		if tick {
+			d.prune()
+		}
+		d.dispatch()
+	})
+}
+
+// dispatch sends the due deliveries, batch by batch.
+func (d *webhookDispatcher) dispatch() {
+	ctx := AcrossSites(context.Background())
+	for {
+		deliveries, hooks, err := d.claim(ctx)
+		if err != nil {
+			rlog.Error("app: claim webhook deliveries", "err", err)
+			return
+		}
+		for i := range deliveries {
+			if hook, ok := hooks[deliveries[i].WebhookID]; ok {
+				d.attempt(ctx, hook, &deliveries[i])
+			}
+		}
+		if len(deliveries) < webhookBatch {
+			return
+		}
+	}
+}
+
+// claim returns the due deliveries and their webhooks. It leases them by
+// moving their next attempt past the time sending them can take, so that
+// other instances skip them meanwhile and pick them up again if this one goes
+// away.
+func (d *webhookDispatcher) claim(ctx context.Context) ([]WebhookDelivery, map[uint]*Webhook, error) {
+	var (
+		deliveries []WebhookDelivery
+		hooks      = make(map[uint]*Webhook)
+	)
+	db, err := database()
+	if err != nil {
+		return nil, nil, err
+	}
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", DeliveryPending, now).
+			Order("next_attempt_at").
+			Limit(webhookBatch).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+		ids := make([]uint, len(deliveries))
+		hookIDs := make([]uint, len(deliveries))
+		for i, delivery := range deliveries {
+			ids[i], hookIDs[i] = delivery.ID, delivery.WebhookID
+		}
+		lease := now.Add(time.Duration(len(deliveries)+1) * d.client.Timeout)
+		err = tx.Model(&WebhookDelivery{}).Where("id IN ?", ids).Update("next_attempt_at", lease).Error
+		if err != nil {
+			return err
+		}
+		var found []*Webhook
+		if err := tx.Find(&found, hookIDs).Error; err != nil {
+			return err
+		}
+		for _, hook := range found {
+			hooks[hook.ID] = hook
+		}
+		return nil
+	})
+	return deliveries, hooks, err
+}
+
+// attempt sends a delivery once and records the outcome. Failed deliveries
+// are retried with exponential backoff until maxAttempts is reached.
+func (d *webhookDispatcher) attempt(ctx context.Context, hook *Webhook, delivery *WebhookDelivery) {
+	status, err := d.send(ctx, hook, delivery)
+	attempts := delivery.Attempts + 1
+	update := map[string]any{"attempts": attempts, "response_status": nil}
+	if status != 0 {
+		update["response_status"] = status
+	}
+	now := time.Now()
+	switch {
+	case err == nil:
+		update["status"] = DeliverySucceeded
+		update["delivered_at"] = now
+		update["next_attempt_at"] = nil
+		update["last_error"] = nil
+	case attempts >= d.maxAttempts:
+		update["status"] = DeliveryFailed
+		update["next_attempt_at"] = nil
+		update["last_error"] = err.Error()
+	default:
+		update["next_attempt_at"] = now.Add(webhookRetryAfter(attempts))
+		update["last_error"] = err.Error()
+	}
+	db, dbErr := database()
+	if dbErr == nil {
+		dbErr = db.WithContext(ctx).Model(delivery).Updates(update).Error
+	}
+	if dbErr != nil {
+		rlog.Error("app: record webhook delivery", "id", delivery.ID, "err", dbErr)
+	}
+}
+
+// webhookRetryAfter returns the wait after the given number of failed
+// attempts.
+func webhookRetryAfter(attempts int) time.Duration {
+	wait := webhookBackoff
+	for i := 1; i < attempts && wait < webhookMaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, webhookMaxBackoff)
+}
+
+// send POSTs the payload of a delivery to its webhook. It returns the status
+// of the response, if there was one, and an error unless the status was 2xx.
+func (d *webhookDispatcher) send(ctx context.Context, hook *Webhook, delivery *WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Delivery", delivery.EventID)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+signWebhook(hook.Secret, timestamp, delivery.Payload))
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Draining the body lets the connection be reused.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// signWebhook returns the hex HMAC-SHA256 of "<timestamp>.<payload>" keyed
+// with secret. Signing the timestamp lets receivers reject replays.
+func signWebhook(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// privateAddr reports whether addr is not on the public internet: loopback,
+// private, link-local such as the cloud metadata service at 169.254.169.254,
+// shared, unspecified or multicast.
+func privateAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsUnspecified() || addr.IsMulticast() || sharedAddrs.Contains(addr)
+}
+
+// sharedAddrs is the carrier-grade NAT range of RFC 6598.
+var sharedAddrs = netip.MustParsePrefix("100.64.0.0/10")
+
+// publicOnly is the net.Dialer Control function that refuses connections to
+// private addresses.
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if privateAddr(addr) {
+		return fmt.Errorf("webhook target %s is not a public address", host)
+	}
+	return nil
+}
+
+// checkWebhookURL rejects webhook URLs that are not https, or whose host is
+// obviously private, unless allowPrivate is set. Host names that resolve to
+// private addresses are refused when the dispatcher connects.
+func checkWebhookURL(raw string, allowPrivate bool) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return invalidArgument("url", "url must be an absolute http(s) URL")
+	}
+	if allowPrivate {
+		return nil
+	}
+	if u.Scheme != "https" {
+		return invalidArgument("url", "url must use https")
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return invalidArgument("url", "url must not point to a private address")
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && privateAddr(addr) {
+		return invalidArgument("url", "url must not point to a private address")
+	}
+	return nil
+}
+
+// prune deletes the finished deliveries older than the retention.
+func (d *webhookDispatcher) prune() {
+	db, err := database()
+	if err == nil {
+		err = db.WithContext(AcrossSites(context.Background())).
+			Where("status <> ? AND created_at < ?", DeliveryPending, time.Now().Add(-d.retention)).
+			Delete(&WebhookDelivery{}).Error
+	}
+	if err != nil {
+		rlog.Error("app: prune webhook deliveries", "err", err)
+	}
+}
+
+type ListWebhooksResponse struct {
+	Webhooks []*Webhook `json:"webhooks"`
+}
+
+// ListWebhooks lists the webhooks of a site, by ID.
+//
+//encore:api private method=GET path=/sites/:siteID/webhooks
+func ListWebhooks(ctx context.Context, siteID uint) (*ListWebhooksResponse, error) {
+	db, err := session(ctx, siteID)
+	if err != nil {
+		return nil, err
+	}
+	hooks := []*Webhook{}
+	if err := db.Order("id").Find(&hooks).Error; err != nil {
+		return nil, err
+	}
+	return &ListWebhooksResponse{Webhooks: hooks}, nil
+}
+
+type CreateWebhookParams struct {
+	URL string `json:"url"`
+	// Events are the EventTypes the webhook receives.
+	Events []EventType `json:"events"`
+	// Secret signs the deliveries, see signWebhook.
+	Secret string `json:"secret"`
+}
+
+// CreateWebhook registers a webhook of a site.
+//
+//encore:api private method=POST path=/sites/:siteID/webhooks
+func CreateWebhook(ctx context.Context, siteID uint, p *CreateWebhookParams) (*Webhook, error) {
+	if err := checkWebhookURL(p.URL, cfg.Webhooks.AllowPrivateTargets()); err != nil {
+		return nil, err
+	}
+	if len(p.Events) == 0 {
+		return nil, invalidArgument("events", "events must not be empty")
+	}
+	events := make([]string, len(p.Events))
+	for i, event := range p.Events {
+		if !slices.Contains(EventTypes, event) {
+			return nil, invalidArgument("events", fmt.Sprintf("unknown event %q", event))
+		}
+		events[i] = string(event)
+	}
+	hook := &Webhook{URL: p.URL, Events: strings.Join(events, " "), Secret: p.Secret}
+	err := transaction(ctx, siteID, func(tx *gorm.DB) error {
+		return tx.Create(hook).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+// DeleteWebhook deletes a webhook of a site together with its deliveries.
+//
+//encore:api private method=DELETE path=/sites/:siteID/webhooks/:id
+func DeleteWebhook(ctx context.Context, siteID, id uint) error {
+	return transaction(ctx, siteID, func(tx *gorm.DB) error {
+		res := tx.Delete(&Webhook{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+type ListWebhookDeliveriesParams struct {
+	// WebhookID limits the list to the deliveries of one webhook.
+	WebhookID uint `query:"webhookId"`
+	// Status limits the list to deliveries of that status.
+	Status string `query:"status"`
+	// Limit is the most deliveries listed, if set.
+	Limit int `query:"limit"`
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []*WebhookDelivery `json:"deliveries"`
+}
+
+// ListWebhookDeliveries lists the webhook deliveries of a site, newest first.
+//
+//encore:api private method=GET path=/sites/:siteID/webhook-deliveries
+func ListWebhookDeliveries(ctx context.Context, siteID uint, p *ListWebhookDeliveriesParams) (*ListWebhookDeliveriesResponse, error) {
+	db, err := session(ctx, siteID)
+	if err != nil {
+		return nil, err
+	}
+	var f filter
+	if p.WebhookID != 0 {
+		f.add("webhook_id = ?", p.WebhookID)
+	}
+	if p.Status != "" {
+		f.add("status = ?", p.Status)
+	}
+	q := f.apply(db).Order("id DESC")
+	if p.Limit > 0 {
+		q = q.Limit(p.Limit)
+	}
+	deliveries := []*WebhookDelivery{}
+	if err := q.Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return &ListWebhookDeliveriesResponse{Deliveries: deliveries}, nil
+}
@@ -0,0 +1,240 @@
+//go:build encore_app
+
+package app
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestCheckWebhookURL(t *testing.T) {
+	tests := []struct {
+		url          string
+		allowPrivate bool
+		ok           bool
+	}{
+		{"https://api.vercel.com/v1/integrations/deploy/x", false, true},
+		{"https://93.184.216.34/hook", false, true},
+		{"http://api.vercel.com/hook", false, false},
+		{"ftp://example.com/hook", false, false},
+		{"/relative", false, false},
+		{"https://localhost/hook", false, false},
+		{"https://app.localhost./hook", false, false},
+		{"https://127.0.0.1/hook", false, false},
+		{"https://[::1]/hook", false, false},
+		{"https://10.0.0.8/hook", false, false},
+		{"https://192.168.1.1/hook", false, false},
+		{"https://169.254.169.254/latest/meta-data", false, false},
+		{"https://[::ffff:169.254.169.254]/hook", false, false},
+		{"https://100.64.0.1/hook", false, false},
+		{"https://0.0.0.0/hook", false, false},
+		{"http://127.0.0.1:8080/hook", true, true},
+		{"ftp://127.0.0.1/hook", true, false},
+	}
+	for _, tt := range tests {
+		err := checkWebhookURL(tt.url, tt.allowPrivate)
+		if (err == nil) != tt.ok {
+			t.Errorf("checkWebhookURL(%q, %v) = %v, want ok %v", tt.url, tt.allowPrivate, err, tt.ok)
+		}
+	}
+}
+
+func TestWebhookDispatcherRefusesPrivateTargets(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer srv.Close()
+
+	d := newWebhookDispatcher(time.Second, 3, time.Minute, time.Hour, false)
+	_, err := d.send(context.Background(), &Webhook{URL: srv.URL, Secret: "secret"}, &WebhookDelivery{Payload: []byte("{}")})
+	if err == nil || !strings.Contains(err.Error(), "not a public address") {
+		t.Fatalf("send to %s: err = %v, want a refused connection", srv.URL, err)
+	}
+	if hits.Load() != 0 {
+		t.Fatal("the private target was reached")
+	}
+}
+
+func TestWebhookSignature(t *testing.T) {
+	const secret = "a-long-random-secret"
+	payload := []byte(`{"Meta":{"Type":"BlogCreated"}}`)
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	got := make(chan received, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- received{r.Header, body}
+	}))
+	defer srv.Close()
+
+	d := newWebhookDispatcher(time.Second, 3, time.Minute, time.Hour, true)
+	delivery := &WebhookDelivery{EventID: "evt-1", EventType: "BlogCreated", Payload: payload}
+	status, err := d.send(context.Background(), &Webhook{URL: srv.URL, Secret: secret}, delivery)
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("send = %d, %v", status, err)
+	}
+	r := <-got
+	if r.header.Get("X-Webhook-Event") != "BlogCreated" || r.header.Get("X-Webhook-Delivery") != "evt-1" {
+		t.Errorf("headers = %v", r.header)
+	}
+	// Verify the way a receiver would.
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(r.header.Get("X-Webhook-Timestamp") + "."))
+	mac.Write(r.body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if sig := r.header.Get("X-Webhook-Signature"); !hmac.Equal([]byte(sig), []byte(want)) {
+		t.Errorf("X-Webhook-Signature = %q, want %q", sig, want)
+	}
+	ts := r.header.Get("X-Webhook-Timestamp")
+	if signWebhook("other-secret", ts, r.body) == signWebhook(secret, ts, r.body) {
+		t.Error("the signature does not depend on the secret")
+	}
+}
+
+// queueTestDelivery stores a webhook to url and a pending delivery to it that
+// was tried attempts times.
+func queueTestDelivery(t *testing.T, site *Site, url string, attempts int) (*Webhook, *WebhookDelivery) {
+	t.Helper()
+	hook := &Webhook{URL: url, Events: string(BlogCreated), Secret: "a-long-random-secret"}
+	now := time.Now()
+	delivery := &WebhookDelivery{
+		EventID:       newEventMeta(BlogCreated, site.ID).ID,
+		EventType:     string(BlogCreated),
+		Payload:       []byte("{}"),
+		Status:        DeliveryPending,
+		Attempts:      attempts,
+		NextAttemptAt: &now,
+	}
+	err := transaction(context.Background(), site.ID, func(tx *gorm.DB) error {
+		if err := tx.Create(hook).Error; err != nil {
+			return err
+		}
+		delivery.WebhookID = hook.ID
+		return tx.Create(delivery).Error
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hook, delivery
+}
+
+func reloadDelivery(t *testing.T, site *Site, id uint) *WebhookDelivery {
+	t.Helper()
+	db, err := session(context.Background(), site.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var delivery WebhookDelivery
+	if err := db.First(&delivery, id).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &delivery
+}
+
+func failingEndpoint(t *testing.T) string {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestWebhookRetriesFailedDelivery(t *testing.T) {
+	site := createSite(t, "webhook-retry")
+	hook, delivery := queueTestDelivery(t, site, failingEndpoint(t), 1)
+
+	d := newWebhookDispatcher(time.Second, 8, time.Minute, time.Hour, true)
+	before := time.Now()
+	d.attempt(AcrossSites(context.Background()), hook, delivery)
+
+	got := reloadDelivery(t, site, delivery.ID)
+	if got.Status != DeliveryPending || got.Attempts != 2 {
+		t.Fatalf("status %s after %d attempts, want PENDING after 2", got.Status, got.Attempts)
+	}
+	if got.ResponseStatus == nil || *got.ResponseStatus != http.StatusServiceUnavailable {
+		t.Errorf("response status = %v, want 503", got.ResponseStatus)
+	}
+	if got.LastError == nil {
+		t.Error("the error was not recorded")
+	}
+	wait := webhookRetryAfter(2)
+	if got.NextAttemptAt == nil || got.NextAttemptAt.Before(before.Add(wait)) || got.NextAttemptAt.After(time.Now().Add(wait)) {
+		t.Errorf("next attempt at %v, want %v from now", got.NextAttemptAt, wait)
+	}
+}
+
+func TestWebhookFailsAfterMaxAttempts(t *testing.T) {
+	site := createSite(t, "webhook-failed")
+	const maxAttempts = 3
+	hook, delivery := queueTestDelivery(t, site, failingEndpoint(t), maxAttempts-1)
+
+	d := newWebhookDispatcher(time.Second, maxAttempts, time.Minute, time.Hour, true)
+	d.attempt(AcrossSites(context.Background()), hook, delivery)
+
+	got := reloadDelivery(t, site, delivery.ID)
+	if got.Status != DeliveryFailed || got.Attempts != maxAttempts || got.NextAttemptAt != nil {
+		t.Fatalf("status %s after %d attempts, next at %v; want FAILED after %d, no next attempt",
+			got.Status, got.Attempts, got.NextAttemptAt, maxAttempts)
+	}
+}
+
+func TestQueueDeliveriesOncePerEvent(t *testing.T) {
+	site := createSite(t, "webhook-dedupe")
+	ctx := context.Background()
+	err := transaction(ctx, site.ID, func(tx *gorm.DB) error {
+		return tx.Create(&Webhook{URL: "https://example.com/hook", Events: string(BlogCreated), Secret: "a-long-random-secret"}).Error
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := newEventMeta(BlogCreated, site.ID)
+	msg := &BlogEvent{Meta: meta}
+	// The second time stands for a redelivery of the event.
+	for i := 0; i < 2; i++ {
+		err := transaction(ctx, site.ID, func(tx *gorm.DB) error {
+			_, err := queueDeliveries(tx, meta, msg)
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	db, err := session(ctx, site.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int64
+	if err := db.Model(&WebhookDelivery{}).Where("event_id = ?", meta.ID).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("%d deliveries of one event, want 1", n)
+	}
+
+	// Webhooks that did not ask for the event type get nothing.
+	other := newEventMeta(BlogDeleted, site.ID)
+	err = transaction(ctx, site.ID, func(tx *gorm.DB) error {
+		n, err := queueDeliveries(tx, other, &BlogEvent{Meta: other})
+		if err == nil && n != 0 {
+			t.Errorf("queued %d deliveries of an event no webhook asked for", n)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,129 @@
+package graphql
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"encore.app/app"
+	"encore.app/graphql/model"
+)
+
+const (
+	// viewDedupeSize is how many views of a day viewDedupe remembers.
+	viewDedupeSize = 200_000
+)
+
+// viewDedupe remembers which visitors viewed which records today, so that
+// reloads are only counted once. It keeps salted hashes in memory, and the
+// salt changes every day, so they cannot be traced back to an IP address or
+// linked across days. A nil viewDedupe counts every view.
+type viewDedupe struct {
+	mu   sync.Mutex
+	day  time.Time
+	salt [16]byte
+	seen map[[sha256.Size]byte]struct{}
+}
+
+// first reports whether ip has not viewed the record typ pk on day yet. Once
+// the day is full, every further view is counted.
+func (d *viewDedupe) first(ip, typ string, pk uint, day time.Time) bool {
+	if d == nil || ip == "" {
+		return true
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.day.Equal(day) {
+		d.day = day
+		rand.Read(d.salt[:])
+		d.seen = make(map[[sha256.Size]byte]struct{})
+	}
+	h := sha256.New()
+	h.Write(d.salt[:])
+	fmt.Fprintf(h, "%s\x00%s\x00%d", ip, typ, pk)
+	var sum [sha256.Size]byte
+	h.Sum(sum[:0])
+	if _, ok := d.seen[sum]; ok {
+		return false
+	}
+	if len(d.seen) < viewDedupeSize {
+		d.seen[sum] = struct{}{}
+	}
+	return true
+}
+
+// utcDay returns midnight UTC of the day t falls on in UTC.
+func utcDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// referrerHost reduces a referrer to its host, so that no paths or query
+// strings of other sites are stored. It returns "" for direct visits and
+// anything that is not an http(s) URL.
+func referrerHost(referrer *string) string {
+	if referrer == nil {
+		return ""
+	}
+	u, err := url.Parse(strings.TrimSpace(*referrer))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// viewCount returns all views of the record typ pk. Within a request, the
+// counts of a type are loaded in batches.
+func viewCount(ctx context.Context, typ string, pk uint) (int, error) {
+	return loadersOf(ctx).viewCounts.load(ctx, typ, pk)
+}
+
+func fetchViewCounts(ctx context.Context, site uint, typ string, ids []uint) (map[uint]int, error) {
+	resp, err := app.ViewCounts(ctx, site, &app.ViewCountsParams{Type: typ, IDs: ids})
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[uint]int, len(ids))
+	for i, id := range ids {
+		counts[id] = int(resp.Counts[i])
+	}
+	return counts, nil
+}
+
+// periodStart returns the first day of period, which ends today.
+func periodStart(period model.ViewPeriod, now time.Time) time.Time {
+	days := 1
+	switch period {
+	case model.ViewPeriodWeek:
+		days = 7
+	case model.ViewPeriodMonth:
+		days = 30
+	}
+	return utcDay(now).AddDate(0, 0, 1-days)
+}
+
+// viewStats returns the views of the record typ pk from from to to.
+func viewStats(ctx context.Context, typ string, pk uint, from, to time.Time) (*model.ViewStats, error) {
+	resp, err := app.ViewStats(ctx, siteOf(ctx), &app.ViewStatsParams{Type: typ, ID: pk, From: from, To: to})
+	if err != nil {
+		return nil, err
+	}
+	stats := &model.ViewStats{Days: make([]*model.DailyViews, len(resp.Days)), Referrers: make([]*model.ReferrerViews, len(resp.Referrers))}
+	for i, d := range resp.Days {
+		stats.Days[i] = &model.DailyViews{Date: d.Day, Views: int(d.Views)}
+		stats.Total += int(d.Views)
+	}
+	for i, ref := range resp.Referrers {
+		rv := &model.ReferrerViews{Views: int(ref.Views)}
+		if ref.Referrer != "" {
+			rv.Referrer = &ref.Referrer
+		}
+		stats.Referrers[i] = rv
+	}
+	return stats, nil
+}
@@ -6,377 +6,1383 @@ package graphql
 
 import (
 	"context"
-	"errors"
-	"strconv"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"encore.app/app"
 	"encore.app/graphql/generated"
 	"encore.app/graphql/model"
-	"gorm.io/gorm"
+	"encore.dev/beta/errs"
 )
 
-// CreatedAt is the resolver for the createdAt field.
-func (r *blogResolver) CreatedAt(ctx context.Context, obj *app.Blog) (string, error) {
-	return obj.CreatedAt.Format(time.RFC3339), nil
+// ID is the resolver for the id field.
+func (r *apiKeyResolver) ID(ctx context.Context, obj *app.ApiKey) (string, error) {
+	return globalID(typeAPIKey, obj.ID), nil
+}
+
+// Scopes is the resolver for the scopes field.
+func (r *apiKeyResolver) Scopes(ctx context.Context, obj *app.ApiKey) ([]string, error) {
+	return splitScopes(obj.Scopes), nil
+}
+
+// Content is the resolver for the content field.
+func (r *blogResolver) Content(ctx context.Context, obj *app.Blog, locale *string) (string, error) {
+	_, content, err := r.blogText(ctx, obj, locale)
+	return content, err
 }
 
 // ID is the resolver for the id field.
 func (r *blogResolver) ID(ctx context.Context, obj *app.Blog) (string, error) {
-	return strconv.FormatUint(uint64(obj.ID), 10), nil
+	return globalID(typeBlog, obj.ID), nil
+}
+
+// Reactions is the resolver for the reactions field.
+func (r *blogResolver) Reactions(ctx context.Context, obj *app.Blog) ([]*model.Reaction, error) {
+	return reactions(ctx, obj.ID, reactorFor(ctx))
+}
+
+// Title is the resolver for the title field.
+func (r *blogResolver) Title(ctx context.Context, obj *app.Blog, locale *string) (string, error) {
+	title, _, err := r.blogText(ctx, obj, locale)
+	return title, err
+}
+
+// Translations is the resolver for the translations field.
+func (r *blogResolver) Translations(ctx context.Context, obj *app.Blog) ([]*app.BlogTranslation, error) {
+	translations, err := loadersOf(ctx).blogTranslations.load(ctx, localesBatchKey(nil), obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	if translations == nil {
+		translations = []*app.BlogTranslation{}
+	}
+	return translations, nil
+}
+
+// ViewCount is the resolver for the viewCount field.
+func (r *blogResolver) ViewCount(ctx context.Context, obj *app.Blog) (int, error) {
+	return viewCount(ctx, typeBlog, obj.ID)
+}
+
+// ID is the resolver for the id field.
+func (r *contactMessageResolver) ID(ctx context.Context, obj *app.ContactMessage) (string, error) {
+	return globalID(typeContactMessage, obj.ID), nil
+}
+
+// Status is the resolver for the status field.
+func (r *contactMessageResolver) Status(ctx context.Context, obj *app.ContactMessage) (model.ContactMessageStatus, error) {
+	switch {
+	case obj.ArchivedAt != nil:
+		return model.ContactMessageStatusArchived, nil
+	case obj.ReadAt != nil:
+		return model.ContactMessageStatusRead, nil
+	}
+	return model.ContactMessageStatusUnread, nil
+}
+
+// ArchiveMessage is the resolver for the archiveMessage field.
+func (r *mutationResolver) ArchiveMessage(ctx context.Context, id string, clientMutationID *string) (*model.UpdateContactMessagePayload, error) {
+	payload := &model.UpdateContactMessagePayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() (err error) {
+		archived := true
+		payload.Message, err = updateContactMessage(ctx, id, &app.UpdateContactMessageParams{Archived: &archived})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
+// BlogCreate is the resolver for the blogCreate field.
+func (r *mutationResolver) BlogCreate(ctx context.Context, input model.CreateBlogInput, clientMutationID *string) (*model.CreateBlogPayload, error) {
+	payload := &model.CreateBlogPayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() (err error) {
+		payload.Blog, err = r.CreateBlog(ctx, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
+// BlogDelete is the resolver for the blogDelete field.
+func (r *mutationResolver) BlogDelete(ctx context.Context, id string, clientMutationID *string) (*model.DeleteBlogPayload, error) {
+	payload := &model.DeleteBlogPayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() error {
+		if _, err := r.DeleteBlog(ctx, id); err != nil {
+			return err
+		}
+		payload.DeletedID = &id
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
+// BlogUpdate is the resolver for the blogUpdate field.
+func (r *mutationResolver) BlogUpdate(ctx context.Context, id string, input model.UpdateBlogInput, clientMutationID *string) (*model.UpdateBlogPayload, error) {
+	payload := &model.UpdateBlogPayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() (err error) {
+		payload.Blog, err = r.UpdateBlog(ctx, id, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
+// ConfirmSubscription is the resolver for the confirmSubscription field.
+func (r *mutationResolver) ConfirmSubscription(ctx context.Context, token string, clientMutationID *string) (*model.ConfirmSubscriptionPayload, error) {
+	payload := &model.ConfirmSubscriptionPayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() error {
+		if err := confirm(ctx, token, time.Now()); err != nil {
+			return err
+		}
+		payload.Confirmed = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
+// CreateAPIKey is the resolver for the createApiKey field.
+func (r *mutationResolver) CreateAPIKey(ctx context.Context, name string, scopes []string, expiresAt *time.Time, clientMutationID *string) (*model.CreateAPIKeyPayload, error) {
+	payload := &model.CreateAPIKeyPayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() error {
+		if len(scopes) == 0 {
+			return invalidArgument("scopes", "scopes must not be empty")
+		}
+		p := principalFrom(ctx)
+		for _, scope := range scopes {
+			if !slices.Contains(r.scopes, scope) {
+				return invalidArgument("scopes", fmt.Sprintf("unknown scope %q", scope))
+			}
+			// Keys can't be used to mint keys with more rights than their own.
+			if !p.has(scope) {
+				return &errs.Error{Code: errs.PermissionDenied, Message: fmt.Sprintf("cannot grant the %s scope without holding it", scope), Details: app.FieldDetails{Field: "scopes"}}
+			}
+		}
+		if expiresAt != nil && !expiresAt.After(time.Now()) {
+			return invalidArgument("expiresAt", "expiresAt must be in the future")
+		}
+		secret := newAPIKey()
+		key, err := app.CreateApiKey(ctx, siteOf(ctx), &app.CreateApiKeyParams{
+			Name:      strings.TrimSpace(name),
+			Prefix:    secret[:apiKeyShown],
+			Hash:      hashAPIKey(secret),
+			Scopes:    strings.Join(scopes, " "),
+			ExpiresAt: expiresAt,
+		})
+		if err != nil {
+			return err
+		}
+		payload.APIKey, payload.Key = key, &secret
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload.UserErrors = userErrors
+	return payload, nil
 }
 
 // CreateBlog is the resolver for the createBlog field.
 func (r *mutationResolver) CreateBlog(ctx context.Context, input model.CreateBlogInput) (*app.Blog, error) {
-	blog := &app.Blog{
-		Title:     input.Title,
-		Content:   input.Content,
-		CreatedAt: time.Now(),
-	}
-	if err := r.db.Create(blog).Error; err != nil {
+	blog, err := app.CreateBlog(ctx, siteOf(ctx), createBlogParams(&input))
+	if err != nil {
 		return nil, err
 	}
+	invalidate(ctx, typeBlog, blog.ID)
 	return blog, nil
 }
 
+// CreateBlogs is the resolver for the createBlogs field.
+func (r *mutationResolver) CreateBlogs(ctx context.Context, inputs []*model.CreateBlogInput, atomic bool, clientMutationID *string) (*model.CreateBlogsPayload, error) {
+	blogs := make([]*app.Blog, len(inputs))
+	_, userErrors, err := runBulk(ctx, len(inputs), atomic, inputPath("inputs"), func(i int) (*app.BlogOp, error) {
+		return &app.BlogOp{Create: createBlogParams(inputs[i])}, nil
+	}, r.sendBlogs(ctx, atomic, func(i int, res *app.BlogResult) {
+		blogs[i] = res.Blog
+		invalidate(ctx, typeBlog, res.Blog.ID)
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return &model.CreateBlogsPayload{Blogs: blogs, UserErrors: userErrors, ClientMutationID: clientMutationID}, nil
+}
+
+// CreatePreviewLink is the resolver for the createPreviewLink field.
+func (r *mutationResolver) CreatePreviewLink(ctx context.Context, id string, expiresIn time.Duration, clientMutationID *string) (*model.CreatePreviewLinkPayload, error) {
+	payload := &model.CreatePreviewLinkPayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() error {
+		typ, pk, ok := decodeGlobalID(id)
+		var scope string
+		switch {
+		case ok && typ == typeBlog:
+			scope = "write:blogs"
+		case ok && typ == typeProject:
+			scope = "write:projects"
+		default:
+			return invalidArgument("id", "id must refer to a blog post or project")
+		}
+		if err := principalFrom(ctx).require(scope); err != nil {
+			return err
+		}
+		if maxDays := cfg.Previews.MaxDays(); expiresIn <= 0 || expiresIn > time.Duration(maxDays)*24*time.Hour {
+			return invalidArgument("expiresIn", fmt.Sprintf("expiresIn must be positive and at most %d days", maxDays))
+		}
+		if secrets.PreviewSecret == "" {
+			return &errs.Error{Code: errs.FailedPrecondition, Message: "preview links are not configured"}
+		}
+		// The scope lets the caller see drafts and hidden projects.
+		if err := requireVisible(ctx, typ, pk); err != nil {
+			return err
+		}
+		site, _ := app.SiteFrom(ctx)
+		expires := time.Now().Add(expiresIn).Truncate(time.Second)
+		token := previewToken(site, typ, pk, expires)
+		link := previewURL(id, token)
+		payload.URL, payload.Token, payload.ExpiresAt = &link, &token, &expires
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
 // CreateProject is the resolver for the createProject field.
 func (r *mutationResolver) CreateProject(ctx context.Context, input model.CreateProjectInput) (*app.Project, error) {
-	userID, err := strconv.ParseUint(input.UserID, 10, 64)
+	params, err := createProjectParams(&input)
 	if err != nil {
 		return nil, err
 	}
-	project := &app.Project{
-		Title:       input.Title,
-		Description: input.Description,
-		UserID:      uint(userID),
-	}
-	if err := r.db.Create(project).Error; err != nil {
+	project, err := app.CreateProject(ctx, siteOf(ctx), params)
+	if err != nil {
 		return nil, err
 	}
+	invalidate(ctx, typeProject, project.ID)
 	return project, nil
 }
 
+// CreateProjects is the resolver for the createProjects field.
+func (r *mutationResolver) CreateProjects(ctx context.Context, inputs []*model.CreateProjectInput, atomic bool, clientMutationID *string) (*model.CreateProjectsPayload, error) {
+	projects := make([]*app.Project, len(inputs))
+	_, userErrors, err := runBulk(ctx, len(inputs), atomic, inputPath("inputs"), func(i int) (*app.ProjectOp, error) {
+		params, err := createProjectParams(inputs[i])
+		return &app.ProjectOp{Create: params}, err
+	}, r.sendProjects(ctx, atomic, func(i int, res *app.ProjectResult) {
+		projects[i] = res.Project
+		invalidate(ctx, typeProject, res.Project.ID)
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return &model.CreateProjectsPayload{Projects: projects, UserErrors: userErrors, ClientMutationID: clientMutationID}, nil
+}
+
 // CreateResume is the resolver for the createResume field.
 func (r *mutationResolver) CreateResume(ctx context.Context, input model.CreateResumeInput) (*app.Resume, error) {
-	resume := &app.Resume{
-		Title:       input.Title,
-		Description: input.Description,
-		Category:    input.Category,
-	}
-	if err := r.db.Create(resume).Error; err != nil {
+	resume, err := app.CreateResume(ctx, siteOf(ctx), createResumeParams(&input))
+	if err != nil {
 		return nil, err
 	}
+	invalidate(ctx, typeResume, resume.ID)
 	return resume, nil
 }
 
+// CreateResumes is the resolver for the createResumes field.
+func (r *mutationResolver) CreateResumes(ctx context.Context, inputs []*model.CreateResumeInput, atomic bool, clientMutationID *string) (*model.CreateResumesPayload, error) {
+	resumes := make([]*app.Resume, len(inputs))
+	_, userErrors, err := runBulk(ctx, len(inputs), atomic, inputPath("inputs"), func(i int) (*app.ResumeOp, error) {
+		return &app.ResumeOp{Create: createResumeParams(inputs[i])}, nil
+	}, r.sendResumes(ctx, atomic, func(i int, res *app.ResumeResult) {
+		resumes[i] = res.Resume
+		invalidate(ctx, typeResume, res.Resume.ID)
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return &model.CreateResumesPayload{Resumes: resumes, UserErrors: userErrors, ClientMutationID: clientMutationID}, nil
+}
+
 // CreateUser is the resolver for the createUser field.
 func (r *mutationResolver) CreateUser(ctx context.Context, input model.CreateUserInput) (*app.User, error) {
-	user := &app.User{
-		Name:      input.Name,
-		Email:     input.Email,
-		CreatedAt: time.Now(),
-	}
-	if err := r.db.Create(user).Error; err != nil {
+	user, err := app.CreateUser(ctx, siteOf(ctx), createUserParams(&input))
+	if err != nil {
 		return nil, err
 	}
+	invalidate(ctx, typeUser, user.ID)
 	return user, nil
 }
 
+// CreateUsers is the resolver for the createUsers field.
+func (r *mutationResolver) CreateUsers(ctx context.Context, inputs []*model.CreateUserInput, atomic bool, clientMutationID *string) (*model.CreateUsersPayload, error) {
+	users := make([]*app.User, len(inputs))
+	_, userErrors, err := runBulk(ctx, len(inputs), atomic, inputPath("inputs"), func(i int) (*app.UserOp, error) {
+		return &app.UserOp{Create: createUserParams(inputs[i])}, nil
+	}, r.sendUsers(ctx, atomic, func(i int, res *app.UserResult) {
+		users[i] = res.User
+		invalidate(ctx, typeUser, res.User.ID)
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return &model.CreateUsersPayload{Users: users, UserErrors: userErrors, ClientMutationID: clientMutationID}, nil
+}
+
+// CreateWebhook is the resolver for the createWebhook field.
+func (r *mutationResolver) CreateWebhook(ctx context.Context, url string, events []string, secret string, clientMutationID *string) (*model.CreateWebhookPayload, error) {
+	payload := &model.CreateWebhookPayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() error {
+		types := make([]app.EventType, len(events))
+		for i, event := range events {
+			types[i] = app.EventType(event)
+		}
+		hook, err := app.CreateWebhook(ctx, siteOf(ctx), &app.CreateWebhookParams{URL: url, Events: types, Secret: secret})
+		if err != nil {
+			return err
+		}
+		payload.Webhook = hook
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
 // DeleteBlog is the resolver for the deleteBlog field.
 func (r *mutationResolver) DeleteBlog(ctx context.Context, id string) (bool, error) {
-	blogID, err := strconv.ParseUint(id, 10, 64)
+	blogID, err := parseID("id", typeBlog, id)
 	if err != nil {
 		return false, err
 	}
-	if err := r.db.Delete(&app.Blog{}, blogID).Error; err != nil {
+	if _, err := app.DeleteBlog(ctx, siteOf(ctx), blogID); err != nil {
 		return false, err
 	}
+	invalidate(ctx, typeBlog, blogID)
 	return true, nil
 }
 
+// DeleteBlogs is the resolver for the deleteBlogs field.
+func (r *mutationResolver) DeleteBlogs(ctx context.Context, ids []string, where *model.BlogFilter, atomic bool, clientMutationID *string) (*model.DeleteBlogsPayload, error) {
+	var (
+		pks  []uint
+		path func(i int, field string) []string
+	)
+	userErrors, err := mutate(ctx, func() (err error) {
+		pks, path, err = deleteTargets(typeBlog, ids, blogFilter(ctx, where))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(userErrors) > 0 {
+		return &model.DeleteBlogsPayload{DeletedIds: []string{}, UserErrors: userErrors, ClientMutationID: clientMutationID}, nil
+	}
+	done, userErrors, err := runBulk(ctx, len(pks), atomic, path, func(i int) (*app.BlogOp, error) {
+		return &app.BlogOp{Delete: &app.DeleteOp{ID: pks[i]}}, nil
+	}, r.sendBlogs(ctx, atomic, func(i int, _ *app.BlogResult) {
+		invalidate(ctx, typeBlog, pks[i])
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return &model.DeleteBlogsPayload{DeletedIds: deletedIDs(typeBlog, pks, done), UserErrors: userErrors, ClientMutationID: clientMutationID}, nil
+}
+
 // DeleteProject is the resolver for the deleteProject field.
 func (r *mutationResolver) DeleteProject(ctx context.Context, id string) (bool, error) {
-	projectID, err := strconv.ParseUint(id, 10, 64)
+	projectID, err := parseID("id", typeProject, id)
 	if err != nil {
 		return false, err
 	}
-	if err := r.db.Delete(&app.Project{}, projectID).Error; err != nil {
+	if _, err := app.DeleteProject(ctx, siteOf(ctx), projectID); err != nil {
 		return false, err
 	}
+	invalidate(ctx, typeProject, projectID)
 	return true, nil
 }
 
+// DeleteProjects is the resolver for the deleteProjects field.
+func (r *mutationResolver) DeleteProjects(ctx context.Context, ids []string, where *model.ProjectFilter, atomic bool, clientMutationID *string) (*model.DeleteProjectsPayload, error) {
+	var (
+		pks  []uint
+		path func(i int, field string) []string
+	)
+	userErrors, err := mutate(ctx, func() (err error) {
+		pks, path, err = deleteTargets(typeProject, ids, projectFilter(ctx, where))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(userErrors) > 0 {
+		return &model.DeleteProjectsPayload{DeletedIds: []string{}, UserErrors: userErrors, ClientMutationID: clientMutationID}, nil
+	}
+	done, userErrors, err := runBulk(ctx, len(pks), atomic, path, func(i int) (*app.ProjectOp, error) {
+		return &app.ProjectOp{Delete: &app.DeleteOp{ID: pks[i]}}, nil
+	}, r.sendProjects(ctx, atomic, func(i int, _ *app.ProjectResult) {
+		invalidate(ctx, typeProject, pks[i])
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return &model.DeleteProjectsPayload{DeletedIds: deletedIDs(typeProject, pks, done), UserErrors: userErrors, ClientMutationID: clientMutationID}, nil
+}
+
 // DeleteResume is the resolver for the deleteResume field.
 func (r *mutationResolver) DeleteResume(ctx context.Context, id string) (bool, error) {
-	resumeID, err := strconv.ParseUint(id, 10, 64)
+	resumeID, err := parseID("id", typeResume, id)
 	if err != nil {
 		return false, err
 	}
-	if err := r.db.Delete(&app.Resume{}, resumeID).Error; err != nil {
+	if _, err := app.DeleteResume(ctx, siteOf(ctx), resumeID); err != nil {
 		return false, err
 	}
+	invalidate(ctx, typeResume, resumeID)
 	return true, nil
 }
 
+// DeleteResumes is the resolver for the deleteResumes field.
+func (r *mutationResolver) DeleteResumes(ctx context.Context, ids []string, where *model.ResumeFilter, atomic bool, clientMutationID *string) (*model.DeleteResumesPayload, error) {
+	var (
+		pks  []uint
+		path func(i int, field string) []string
+	)
+	userErrors, err := mutate(ctx, func() (err error) {
+		pks, path, err = deleteTargets(typeResume, ids, resumeFilter(ctx, where))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(userErrors) > 0 {
+		return &model.DeleteResumesPayload{DeletedIds: []string{}, UserErrors: userErrors, ClientMutationID: clientMutationID}, nil
+	}
+	done, userErrors, err := runBulk(ctx, len(pks), atomic, path, func(i int) (*app.ResumeOp, error) {
+		return &app.ResumeOp{Delete: &app.DeleteOp{ID: pks[i]}}, nil
+	}, r.sendResumes(ctx, atomic, func(i int, _ *app.ResumeResult) {
+		invalidate(ctx, typeResume, pks[i])
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return &model.DeleteResumesPayload{DeletedIds: deletedIDs(typeResume, pks, done), UserErrors: userErrors, ClientMutationID: clientMutationID}, nil
+}
+
 // DeleteUser is the resolver for the deleteUser field.
-func (r *mutationResolver) DeleteUser(ctx context.Context, id string) (bool, error) {
-	userID, err := strconv.ParseUint(id, 10, 64)
+func (r *mutationResolver) DeleteUser(ctx context.Context, id string, strategy model.DeleteUserStrategy, toUserID *string) (bool, error) {
+	userID, err := parseID("id", typeUser, id)
+	if err != nil {
+		return false, err
+	}
+	params, err := deleteUserParams(strategy, toUserID)
 	if err != nil {
 		return false, err
 	}
-	if err := r.db.Delete(&app.User{}, userID).Error; err != nil {
+	resp, err := app.DeleteUser(ctx, siteOf(ctx), userID, params)
+	if err != nil {
 		return false, err
 	}
+	invalidateDeletion(ctx, userID, resp.Deletion)
 	return true, nil
 }
 
-// UpdateBlog is the resolver for the updateBlog field.
-func (r *mutationResolver) UpdateBlog(ctx context.Context, id string, input model.UpdateBlogInput) (*app.Blog, error) {
-	blogID, err := strconv.ParseUint(id, 10, 64)
+// DeleteUsers is the resolver for the deleteUsers field.
+func (r *mutationResolver) DeleteUsers(ctx context.Context, ids []string, where *model.UserFilter, strategy model.DeleteUserStrategy, toUserID *string, atomic bool, clientMutationID *string) (*model.DeleteUsersPayload, error) {
+	var (
+		pks  []uint
+		path func(i int, field string) []string
+	)
+	userErrors, err := mutate(ctx, func() (err error) {
+		pks, path, err = deleteTargets(typeUser, ids, userFilter(ctx, where))
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	var blog app.Blog
-	if err := r.db.First(&blog, blogID).Error; err != nil {
+	if len(userErrors) > 0 {
+		return &model.DeleteUsersPayload{DeletedIds: []string{}, UserErrors: userErrors, ClientMutationID: clientMutationID}, nil
+	}
+	done, userErrors, err := runBulk(ctx, len(pks), atomic, path, func(i int) (*app.UserOp, error) {
+		params, err := deleteUserParams(strategy, toUserID)
+		if err != nil {
+			return nil, err
+		}
+		return &app.UserOp{Delete: &app.DeleteUserOp{ID: pks[i], Params: *params}}, nil
+	}, r.sendUsers(ctx, atomic, func(i int, res *app.UserResult) {
+		invalidateDeletion(ctx, pks[i], res.Deletion)
+	}))
+	if err != nil {
 		return nil, err
 	}
-	if input.Title != nil {
-		blog.Title = *input.Title
+	return &model.DeleteUsersPayload{DeletedIds: deletedIDs(typeUser, pks, done), UserErrors: userErrors, ClientMutationID: clientMutationID}, nil
+}
+
+// DeleteWebhook is the resolver for the deleteWebhook field.
+func (r *mutationResolver) DeleteWebhook(ctx context.Context, id string, clientMutationID *string) (*model.DeleteWebhookPayload, error) {
+	payload := &model.DeleteWebhookPayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() error {
+		hookID, err := parseID("id", typeWebhook, id)
+		if err != nil {
+			return err
+		}
+		if err := app.DeleteWebhook(ctx, siteOf(ctx), hookID); err != nil {
+			return err
+		}
+		payload.DeletedID = &id
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	if input.Content != nil {
-		blog.Content = *input.Content
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
+// MarkMessageRead is the resolver for the markMessageRead field.
+func (r *mutationResolver) MarkMessageRead(ctx context.Context, id string, read bool, clientMutationID *string) (*model.UpdateContactMessagePayload, error) {
+	payload := &model.UpdateContactMessagePayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() (err error) {
+		payload.Message, err = updateContactMessage(ctx, id, &app.UpdateContactMessageParams{Read: &read})
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
-	if err := r.db.Save(&blog).Error; err != nil {
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
+// ProjectCreate is the resolver for the projectCreate field.
+func (r *mutationResolver) ProjectCreate(ctx context.Context, input model.CreateProjectInput, clientMutationID *string) (*model.CreateProjectPayload, error) {
+	payload := &model.CreateProjectPayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() (err error) {
+		payload.Project, err = r.CreateProject(ctx, input)
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
-	return &blog, nil
+	payload.UserErrors = userErrors
+	return payload, nil
 }
 
-// UpdateProject is the resolver for the updateProject field.
-func (r *mutationResolver) UpdateProject(ctx context.Context, id string, input model.UpdateProjectInput) (*app.Project, error) {
-	projectID, err := strconv.ParseUint(id, 10, 64)
+// ProjectDelete is the resolver for the projectDelete field.
+func (r *mutationResolver) ProjectDelete(ctx context.Context, id string, clientMutationID *string) (*model.DeleteProjectPayload, error) {
+	payload := &model.DeleteProjectPayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() error {
+		if _, err := r.DeleteProject(ctx, id); err != nil {
+			return err
+		}
+		payload.DeletedID = &id
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	var project app.Project
-	if err := r.db.First(&project, projectID).Error; err != nil {
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
+// ProjectUpdate is the resolver for the projectUpdate field.
+func (r *mutationResolver) ProjectUpdate(ctx context.Context, id string, input model.UpdateProjectInput, clientMutationID *string) (*model.UpdateProjectPayload, error) {
+	payload := &model.UpdateProjectPayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() (err error) {
+		payload.Project, err = r.UpdateProject(ctx, id, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
+// React is the resolver for the react field.
+func (r *mutationResolver) React(ctx context.Context, blogID string, kind model.ReactionKind, clientMutationID *string) (*model.ReactionPayload, error) {
+	return r.changeReaction(ctx, blogID, clientMutationID, func(pk uint, reactor string) (*app.ReactionsResponse, error) {
+		return app.React(ctx, siteOf(ctx), pk, &app.ReactParams{Kind: string(kind), Reactor: reactor, IncludeDrafts: seesDrafts(ctx)})
+	})
+}
+
+// RecordView is the resolver for the recordView field.
+func (r *mutationResolver) RecordView(ctx context.Context, entityType model.ViewedEntity, id string, referrer *string, clientMutationID *string) (*model.RecordViewPayload, error) {
+	payload := &model.RecordViewPayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() error {
+		typ := typeBlog
+		if entityType == model.ViewedEntityProject {
+			typ = typeProject
+		}
+		pk, err := parseID("id", typ, id)
+		if err != nil {
+			return err
+		}
+		if err := requireVisible(ctx, typ, pk); err != nil {
+			return err
+		}
+		day := utcDay(time.Now())
+		var ip string
+		if c, ok := ctx.Value(clientKey{}).(*client); ok {
+			ip = c.ip
+		}
+		if !r.views.first(ip, typ, pk, day) {
+			return nil
+		}
+		err = app.RecordView(ctx, siteOf(ctx), &app.RecordViewParams{Type: typ, ID: pk, Referrer: referrerHost(referrer), Day: day})
+		if err != nil {
+			return err
+		}
+		payload.Recorded = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
+// ResumeCreate is the resolver for the resumeCreate field.
+func (r *mutationResolver) ResumeCreate(ctx context.Context, input model.CreateResumeInput, clientMutationID *string) (*model.CreateResumePayload, error) {
+	payload := &model.CreateResumePayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() (err error) {
+		payload.Resume, err = r.CreateResume(ctx, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
+// ResumeDelete is the resolver for the resumeDelete field.
+func (r *mutationResolver) ResumeDelete(ctx context.Context, id string, clientMutationID *string) (*model.DeleteResumePayload, error) {
+	payload := &model.DeleteResumePayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() error {
+		if _, err := r.DeleteResume(ctx, id); err != nil {
+			return err
+		}
+		payload.DeletedID = &id
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
+// ResumeUpdate is the resolver for the resumeUpdate field.
+func (r *mutationResolver) ResumeUpdate(ctx context.Context, id string, input model.UpdateResumeInput, clientMutationID *string) (*model.UpdateResumePayload, error) {
+	payload := &model.UpdateResumePayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() (err error) {
+		payload.Resume, err = r.UpdateResume(ctx, id, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
+// RevokeAPIKey is the resolver for the revokeApiKey field.
+func (r *mutationResolver) RevokeAPIKey(ctx context.Context, id string, clientMutationID *string) (*model.RevokeAPIKeyPayload, error) {
+	payload := &model.RevokeAPIKeyPayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() error {
+		keyID, err := parseID("id", typeAPIKey, id)
+		if err != nil {
+			return err
+		}
+		payload.APIKey, err = app.RevokeApiKey(ctx, siteOf(ctx), keyID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
+// SubmitContactMessage is the resolver for the submitContactMessage field.
+func (r *mutationResolver) SubmitContactMessage(ctx context.Context, name string, email string, subject string, body string, website *string, formToken string, captchaToken *string, clientMutationID *string) (*model.SubmitContactMessagePayload, error) {
+	payload := &model.SubmitContactMessagePayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() error {
+		human, err := r.contact.check(ctx, website, formToken, captchaToken)
+		if err != nil {
+			return err
+		}
+		if human {
+			_, err := app.CreateContactMessage(ctx, siteOf(ctx), &app.CreateContactMessageParams{
+				Name:    strings.TrimSpace(name),
+				Email:   email,
+				Subject: strings.TrimSpace(subject),
+				Body:    strings.TrimSpace(body),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		payload.Accepted = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
+// SubscribeNewsletter is the resolver for the subscribeNewsletter field.
+func (r *mutationResolver) SubscribeNewsletter(ctx context.Context, email string, clientMutationID *string) (*model.SubscribeNewsletterPayload, error) {
+	payload := &model.SubscribeNewsletterPayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() error {
+		if err := subscribe(ctx, email); err != nil {
+			return err
+		}
+		payload.Accepted = true
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
-	if input.Title != nil {
-		project.Title = *input.Title
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
+// Unreact is the resolver for the unreact field.
+func (r *mutationResolver) Unreact(ctx context.Context, blogID string, kind model.ReactionKind, clientMutationID *string) (*model.ReactionPayload, error) {
+	return r.changeReaction(ctx, blogID, clientMutationID, func(pk uint, reactor string) (*app.ReactionsResponse, error) {
+		return app.Unreact(ctx, siteOf(ctx), pk, string(kind), &app.UnreactParams{Reactor: reactor, IncludeDrafts: seesDrafts(ctx)})
+	})
+}
+
+// UpdateBlog is the resolver for the updateBlog field.
+func (r *mutationResolver) UpdateBlog(ctx context.Context, id string, input model.UpdateBlogInput) (*app.Blog, error) {
+	blogID, err := parseID("id", typeBlog, id)
+	if err != nil {
+		return nil, err
 	}
-	if input.Description != nil {
-		project.Description = *input.Description
+	blog, err := app.UpdateBlog(ctx, siteOf(ctx), blogID, updateBlogParams(&input))
+	if err != nil {
+		return nil, r.conflict(ctx, err, typeBlog, blogID)
 	}
-	if input.UserID != nil {
-		userID, err := strconv.ParseUint(*input.UserID, 10, 64)
+	invalidate(ctx, typeBlog, blogID)
+	return blog, nil
+}
+
+// UpdateBlogs is the resolver for the updateBlogs field.
+func (r *mutationResolver) UpdateBlogs(ctx context.Context, items []*model.UpdateBlogItem, atomic bool, clientMutationID *string) (*model.UpdateBlogsPayload, error) {
+	blogs := make([]*app.Blog, len(items))
+	_, userErrors, err := runBulk(ctx, len(items), atomic, itemPath("items"), func(i int) (*app.BlogOp, error) {
+		pk, err := parseID("id", typeBlog, items[i].ID)
 		if err != nil {
 			return nil, err
 		}
-		project.UserID = uint(userID)
+		return &app.BlogOp{Update: &app.UpdateBlogOp{ID: pk, Params: *updateBlogParams(items[i].Input)}}, nil
+	}, r.sendBlogs(ctx, atomic, func(i int, res *app.BlogResult) {
+		blogs[i] = res.Blog
+		invalidate(ctx, typeBlog, res.Blog.ID)
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return &model.UpdateBlogsPayload{Blogs: blogs, UserErrors: userErrors, ClientMutationID: clientMutationID}, nil
+}
+
+// UpdateProject is the resolver for the updateProject field.
+func (r *mutationResolver) UpdateProject(ctx context.Context, id string, input model.UpdateProjectInput) (*app.Project, error) {
+	projectID, err := parseID("id", typeProject, id)
+	if err != nil {
+		return nil, err
+	}
+	params, err := updateProjectParams(&input)
+	if err != nil {
+		return nil, err
+	}
+	project, err := app.UpdateProject(ctx, siteOf(ctx), projectID, params)
+	if err != nil {
+		return nil, r.conflict(ctx, err, typeProject, projectID)
 	}
-	if err := r.db.Save(&project).Error; err != nil {
+	invalidate(ctx, typeProject, projectID)
+	return project, nil
+}
+
+// UpdateProjects is the resolver for the updateProjects field.
+func (r *mutationResolver) UpdateProjects(ctx context.Context, items []*model.UpdateProjectItem, atomic bool, clientMutationID *string) (*model.UpdateProjectsPayload, error) {
+	projects := make([]*app.Project, len(items))
+	_, userErrors, err := runBulk(ctx, len(items), atomic, itemPath("items"), func(i int) (*app.ProjectOp, error) {
+		pk, err := parseID("id", typeProject, items[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		params, err := updateProjectParams(items[i].Input)
+		if err != nil {
+			return nil, err
+		}
+		return &app.ProjectOp{Update: &app.UpdateProjectOp{ID: pk, Params: *params}}, nil
+	}, r.sendProjects(ctx, atomic, func(i int, res *app.ProjectResult) {
+		projects[i] = res.Project
+		invalidate(ctx, typeProject, res.Project.ID)
+	}))
+	if err != nil {
 		return nil, err
 	}
-	return &project, nil
+	return &model.UpdateProjectsPayload{Projects: projects, UserErrors: userErrors, ClientMutationID: clientMutationID}, nil
 }
 
 // UpdateResume is the resolver for the updateResume field.
 func (r *mutationResolver) UpdateResume(ctx context.Context, id string, input model.UpdateResumeInput) (*app.Resume, error) {
-	resumeID, err := strconv.ParseUint(id, 10, 64)
+	resumeID, err := parseID("id", typeResume, id)
 	if err != nil {
 		return nil, err
 	}
-	var resume app.Resume
-	if err := r.db.First(&resume, resumeID).Error; err != nil {
+	resume, err := app.UpdateResume(ctx, siteOf(ctx), resumeID, updateResumeParams(&input))
+	if err != nil {
+		return nil, r.conflict(ctx, err, typeResume, resumeID)
+	}
+	invalidate(ctx, typeResume, resumeID)
+	return resume, nil
+}
+
+// UpdateResumes is the resolver for the updateResumes field.
+func (r *mutationResolver) UpdateResumes(ctx context.Context, items []*model.UpdateResumeItem, atomic bool, clientMutationID *string) (*model.UpdateResumesPayload, error) {
+	resumes := make([]*app.Resume, len(items))
+	_, userErrors, err := runBulk(ctx, len(items), atomic, itemPath("items"), func(i int) (*app.ResumeOp, error) {
+		pk, err := parseID("id", typeResume, items[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		return &app.ResumeOp{Update: &app.UpdateResumeOp{ID: pk, Params: *updateResumeParams(items[i].Input)}}, nil
+	}, r.sendResumes(ctx, atomic, func(i int, res *app.ResumeResult) {
+		resumes[i] = res.Resume
+		invalidate(ctx, typeResume, res.Resume.ID)
+	}))
+	if err != nil {
 		return nil, err
 	}
-	if input.Title != nil {
-		resume.Title = *input.Title
+	return &model.UpdateResumesPayload{Resumes: resumes, UserErrors: userErrors, ClientMutationID: clientMutationID}, nil
+}
+
+// UpdateUser is the resolver for the updateUser field.
+func (r *mutationResolver) UpdateUser(ctx context.Context, id string, input model.UpdateUserInput) (*app.User, error) {
+	userID, err := parseID("id", typeUser, id)
+	if err != nil {
+		return nil, err
 	}
-	if input.Description != nil {
-		resume.Description = *input.Description
+	user, err := app.UpdateUser(ctx, siteOf(ctx), userID, updateUserParams(&input))
+	if err != nil {
+		return nil, r.conflict(ctx, err, typeUser, userID)
 	}
-	if input.Category != nil {
-		resume.Category = *input.Category
+	invalidate(ctx, typeUser, userID)
+	return user, nil
+}
+
+// UpdateUsers is the resolver for the updateUsers field.
+func (r *mutationResolver) UpdateUsers(ctx context.Context, items []*model.UpdateUserItem, atomic bool, clientMutationID *string) (*model.UpdateUsersPayload, error) {
+	users := make([]*app.User, len(items))
+	_, userErrors, err := runBulk(ctx, len(items), atomic, itemPath("items"), func(i int) (*app.UserOp, error) {
+		pk, err := parseID("id", typeUser, items[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		return &app.UserOp{Update: &app.UpdateUserOp{ID: pk, Params: *updateUserParams(items[i].Input)}}, nil
+	}, r.sendUsers(ctx, atomic, func(i int, res *app.UserResult) {
+		users[i] = res.User
+		invalidate(ctx, typeUser, res.User.ID)
+	}))
+	if err != nil {
+		return nil, err
 	}
-	if err := r.db.Save(&resume).Error; err != nil {
+	return &model.UpdateUsersPayload{Users: users, UserErrors: userErrors, ClientMutationID: clientMutationID}, nil
+}
+
+// UpsertBlogTranslation is the resolver for the upsertBlogTranslation field.
+func (r *mutationResolver) UpsertBlogTranslation(ctx context.Context, blogID string, input model.BlogTranslationInput, clientMutationID *string) (*model.UpsertBlogTranslationPayload, error) {
+	payload := &model.UpsertBlogTranslationPayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() error {
+		pk, err := parseID("blogId", typeBlog, blogID)
+		if err != nil {
+			return err
+		}
+		locale, err := translationLocale(input.Locale)
+		if err != nil {
+			return err
+		}
+		t, err := app.UpsertBlogTranslation(ctx, siteOf(ctx), pk, locale, &app.UpsertBlogTranslationParams{Title: input.Title, Content: input.Content})
+		if err != nil {
+			return err
+		}
+		invalidate(ctx, typeBlog, pk)
+		payload.Translation = t
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
-	return &resume, nil
+	payload.UserErrors = userErrors
+	return payload, nil
 }
 
-// UpdateUser is the resolver for the updateUser field.
-func (r *mutationResolver) UpdateUser(ctx context.Context, id string, input model.UpdateUserInput) (*app.User, error) {
-	userID, err := strconv.ParseUint(id, 10, 64)
+// UpsertProjectTranslation is the resolver for the upsertProjectTranslation field.
+func (r *mutationResolver) UpsertProjectTranslation(ctx context.Context, projectID string, input model.ProjectTranslationInput, clientMutationID *string) (*model.UpsertProjectTranslationPayload, error) {
+	payload := &model.UpsertProjectTranslationPayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() error {
+		pk, err := parseID("projectId", typeProject, projectID)
+		if err != nil {
+			return err
+		}
+		locale, err := translationLocale(input.Locale)
+		if err != nil {
+			return err
+		}
+		t, err := app.UpsertProjectTranslation(ctx, siteOf(ctx), pk, locale, &app.UpsertProjectTranslationParams{Title: input.Title, Description: input.Description})
+		if err != nil {
+			return err
+		}
+		invalidate(ctx, typeProject, pk)
+		payload.Translation = t
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	var user app.User
-	if err := r.db.First(&user, userID).Error; err != nil {
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
+// UpsertResumeTranslation is the resolver for the upsertResumeTranslation field.
+func (r *mutationResolver) UpsertResumeTranslation(ctx context.Context, resumeID string, input model.ResumeTranslationInput, clientMutationID *string) (*model.UpsertResumeTranslationPayload, error) {
+	payload := &model.UpsertResumeTranslationPayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() error {
+		pk, err := parseID("resumeId", typeResume, resumeID)
+		if err != nil {
+			return err
+		}
+		locale, err := translationLocale(input.Locale)
+		if err != nil {
+			return err
+		}
+		t, err := app.UpsertResumeTranslation(ctx, siteOf(ctx), pk, locale, &app.UpsertResumeTranslationParams{Title: input.Title, Description: input.Description})
+		if err != nil {
+			return err
+		}
+		invalidate(ctx, typeResume, pk)
+		payload.Translation = t
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
-	if input.Name != nil {
-		user.Name = *input.Name
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
+// UserCreate is the resolver for the userCreate field.
+func (r *mutationResolver) UserCreate(ctx context.Context, input model.CreateUserInput, clientMutationID *string) (*model.CreateUserPayload, error) {
+	payload := &model.CreateUserPayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() (err error) {
+		payload.User, err = r.CreateUser(ctx, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
-	if input.Email != nil {
-		user.Email = *input.Email
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
+// UserDelete is the resolver for the userDelete field.
+func (r *mutationResolver) UserDelete(ctx context.Context, id string, strategy model.DeleteUserStrategy, toUserID *string, clientMutationID *string) (*model.DeleteUserPayload, error) {
+	payload := &model.DeleteUserPayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() error {
+		if _, err := r.DeleteUser(ctx, id, strategy, toUserID); err != nil {
+			return err
+		}
+		payload.DeletedID = &id
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	if err := r.db.Save(&user).Error; err != nil {
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
+// UserUpdate is the resolver for the userUpdate field.
+func (r *mutationResolver) UserUpdate(ctx context.Context, id string, input model.UpdateUserInput, clientMutationID *string) (*model.UpdateUserPayload, error) {
+	payload := &model.UpdateUserPayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() (err error) {
+		payload.User, err = r.UpdateUser(ctx, id, input)
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
-	return &user, nil
+	payload.UserErrors = userErrors
+	return payload, nil
+}
+
+// Description is the resolver for the description field.
+func (r *projectResolver) Description(ctx context.Context, obj *app.Project, locale *string) (string, error) {
+	_, description, err := r.projectText(ctx, obj, locale)
+	return description, err
 }
 
 // ID is the resolver for the id field.
 func (r *projectResolver) ID(ctx context.Context, obj *app.Project) (string, error) {
-	return strconv.FormatUint(uint64(obj.ID), 10), nil
+	return globalID(typeProject, obj.ID), nil
+}
+
+// Title is the resolver for the title field.
+func (r *projectResolver) Title(ctx context.Context, obj *app.Project, locale *string) (string, error) {
+	title, _, err := r.projectText(ctx, obj, locale)
+	return title, err
+}
+
+// Translations is the resolver for the translations field.
+func (r *projectResolver) Translations(ctx context.Context, obj *app.Project) ([]*app.ProjectTranslation, error) {
+	translations, err := loadersOf(ctx).projectTranslations.load(ctx, localesBatchKey(nil), obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	if translations == nil {
+		translations = []*app.ProjectTranslation{}
+	}
+	return translations, nil
 }
 
 // User is the resolver for the user field.
 func (r *projectResolver) User(ctx context.Context, obj *app.Project) (*app.User, error) {
-	var user app.User
-	if err := r.db.First(&user, obj.UserID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil
-		}
-		return nil, err
+	user, err := app.GetUser(ctx, siteOf(ctx), obj.UserID)
+	if isNotFound(err) {
+		return nil, nil
 	}
-	return &user, nil
+	return user, err
 }
 
 // UserID is the resolver for the userID field.
 func (r *projectResolver) UserID(ctx context.Context, obj *app.Project) (string, error) {
-	return strconv.FormatUint(uint64(obj.UserID), 10), nil
+	return globalID(typeUser, obj.UserID), nil
+}
+
+// ViewCount is the resolver for the viewCount field.
+func (r *projectResolver) ViewCount(ctx context.Context, obj *app.Project) (int, error) {
+	return viewCount(ctx, typeProject, obj.ID)
+}
+
+// APIKeys is the resolver for the apiKeys field.
+func (r *queryResolver) APIKeys(ctx context.Context) ([]*app.ApiKey, error) {
+	resp, err := app.ListApiKeys(ctx, siteOf(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return resp.ApiKeys, nil
 }
 
 // Blog is the resolver for the blog field.
-func (r *queryResolver) Blog(ctx context.Context, id string) (*app.Blog, error) {
-	blogID, err := strconv.ParseUint(id, 10, 64)
+func (r *queryResolver) Blog(ctx context.Context, id string, previewToken *string) (*app.Blog, error) {
+	blogID, err := parseID("id", typeBlog, id)
 	if err != nil {
 		return nil, err
 	}
-	var blog app.Blog
-	if err := r.db.First(&blog, blogID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil
-		}
+	preview, err := previewing(ctx, previewToken, typeBlog, blogID)
+	if err != nil {
 		return nil, err
 	}
-	return &blog, nil
+	blog, err := app.GetBlog(ctx, siteOf(ctx), blogID, &app.GetBlogParams{IncludeDrafts: preview || seesDrafts(ctx)})
+	if isNotFound(err) {
+		return nil, nil
+	}
+	return blog, err
 }
 
 // Blogs is the resolver for the blogs field.
 func (r *queryResolver) Blogs(ctx context.Context) ([]*app.Blog, error) {
-	var blogs []*app.Blog
-	if err := r.db.Find(&blogs).Error; err != nil {
+	resp, err := app.ListBlogs(ctx, siteOf(ctx), &app.ListBlogsParams{IncludeDrafts: seesDrafts(ctx)})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Blogs, nil
+}
+
+// ContactFormToken is the resolver for the contactFormToken field.
+func (r *queryResolver) ContactFormToken(ctx context.Context) (string, error) {
+	if secrets.ContactFormSecret == "" {
+		return "", &errs.Error{Code: errs.FailedPrecondition, Message: "the contact form is not configured"}
+	}
+	return contactFormToken(siteOf(ctx), time.Now()), nil
+}
+
+// ContactMessages is the resolver for the contactMessages field.
+func (r *queryResolver) ContactMessages(ctx context.Context, status *model.ContactMessageStatus, first *int) ([]*app.ContactMessage, error) {
+	limit, err := firstArg(first, 50)
+	if err != nil {
+		return nil, err
+	}
+	p := &app.ListContactMessagesParams{Limit: limit}
+	if status != nil {
+		p.Status = string(*status)
+	}
+	resp, err := app.ListContactMessages(ctx, siteOf(ctx), p)
+	if err != nil {
 		return nil, err
 	}
-	return blogs, nil
+	return resp.Messages, nil
+}
+
+// Node is the resolver for the node field.
+func (r *queryResolver) Node(ctx context.Context, id string) (model.Node, error) {
+	nodes, err := r.loadNodes(ctx, "id", []string{id})
+	if err != nil {
+		return nil, err
+	}
+	return nodes[0], nil
+}
+
+// Nodes is the resolver for the nodes field.
+func (r *queryResolver) Nodes(ctx context.Context, ids []string) ([]model.Node, error) {
+	return r.loadNodes(ctx, "ids", ids)
+}
+
+// PopularBlogs is the resolver for the popularBlogs field.
+func (r *queryResolver) PopularBlogs(ctx context.Context, period model.ViewPeriod, first *int) ([]*app.Blog, error) {
+	limit, err := firstArg(first, 10)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := app.PopularBlogs(ctx, siteOf(ctx), &app.PopularBlogsParams{
+		Since:         periodStart(period, time.Now()),
+		Limit:         limit,
+		IncludeDrafts: seesDrafts(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Blogs, nil
 }
 
 // Project is the resolver for the project field.
-func (r *queryResolver) Project(ctx context.Context, id string) (*app.Project, error) {
-	projectID, err := strconv.ParseUint(id, 10, 64)
+func (r *queryResolver) Project(ctx context.Context, id string, previewToken *string) (*app.Project, error) {
+	projectID, err := parseID("id", typeProject, id)
 	if err != nil {
 		return nil, err
 	}
-	var project app.Project
-	if err := r.db.First(&project, projectID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil
-		}
+	preview, err := previewing(ctx, previewToken, typeProject, projectID)
+	if err != nil {
 		return nil, err
 	}
-	return &project, nil
+	project, err := app.GetProject(ctx, siteOf(ctx), projectID, &app.GetProjectParams{IncludeHidden: preview || seesHidden(ctx)})
+	if isNotFound(err) {
+		return nil, nil
+	}
+	return project, err
 }
 
 // Projects is the resolver for the projects field.
 func (r *queryResolver) Projects(ctx context.Context) ([]*app.Project, error) {
-	var projects []*app.Project
-	if err := r.db.Find(&projects).Error; err != nil {
+	resp, err := app.ListProjects(ctx, siteOf(ctx), &app.ListProjectsParams{IncludeHidden: seesHidden(ctx)})
+	if err != nil {
 		return nil, err
 	}
-	return projects, nil
+	return resp.Projects, nil
 }
 
 // Resume is the resolver for the resume field.
 func (r *queryResolver) Resume(ctx context.Context, id string) (*app.Resume, error) {
-	resumeID, err := strconv.ParseUint(id, 10, 64)
+	resumeID, err := parseID("id", typeResume, id)
 	if err != nil {
 		return nil, err
 	}
-	var resume app.Resume
-	if err := r.db.First(&resume, resumeID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil
-		}
-		return nil, err
+	resume, err := app.GetResume(ctx, siteOf(ctx), resumeID)
+	if isNotFound(err) {
+		return nil, nil
 	}
-	return &resume, nil
+	return resume, err
 }
 
 // Resumes is the resolver for the resumes field.
 func (r *queryResolver) Resumes(ctx context.Context) ([]*app.Resume, error) {
-	var resumes []*app.Resume
-	if err := r.db.Find(&resumes).Error; err != nil {
+	resp, err := app.ListResumes(ctx, siteOf(ctx), &app.ListResumesParams{})
+	if err != nil {
 		return nil, err
 	}
-	return resumes, nil
+	return resp.Resumes, nil
 }
 
 // User is the resolver for the user field.
 func (r *queryResolver) User(ctx context.Context, id string) (*app.User, error) {
-	userID, err := strconv.ParseUint(id, 10, 64)
+	userID, err := parseID("id", typeUser, id)
 	if err != nil {
 		return nil, err
 	}
-	var user app.User
-	if err := r.db.First(&user, userID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil
-		}
-		return nil, err
+	user, err := app.GetUser(ctx, siteOf(ctx), userID)
+	if isNotFound(err) {
+		return nil, nil
 	}
-	return &user, nil
+	return user, err
 }
 
 // Users is the resolver for the users field.
 func (r *queryResolver) Users(ctx context.Context) ([]*app.User, error) {
-	var users []*app.User
-	if err := r.db.Find(&users).Error; err != nil {
+	resp, err := app.ListUsers(ctx, siteOf(ctx), &app.ListUsersParams{})
+	if err != nil {
 		return nil, err
 	}
-	return users, nil
+	return resp.Users, nil
+}
+
+// ViewStats is the resolver for the viewStats field.
+func (r *queryResolver) ViewStats(ctx context.Context, id string, from time.Time, to time.Time) (*model.ViewStats, error) {
+	typ, pk, ok := decodeGlobalID(id)
+	if !ok || (typ != typeBlog && typ != typeProject) {
+		return nil, invalidArgument("id", "id must be the ID of a Blog or Project")
+	}
+	return viewStats(ctx, typ, pk, from, to)
+}
+
+// WebhookDeliveries is the resolver for the webhookDeliveries field.
+func (r *queryResolver) WebhookDeliveries(ctx context.Context, webhookID *string, status *model.WebhookDeliveryStatus, first *int) ([]*app.WebhookDelivery, error) {
+	limit, err := firstArg(first, 50)
+	if err != nil {
+		return nil, err
+	}
+	p := &app.ListWebhookDeliveriesParams{Limit: limit}
+	if webhookID != nil {
+		if p.WebhookID, err = parseID("webhookId", typeWebhook, *webhookID); err != nil {
+			return nil, err
+		}
+	}
+	if status != nil {
+		p.Status = string(*status)
+	}
+	resp, err := app.ListWebhookDeliveries(ctx, siteOf(ctx), p)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Deliveries, nil
+}
+
+// Webhooks is the resolver for the webhooks field.
+func (r *queryResolver) Webhooks(ctx context.Context) ([]*app.Webhook, error) {
+	resp, err := app.ListWebhooks(ctx, siteOf(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return resp.Webhooks, nil
+}
+
+// Description is the resolver for the description field.
+func (r *resumeResolver) Description(ctx context.Context, obj *app.Resume, locale *string) (string, error) {
+	_, description, err := r.resumeText(ctx, obj, locale)
+	return description, err
 }
 
 // ID is the resolver for the id field.
 func (r *resumeResolver) ID(ctx context.Context, obj *app.Resume) (string, error) {
-	return strconv.FormatUint(uint64(obj.ID), 10), nil
+	return globalID(typeResume, obj.ID), nil
 }
 
-// CreatedAt is the resolver for the createdAt field.
-func (r *userResolver) CreatedAt(ctx context.Context, obj *app.User) (string, error) {
-	return obj.CreatedAt.Format(time.RFC3339), nil
+// Title is the resolver for the title field.
+func (r *resumeResolver) Title(ctx context.Context, obj *app.Resume, locale *string) (string, error) {
+	title, _, err := r.resumeText(ctx, obj, locale)
+	return title, err
+}
+
+// Translations is the resolver for the translations field.
+func (r *resumeResolver) Translations(ctx context.Context, obj *app.Resume) ([]*app.ResumeTranslation, error) {
+	translations, err := loadersOf(ctx).resumeTranslations.load(ctx, localesBatchKey(nil), obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	if translations == nil {
+		translations = []*app.ResumeTranslation{}
+	}
+	return translations, nil
+}
+
+// ReactionsChanged is the resolver for the reactionsChanged field.
+func (r *subscriptionResolver) ReactionsChanged(ctx context.Context, blogID string) (<-chan []*model.Reaction, error) {
+	pk, err := parseID("blogId", typeBlog, blogID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireVisible(ctx, typeBlog, pk); err != nil {
+		return nil, err
+	}
+	out := make(chan []*model.Reaction, 1)
+	go r.watchReactions(ctx, pk, reactorFor(ctx), out, time.Duration(cfg.Reactions.PollSeconds())*time.Second)
+	return out, nil
 }
 
 // ID is the resolver for the id field.
 func (r *userResolver) ID(ctx context.Context, obj *app.User) (string, error) {
-	return strconv.FormatUint(uint64(obj.ID), 10), nil
+	return globalID(typeUser, obj.ID), nil
 }
 
 // Projects is the resolver for the projects field.
 func (r *userResolver) Projects(ctx context.Context, obj *app.User) ([]*app.Project, error) {
-	var projects []*app.Project
-	if err := r.db.Where("user_id = ?", obj.ID).Find(&projects).Error; err != nil {
+	resp, err := app.ListProjects(ctx, siteOf(ctx), &app.ListProjectsParams{UserID: obj.ID, IncludeHidden: seesHidden(ctx)})
+	if err != nil {
 		return nil, err
 	}
-	return projects, nil
+	return resp.Projects, nil
+}
+
+// ID is the resolver for the id field.
+func (r *webhookDeliveryResolver) ID(ctx context.Context, obj *app.WebhookDelivery) (string, error) {
+	return globalID(typeWebhookDelivery, obj.ID), nil
+}
+
+// Status is the resolver for the status field.
+func (r *webhookDeliveryResolver) Status(ctx context.Context, obj *app.WebhookDelivery) (model.WebhookDeliveryStatus, error) {
+	return model.WebhookDeliveryStatus(obj.Status), nil
 }
 
+// WebhookID is the resolver for the webhookId field.
+func (r *webhookDeliveryResolver) WebhookID(ctx context.Context, obj *app.WebhookDelivery) (string, error) {
+	return globalID(typeWebhook, obj.WebhookID), nil
+}
+
+// Events is the resolver for the events field.
+func (r *webhookResolver) Events(ctx context.Context, obj *app.Webhook) ([]string, error) {
+	return strings.Fields(obj.Events), nil
+}
+
+// ID is the resolver for the id field.
+func (r *webhookResolver) ID(ctx context.Context, obj *app.Webhook) (string, error) {
+	return globalID(typeWebhook, obj.ID), nil
+}
+
+// ApiKey returns generated.ApiKeyResolver implementation.
+func (r *Resolver) ApiKey() generated.ApiKeyResolver { return &apiKeyResolver{r} }
+
 // Blog returns generated.BlogResolver implementation.
 func (r *Resolver) Blog() generated.BlogResolver { return &blogResolver{r} }
 
+// ContactMessage returns generated.ContactMessageResolver implementation.
+func (r *Resolver) ContactMessage() generated.ContactMessageResolver { return &contactMessageResolver{r} }
+
 // Mutation returns generated.MutationResolver implementation.
 func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
 
@@ -389,12 +1395,28 @@ func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
 // Resume returns generated.ResumeResolver implementation.
 func (r *Resolver) Resume() generated.ResumeResolver { return &resumeResolver{r} }
 
+// Subscription returns generated.SubscriptionResolver implementation.
+func (r *Resolver) Subscription() generated.SubscriptionResolver { return &subscriptionResolver{r} }
+
 // User returns generated.UserResolver implementation.
 func (r *Resolver) User() generated.UserResolver { return &userResolver{r} }
 
+// Webhook returns generated.WebhookResolver implementation.
+func (r *Resolver) Webhook() generated.WebhookResolver { return &webhookResolver{r} }
+
+// WebhookDelivery returns generated.WebhookDeliveryResolver implementation.
+func (r *Resolver) WebhookDelivery() generated.WebhookDeliveryResolver {
+	return &webhookDeliveryResolver{r}
+}
+
+type apiKeyResolver struct{ *Resolver }
 type blogResolver struct{ *Resolver }
+type contactMessageResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
 type projectResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
 type resumeResolver struct{ *Resolver }
-type userResolver struct{ *Resolver }
\ No newline at end of file
+type subscriptionResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
+type webhookDeliveryResolver struct{ *Resolver }
+type webhookResolver struct{ *Resolver }
\ No newline at end of file
@@ -0,0 +1,274 @@
+package graphql
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"encore.app/app"
+	"encore.app/graphql/model"
+	"encore.dev/beta/auth"
+	"encore.dev/beta/errs"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/plugin/federation/fedruntime"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+var secrets struct {
+	// AdminToken grants every scope on every site. It is meant for creating
+	// the first API keys.
+	AdminToken string
+	// ContactFormSecret signs the tokens the contact form is submitted with.
+	ContactFormSecret string
+	// CaptchaSecret authenticates the service with the CAPTCHA provider of
+	// the contact form.
+	CaptchaSecret string
+	// NewsletterSecret signs the confirmation and unsubscribe links of the
+	// newsletter.
+	NewsletterSecret string
+	// SMTPPassword authenticates with the SMTP server of the "smtp" mailer.
+	SMTPPassword string
+	// FingerprintSecret keys the fingerprints that tell anonymous visitors
+	// apart when they react to blog posts.
+	FingerprintSecret string
+	// PreviewSecret signs the preview links of drafts and hidden projects.
+	PreviewSecret string
+}
+
+// AuthData describes the caller authenticated by AuthHandler.
+type AuthData struct {
+	// KeyID is the API key that was used, or 0 for the admin token.
+	KeyID uint
+	// SiteID is the site the API key belongs to, or 0 for the admin token,
+	// which is valid for every site.
+	SiteID uint
+	Scopes []string
+}
+
+const (
+	// apiKeyPrefix starts every API key, so that leaked keys are easy to spot.
+	apiKeyPrefix = "pk_"
+	// apiKeyShown is the length of the prefix stored to tell keys apart.
+	apiKeyShown = 10
+	// scopeAll is held by the admin token and satisfies every scope.
+	scopeAll = "*"
+)
+
+// AuthHandler authenticates `Authorization: Bearer` tokens, which are either
+// API keys or the admin token.
+//
+//encore:authhandler
+func (s *Service) AuthHandler(ctx context.Context, token string) (auth.UID, *AuthData, error) {
+	if secrets.AdminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(secrets.AdminToken)) == 1 {
+		return "admin", &AuthData{Scopes: []string{scopeAll}}, nil
+	}
+	key, err := app.AuthenticateApiKey(ctx, &app.AuthenticateApiKeyParams{Hash: hashAPIKey(token)})
+	if err != nil {
+		return "", nil, err
+	}
+	uid := auth.UID("apikey:" + strconv.FormatUint(uint64(key.ID), 10))
+	return uid, &AuthData{KeyID: key.ID, SiteID: key.SiteID, Scopes: splitScopes(key.Scopes)}, nil
+}
+
+// newAPIKey returns a fresh secret key.
+func newAPIKey() string {
+	var b [32]byte
+	rand.Read(b[:])
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b[:])
+}
+
+func hashAPIKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func splitScopes(s string) []string {
+	return strings.Fields(s)
+}
+
+// principal is the caller of the request being served and the scopes it holds.
+type principal struct {
+	authenticated bool
+	uid           auth.UID
+	scopes        []string
+}
+
+type principalKey struct{}
+
+// principalFor returns the caller of a request for site: the authenticated API
+// key or admin, or else an anonymous caller with the configured
+// AnonymousScopes.
+func principalFor(site *app.Site) (*principal, error) {
+	data, ok := auth.Data().(*AuthData)
+	if !ok {
+		return &principal{scopes: cfg.AnonymousScopes()}, nil
+	}
+	if data.SiteID != 0 && data.SiteID != site.ID {
+		return nil, &errs.Error{Code: errs.PermissionDenied, Message: "API key belongs to another site"}
+	}
+	uid, _ := auth.UserID()
+	return &principal{authenticated: true, uid: uid, scopes: data.Scopes}, nil
+}
+
+// principalFrom returns the caller stored in ctx. Requests that did not come
+// through Service.Query hold no scopes at all.
+func principalFrom(ctx context.Context) *principal {
+	if p, ok := ctx.Value(principalKey{}).(*principal); ok {
+		return p
+	}
+	return &principal{}
+}
+
+func (p *principal) has(scope string) bool {
+	return slices.Contains(p.scopes, scope) || slices.Contains(p.scopes, scopeAll)
+}
+
+// require returns UNAUTHENTICATED or FORBIDDEN unless p holds scope.
+func (p *principal) require(scope string) error {
+	switch {
+	case p.has(scope):
+		return nil
+	case !p.authenticated:
+		return &errs.Error{Code: errs.Unauthenticated, Message: fmt.Sprintf("authentication with the %s scope is required", scope)}
+	default:
+		return &errs.Error{Code: errs.PermissionDenied, Message: fmt.Sprintf("missing the %s scope", scope)}
+	}
+}
+
+// authorize returns a field middleware that enforces the @hasScope directives
+// of schema. Fields are checked against their own directive and against the
+// directive of the object type they return. Objects returned through an
+// interface or union, such as Query.node or Query._entities, are checked once
+// they are resolved.
+func authorize(schema *ast.Schema) graphql.FieldMiddleware {
+	objects := make(map[string]string)
+	for name, def := range schema.Types {
+		if d := def.Directives.ForName("hasScope"); d != nil {
+			objects[name] = scopeArg(d)
+		}
+	}
+	return func(ctx context.Context, next graphql.Resolver) (any, error) {
+		fc := graphql.GetFieldContext(ctx)
+		if fc == nil || fc.Field.Definition == nil {
+			return next(ctx)
+		}
+		p := principalFrom(ctx)
+		if d := fc.Field.Definition.Directives.ForName("hasScope"); d != nil {
+			if err := p.require(scopeArg(d)); err != nil {
+				return nil, err
+			}
+		}
+		typ := fc.Field.Definition.Type.Name()
+		if scope, ok := objects[typ]; ok {
+			if err := p.require(scope); err != nil {
+				return nil, err
+			}
+			return next(ctx)
+		}
+		if def := schema.Types[typ]; def == nil || !def.IsAbstractType() {
+			return next(ctx)
+		}
+		res, err := next(ctx)
+		if err != nil {
+			return res, err
+		}
+		return p.filterNodes(ctx, objects, res)
+	}
+}
+
+// filterNodes drops the nodes of res, a Node, a list of them or a list of
+// federation entities, whose type requires a scope p does not hold. A single
+// node is replaced by the error, a list reports it once.
+func (p *principal) filterNodes(ctx context.Context, objects map[string]string, res any) (any, error) {
+	check := func(n model.Node) error {
+		if scope, ok := objects[nodeTypeName(n)]; ok {
+			return p.require(scope)
+		}
+		return nil
+	}
+	switch res := res.(type) {
+	case []model.Node:
+		return filterList(ctx, res, check), nil
+	case []fedruntime.Entity:
+		return filterList(ctx, res, check), nil
+	default:
+		if err := check(res); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// filterList replaces the elements of list that fail check by nil, and adds
+// the first failure to the response.
+func filterList[T any](ctx context.Context, list []T, check func(model.Node) error) []T {
+	var (
+		zero  T
+		first error
+	)
+	for i, n := range list {
+		if any(n) == nil {
+			continue
+		}
+		if err := check(n); err != nil {
+			list[i] = zero
+			if first == nil {
+				first = err
+			}
+		}
+	}
+	if first != nil {
+		graphql.AddError(ctx, first)
+	}
+	return list
+}
+
+// nodeTypeName returns the GraphQL type name of a resolved node.
+func nodeTypeName(n model.Node) string {
+	switch n.(type) {
+	case *app.User:
+		return typeUser
+	case *app.Project:
+		return typeProject
+	case *app.Blog:
+		return typeBlog
+	case *app.Resume:
+		return typeResume
+	}
+	typ, _, _ := crudNode(n)
+	return typ
+}
+
+func scopeArg(d *ast.Directive) string {
+	scope, _ := d.ArgumentMap(nil)["scope"].(string)
+	return scope
+}
+
+// schemaScopes lists every scope used by the @hasScope directives of schema.
+func schemaScopes(schema *ast.Schema) []string {
+	seen := make(map[string]bool)
+	for _, def := range schema.Types {
+		if d := def.Directives.ForName("hasScope"); d != nil {
+			seen[scopeArg(d)] = true
+		}
+		for _, f := range def.Fields {
+			if d := f.Directives.ForName("hasScope"); d != nil {
+				seen[scopeArg(d)] = true
+			}
+		}
+	}
+	scopes := make([]string, 0, len(seen))
+	for s := range seen {
+		scopes = append(scopes, s)
+	}
+	sort.Strings(scopes)
+	return scopes
+}
@@ -0,0 +1,356 @@
+package graphql
+
+import (
+	"context"
+	"strconv"
+
+	"encore.app/app"
+	"encore.app/graphql/model"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// runBulk runs a bulk mutation of n items as one batch of the app service,
+// which commits every item that succeeds, or none of them with atomic set.
+//
+// op builds the operation of item i. Items whose arguments failed validation,
+// or whose operation cannot be built, are not sent; with atomic set, the batch
+// is not sent at all then. send runs the operations of the given items and
+// returns the failure of each, in the same order, and whether the others were
+// committed.
+//
+// Expected failures are returned as userErrors located by path; unexpected
+// errors abort the mutation. done reports which items were committed.
+func runBulk[Op any](ctx context.Context, n int, atomic bool, path func(i int, field string) []string, op func(i int) (Op, error), send func(items []int, ops []Op) ([]error, bool, error)) (done []bool, userErrors []*model.UserError, err error) {
+	userErrors = []*model.UserError{}
+	skip := make([]bool, n)
+	for _, v := range violationsFrom(ctx) {
+		if len(v.path) > 1 {
+			if i, ok := v.path[1].(ast.PathIndex); ok {
+				skip[i] = true
+			}
+		}
+		userErrors = append(userErrors, v.userError())
+	}
+
+	var (
+		items []int
+		ops   []Op
+	)
+	for i := 0; i < n; i++ {
+		if skip[i] {
+			continue
+		}
+		o, err := op(i)
+		if err != nil {
+			ce, ok := classify(err)
+			if !ok {
+				return nil, nil, err
+			}
+			userErrors = append(userErrors, ce.userError(path(i, ce.field)))
+			continue
+		}
+		items = append(items, i)
+		ops = append(ops, o)
+	}
+	done = make([]bool, n)
+	if len(ops) == 0 || atomic && len(userErrors) > 0 {
+		return done, userErrors, nil
+	}
+
+	failures, committed, err := send(items, ops)
+	if err != nil {
+		return nil, nil, err
+	}
+	for k, i := range items {
+		if failures[k] != nil {
+			ce, ok := classify(failures[k])
+			if !ok {
+				return nil, nil, failures[k]
+			}
+			userErrors = append(userErrors, ce.userError(path(i, ce.field)))
+			continue
+		}
+		done[i] = committed
+	}
+	return done, userErrors, nil
+}
+
+// sendUsers returns the send function of runBulk for user operations. keep
+// is called with the result of every committed item.
+func (r *Resolver) sendUsers(ctx context.Context, atomic bool, keep func(i int, res *app.UserResult)) func([]int, []*app.UserOp) ([]error, bool, error) {
+	return func(items []int, ops []*app.UserOp) ([]error, bool, error) {
+		resp, err := app.BatchUsers(ctx, siteOf(ctx), &app.BatchUsersParams{Ops: ops, Atomic: atomic})
+		if err != nil {
+			return nil, false, err
+		}
+		failures := make([]error, len(ops))
+		for k, res := range resp.Results {
+			switch {
+			case res.Error != nil && ops[k].Update != nil:
+				failures[k] = r.conflict(ctx, res.Error.Err(), typeUser, ops[k].Update.ID)
+			case res.Error != nil:
+				failures[k] = res.Error.Err()
+			case resp.Committed:
+				keep(items[k], res)
+			}
+		}
+		return failures, resp.Committed, nil
+	}
+}
+
+// sendProjects is sendUsers for projects.
+func (r *Resolver) sendProjects(ctx context.Context, atomic bool, keep func(i int, res *app.ProjectResult)) func([]int, []*app.ProjectOp) ([]error, bool, error) {
+	return func(items []int, ops []*app.ProjectOp) ([]error, bool, error) {
+		resp, err := app.BatchProjects(ctx, siteOf(ctx), &app.BatchProjectsParams{Ops: ops, Atomic: atomic})
+		if err != nil {
+			return nil, false, err
+		}
+		failures := make([]error, len(ops))
+		for k, res := range resp.Results {
+			switch {
+			case res.Error != nil && ops[k].Update != nil:
+				failures[k] = r.conflict(ctx, res.Error.Err(), typeProject, ops[k].Update.ID)
+			case res.Error != nil:
+				failures[k] = res.Error.Err()
+			case resp.Committed:
+				keep(items[k], res)
+			}
+		}
+		return failures, resp.Committed, nil
+	}
+}
+
+// sendBlogs is sendUsers for blog posts.
+func (r *Resolver) sendBlogs(ctx context.Context, atomic bool, keep func(i int, res *app.BlogResult)) func([]int, []*app.BlogOp) ([]error, bool, error) {
+	return func(items []int, ops []*app.BlogOp) ([]error, bool, error) {
+		resp, err := app.BatchBlogs(ctx, siteOf(ctx), &app.BatchBlogsParams{Ops: ops, Atomic: atomic})
+		if err != nil {
+			return nil, false, err
+		}
+		failures := make([]error, len(ops))
+		for k, res := range resp.Results {
+			switch {
+			case res.Error != nil && ops[k].Update != nil:
+				failures[k] = r.conflict(ctx, res.Error.Err(), typeBlog, ops[k].Update.ID)
+			case res.Error != nil:
+				failures[k] = res.Error.Err()
+			case resp.Committed:
+				keep(items[k], res)
+			}
+		}
+		return failures, resp.Committed, nil
+	}
+}
+
+// sendResumes is sendUsers for resume sections.
+func (r *Resolver) sendResumes(ctx context.Context, atomic bool, keep func(i int, res *app.ResumeResult)) func([]int, []*app.ResumeOp) ([]error, bool, error) {
+	return func(items []int, ops []*app.ResumeOp) ([]error, bool, error) {
+		resp, err := app.BatchResumes(ctx, siteOf(ctx), &app.BatchResumesParams{Ops: ops, Atomic: atomic})
+		if err != nil {
+			return nil, false, err
+		}
+		failures := make([]error, len(ops))
+		for k, res := range resp.Results {
+			switch {
+			case res.Error != nil && ops[k].Update != nil:
+				failures[k] = r.conflict(ctx, res.Error.Err(), typeResume, ops[k].Update.ID)
+			case res.Error != nil:
+				failures[k] = res.Error.Err()
+			case resp.Committed:
+				keep(items[k], res)
+			}
+		}
+		return failures, resp.Committed, nil
+	}
+}
+
+// inputPath locates fields of the i-th element of a list of inputs.
+func inputPath(arg string) func(i int, field string) []string {
+	return func(i int, field string) []string {
+		path := []string{arg, strconv.Itoa(i)}
+		if field != "" {
+			path = append(path, field)
+		}
+		return path
+	}
+}
+
+// itemPath locates fields of the i-th element of a list of update items,
+// whose changes are nested under "input".
+func itemPath(arg string) func(i int, field string) []string {
+	return func(i int, field string) []string {
+		path := []string{arg, strconv.Itoa(i)}
+		switch field {
+		case "", "id":
+			return append(path, "id")
+		default:
+			return append(path, "input", field)
+		}
+	}
+}
+
+// deleteTargets resolves the primary keys a bulk delete applies to: either
+// the given IDs, or every record listed by where. It also returns how to
+// locate a failure of the i-th target.
+func deleteTargets(typ string, ids []string, where func() ([]uint, error)) ([]uint, func(i int, field string) []string, error) {
+	switch {
+	case ids != nil && where != nil:
+		return nil, nil, invalidArgument("where", "provide either ids or where, not both")
+	case ids != nil:
+		pks := make([]uint, len(ids))
+		for i, id := range ids {
+			pk, err := parseID("ids", typ, id)
+			if err != nil {
+				return nil, nil, err
+			}
+			pks[i] = pk
+		}
+		return pks, func(i int, _ string) []string { return []string{"ids", strconv.Itoa(i)} }, nil
+	case where != nil:
+		pks, err := where()
+		if err != nil {
+			return nil, nil, err
+		}
+		return pks, func(int, string) []string { return []string{"where"} }, nil
+	default:
+		return nil, nil, invalidArgument("ids", "provide either ids or where")
+	}
+}
+
+// errEmptyFilter rejects a where without any condition, so that it never
+// matches every record.
+var errEmptyFilter = invalidArgument("where", "where must set at least one condition")
+
+func userFilter(ctx context.Context, where *model.UserFilter) func() ([]uint, error) {
+	if where == nil {
+		return nil
+	}
+	return func() ([]uint, error) {
+		if where.NameContains == nil && where.CreatedBefore == nil && where.CreatedAfter == nil {
+			return nil, errEmptyFilter
+		}
+		p := &app.ListUsersParams{}
+		if where.NameContains != nil {
+			p.NameContains = *where.NameContains
+		}
+		if where.CreatedBefore != nil {
+			p.CreatedBefore = *where.CreatedBefore
+		}
+		if where.CreatedAfter != nil {
+			p.CreatedAfter = *where.CreatedAfter
+		}
+		resp, err := app.ListUsers(ctx, siteOf(ctx), p)
+		if err != nil {
+			return nil, err
+		}
+		pks := make([]uint, len(resp.Users))
+		for i, u := range resp.Users {
+			pks[i] = u.ID
+		}
+		return pks, nil
+	}
+}
+
+func projectFilter(ctx context.Context, where *model.ProjectFilter) func() ([]uint, error) {
+	if where == nil {
+		return nil
+	}
+	return func() ([]uint, error) {
+		if where.UserID == nil && where.TitleContains == nil {
+			return nil, errEmptyFilter
+		}
+		p := &app.ListProjectsParams{IncludeHidden: true}
+		if where.UserID != nil {
+			userID, err := parseID("userID", typeUser, *where.UserID)
+			if err != nil {
+				return nil, err
+			}
+			p.UserID = userID
+		}
+		if where.TitleContains != nil {
+			p.TitleContains = *where.TitleContains
+		}
+		resp, err := app.ListProjects(ctx, siteOf(ctx), p)
+		if err != nil {
+			return nil, err
+		}
+		pks := make([]uint, len(resp.Projects))
+		for i, project := range resp.Projects {
+			pks[i] = project.ID
+		}
+		return pks, nil
+	}
+}
+
+func blogFilter(ctx context.Context, where *model.BlogFilter) func() ([]uint, error) {
+	if where == nil {
+		return nil
+	}
+	return func() ([]uint, error) {
+		if where.TitleContains == nil && where.CreatedBefore == nil && where.CreatedAfter == nil {
+			return nil, errEmptyFilter
+		}
+		p := &app.ListBlogsParams{IncludeDrafts: true}
+		if where.TitleContains != nil {
+			p.TitleContains = *where.TitleContains
+		}
+		if where.CreatedBefore != nil {
+			p.CreatedBefore = *where.CreatedBefore
+		}
+		if where.CreatedAfter != nil {
+			p.CreatedAfter = *where.CreatedAfter
+		}
+		resp, err := app.ListBlogs(ctx, siteOf(ctx), p)
+		if err != nil {
+			return nil, err
+		}
+		pks := make([]uint, len(resp.Blogs))
+		for i, b := range resp.Blogs {
+			pks[i] = b.ID
+		}
+		return pks, nil
+	}
+}
+
+func resumeFilter(ctx context.Context, where *model.ResumeFilter) func() ([]uint, error) {
+	if where == nil {
+		return nil
+	}
+	return func() ([]uint, error) {
+		if where.Category == nil && where.TitleContains == nil {
+			return nil, errEmptyFilter
+		}
+		p := &app.ListResumesParams{}
+		if where.Category != nil {
+			if *where.Category == "" {
+				// Every section has a category, and the service takes an
+				// empty one as no condition.
+				return []uint{}, nil
+			}
+			p.Category = *where.Category
+		}
+		if where.TitleContains != nil {
+			p.TitleContains = *where.TitleContains
+		}
+		resp, err := app.ListResumes(ctx, siteOf(ctx), p)
+		if err != nil {
+			return nil, err
+		}
+		pks := make([]uint, len(resp.Resumes))
+		for i, rs := range resp.Resumes {
+			pks[i] = rs.ID
+		}
+		return pks, nil
+	}
+}
+
+// deletedIDs lists the global IDs of the committed deletes.
+func deletedIDs(typ string, pks []uint, done []bool) []string {
+	ids := []string{}
+	for i, pk := range pks {
+		if done[i] {
+			ids = append(ids, globalID(typ, pk))
+		}
+	}
+	return ids
+}
@@ -0,0 +1,205 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"encore.app/graphql/model"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// cachePolicy is the Cache-Control policy of a response: the lowest maxAge of
+// all resolved fields, and private if any field is.
+type cachePolicy struct {
+	mu      sync.Mutex
+	maxAge  int
+	private bool
+	hinted  bool
+}
+
+type cachePolicyKey struct{}
+
+// restrict lowers the policy to a field's hint.
+func (p *cachePolicy) restrict(maxAge int, scope model.CacheControlScope) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.hinted || maxAge < p.maxAge {
+		p.maxAge = maxAge
+	}
+	p.hinted = true
+	if scope == model.CacheControlScopePrivate {
+		p.private = true
+	}
+}
+
+// markPrivate keeps shared caches from storing the response.
+func (p *cachePolicy) markPrivate() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.private = true
+}
+
+// shared returns the maxAge of the policy and whether shared caches may
+// store the response.
+func (p *cachePolicy) shared() (int, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.hinted {
+		return 0, false
+	}
+	return p.maxAge, !p.private
+}
+
+// header returns the Cache-Control value of the policy.
+func (p *cachePolicy) header() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.hinted || p.maxAge <= 0 {
+		return "no-store"
+	}
+	scope := "public"
+	if p.private {
+		scope = "private"
+	}
+	return "max-age=" + strconv.Itoa(p.maxAge) + ", " + scope
+}
+
+// cacheErrors is a response middleware that makes responses with errors
+// uncacheable.
+func cacheErrors(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	resp := next(ctx)
+	if policy, ok := ctx.Value(cachePolicyKey{}).(*cachePolicy); ok && resp != nil && len(resp.Errors) > 0 {
+		policy.restrict(0, "")
+	}
+	return resp
+}
+
+// cacheHint is a @cacheControl directive.
+type cacheHint struct {
+	maxAge *int
+	scope  model.CacheControlScope
+}
+
+func hintOf(directives ast.DirectiveList) (cacheHint, bool) {
+	d := directives.ForName("cacheControl")
+	if d == nil {
+		return cacheHint{}, false
+	}
+	args := d.ArgumentMap(nil)
+	var h cacheHint
+	if maxAge, ok := args["maxAge"].(int64); ok {
+		n := int(maxAge)
+		h.maxAge = &n
+	}
+	if scope, ok := args["scope"].(string); ok {
+		h.scope = model.CacheControlScope(scope)
+	}
+	return h, true
+}
+
+// cacheControl returns a field middleware that folds the @cacheControl hints
+// of every resolved field into the cachePolicy of the request.
+//
+// A field's hint is its own directive, or else that of the type it returns.
+// Root fields and fields returning objects, interfaces or lists of them
+// without a maxAge are uncacheable. Scalar fields without a hint don't
+// restrict the policy.
+func cacheControl(schema *ast.Schema) graphql.FieldMiddleware {
+	return func(ctx context.Context, next graphql.Resolver) (any, error) {
+		policy, ok := ctx.Value(cachePolicyKey{}).(*cachePolicy)
+		fc := graphql.GetFieldContext(ctx)
+		if !ok || fc == nil || fc.Field.Definition == nil {
+			return next(ctx)
+		}
+		typ := schema.Types[fc.Field.Definition.Type.Name()]
+		hint, _ := hintOf(fc.Field.Definition.Directives)
+		if typ != nil {
+			if typeHint, ok := hintOf(typ.Directives); ok {
+				if hint.maxAge == nil {
+					hint.maxAge = typeHint.maxAge
+				}
+				if hint.scope == "" {
+					hint.scope = typeHint.scope
+				}
+			}
+		}
+		switch {
+		case hint.maxAge != nil:
+			policy.restrict(*hint.maxAge, hint.scope)
+		case fc.Object == "Query" || typ == nil || typ.IsCompositeType():
+			policy.restrict(0, hint.scope)
+		case hint.scope == model.CacheControlScopePrivate:
+			policy.markPrivate()
+		}
+		return next(ctx)
+	}
+}
+
+// cachedWriter buffers the response to a GET query so that it can be sent
+// with caching headers, or replaced by 304 Not Modified.
+type cachedWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *cachedWriter) Header() http.Header { return w.header }
+
+func (w *cachedWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *cachedWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
+
+// serveCached serves a GET query through serve and adds Cache-Control, ETag
+// and Vary headers to the response.
+func serveCached(w http.ResponseWriter, req *http.Request, authenticated bool, serve func(http.ResponseWriter, *http.Request)) {
+	policy := &cachePolicy{}
+	cw := &cachedWriter{header: w.Header()}
+	serve(cw, req.WithContext(context.WithValue(req.Context(), cachePolicyKey{}, policy)))
+	if authenticated {
+		// Responses depend on the scopes of the key.
+		policy.markPrivate()
+	}
+
+	w.Header().Set("Vary", "Authorization, X-Site, Accept-Language")
+	if cw.status != http.StatusOK {
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(cw.status)
+		w.Write(cw.body.Bytes())
+		return
+	}
+	w.Header().Set("Cache-Control", policy.header())
+	sum := sha256.Sum256(cw.body.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	if etagMatches(req.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.WriteHeader(cw.status)
+	w.Write(cw.body.Bytes())
+}
+
+// etagMatches reports whether an If-None-Match header lists etag.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == etag || tag == "*" {
+			return true
+		}
+	}
+	return false
+}
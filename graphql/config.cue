@@ -0,0 +1,73 @@
+// Deadlines of the database work done by a single GraphQL operation.
+QueryTimeoutMs:    5000
+MutationTimeoutMs: 15000
+
+// Slug of the site served on hosts that are not the domain of any site.
+DefaultSite: "default"
+
+// Scopes of requests without an API key. The portfolio is public to read.
+AnonymousScopes: ["read:users", "read:projects", "read:blogs", "read:resumes", "read:testimonials"]
+
+// Token bucket per client, measured in query complexity.
+RateLimit: {
+	PerSecond:         20
+	Burst:             500
+	Store:             "memory"
+	TrustForwardedFor: false
+}
+
+// Results of public queries kept in memory, dropped when a mutation writes
+// the records they contain: right away on the instance that ran it, and
+// within PollSeconds on the others.
+ResponseCache: {
+	MaxEntries:  1000
+	PollSeconds: 5
+}
+
+// Spam protection of the contact form. Set CaptchaVerifyURL to e.g.
+// "https://challenges.cloudflare.com/turnstile/v0/siteverify" to require a
+// CAPTCHA.
+Contact: {
+	MinSubmitSeconds: 3
+	MaxFormHours:     24
+	PerHour:          5
+	Burst:            3
+	CaptchaVerifyURL: ""
+}
+
+// Outgoing email. The "log" mailer only logs messages, "file" writes them to
+// Dir, and "smtp" sends them with the SMTPPassword secret.
+Mail: {
+	Mailer:       "log"
+	From:         "Portfolio <no-reply@localhost>"
+	SMTPAddr:     ""
+	SMTPUsername: ""
+	Dir:          "mail"
+}
+
+// reactionsChanged subscriptions are notified right away of reactions made
+// on the same instance, and poll for the others.
+Reactions: {
+	PollSeconds: 10
+}
+
+// Texts of the records are in Default; translations can be added for the
+// Supported locales.
+Locales: {
+	Default:   "id"
+	Supported: ["en"]
+}
+
+// Preview links for drafts and hidden projects, valid for up to MaxDays.
+Previews: {
+	URL:     "http://localhost:3000/preview/{id}?token={token}"
+	MaxDays: 30
+}
+
+// Double opt-in newsletter. The token is appended to both URLs.
+Newsletter: {
+	ConfirmURL:     "http://localhost:3000/newsletter/confirm?token="
+	UnsubscribeURL: "http://localhost:4000/newsletter/unsubscribe?token="
+	ConfirmHours:   48
+	ExcerptLength:  300
+}
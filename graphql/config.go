@@ -0,0 +1,149 @@
+package graphql
+
+import (
+	"context"
+	"time"
+
+	"encore.dev/config"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// Config is the configuration of the graphql service, see config.cue.
+type Config struct {
+	// QueryTimeoutMs bounds the database statements of a query operation, in
+	// milliseconds. 0 disables the timeout.
+	QueryTimeoutMs config.Int
+	// MutationTimeoutMs bounds the database statements of a mutation, in
+	// milliseconds. 0 disables the timeout.
+	MutationTimeoutMs config.Int
+	// DefaultSite is the slug of the site served to requests that neither name
+	// a site in the X-Site header nor come in on the domain of one.
+	DefaultSite config.String
+	// AnonymousScopes are the scopes of requests without credentials.
+	AnonymousScopes config.Values[string]
+
+	// RateLimit limits the query complexity each client can spend.
+	RateLimit struct {
+		// PerSecond is the complexity refilled per second. 0 disables the
+		// rate limit.
+		PerSecond config.Float64
+		// Burst is the most complexity a client can spend at once. Queries
+		// above it are always rejected.
+		Burst config.Int
+		// Store is "memory" to keep the limit per instance, or "postgres" to
+		// share it between all instances.
+		Store config.String
+		// TrustForwardedFor identifies clients by the X-Forwarded-For header.
+		// Only enable it behind a proxy that sets the header.
+		TrustForwardedFor config.Bool
+	}
+
+	// ResponseCache keeps the results of public queries in memory.
+	ResponseCache struct {
+		// MaxEntries is the most responses kept per instance. 0 disables the
+		// cache.
+		MaxEntries config.Int
+		// PollSeconds is how often each instance drops the entries that
+		// writes through other instances invalidated. 0 disables that, which
+		// is only correct with a single instance.
+		PollSeconds config.Int
+	}
+
+	// Contact protects the contact form against spam.
+	Contact struct {
+		// MinSubmitSeconds is the least time between showing the form and
+		// submitting it. Faster submissions are dropped as bots.
+		MinSubmitSeconds config.Int
+		// MaxFormHours is how long the token of a shown form can be used.
+		MaxFormHours config.Int
+		// PerHour is how many messages an IP address can send per hour on
+		// average, and Burst how many at once. PerHour 0 disables the limit.
+		PerHour config.Float64
+		Burst   config.Int
+		// CaptchaVerifyURL is the siteverify endpoint of the CAPTCHA
+		// provider, whose secret is the CaptchaSecret secret. Empty disables
+		// the CAPTCHA.
+		CaptchaVerifyURL config.String
+	}
+
+	// Mail is how the service sends email.
+	Mail struct {
+		// Mailer is "smtp" to send through SMTPAddr, "file" to write each
+		// message to a file in Dir, or "log" to only log messages.
+		Mailer config.String
+		// From is the sender address.
+		From config.String
+		// SMTPAddr is the host:port of the SMTP server. SMTPUsername and the
+		// SMTPPassword secret authenticate with it, if set.
+		SMTPAddr     config.String
+		SMTPUsername config.String
+		// Dir is the directory the "file" mailer writes to.
+		Dir config.String
+	}
+
+	// Reactions pushes reaction counts to reactionsChanged subscriptions.
+	Reactions struct {
+		// PollSeconds is how often subscriptions check for reactions made
+		// through other instances, which cannot notify them. 0 disables
+		// polling, which is fine with a single instance.
+		PollSeconds config.Int
+	}
+
+	// Locales are the languages records can be translated into.
+	Locales struct {
+		// Default is the locale of the texts stored on the records
+		// themselves, which is served when no translation matches.
+		Default config.String
+		// Supported are the other locales translations can be stored in.
+		Supported config.Values[string]
+	}
+
+	// Previews are links that show drafts and hidden projects.
+	Previews struct {
+		// URL is the preview page of the frontend. {id} is replaced by the
+		// ID of the record and {token} by the previewToken.
+		URL config.String
+		// MaxDays is the longest a preview link can stay valid.
+		MaxDays config.Int
+	}
+
+	// Newsletter sends new blog posts to the confirmed subscribers of a site.
+	Newsletter struct {
+		// ConfirmURL is the page that confirms a subscription, which gets
+		// the token appended and passes it to confirmSubscription.
+		ConfirmURL config.String
+		// UnsubscribeURL is the public URL of the unsubscribe endpoint,
+		// which gets the token appended.
+		UnsubscribeURL config.String
+		// ConfirmHours is how long a confirmation link stays valid.
+		ConfirmHours config.Int
+		// ExcerptLength is how many characters of a post the email shows.
+		ExcerptLength config.Int
+	}
+}
+
+var cfg = config.Load[*Config]()
+
+// operationTimeouts returns a response middleware that puts a deadline on each
+// operation depending on its type. The deadline carries over to the calls to
+// the app service, whose database sessions are bound to their context, so
+// Postgres aborts any statement still running at the deadline or after the
+// client disconnected.
+func operationTimeouts(query, mutation time.Duration) graphql.ResponseMiddleware {
+	return func(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+		var timeout time.Duration
+		switch graphql.GetOperationContext(ctx).Operation.Operation {
+		case ast.Query:
+			timeout = query
+		case ast.Mutation:
+			timeout = mutation
+		}
+		if timeout <= 0 {
+			return next(ctx)
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return next(ctx)
+	}
+}
@@ -0,0 +1,174 @@
+package graphql
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"encore.app/app"
+	"encore.dev/beta/errs"
+)
+
+// captchaVerifier checks the CAPTCHA token a contact form was submitted with.
+type captchaVerifier interface {
+	verify(ctx context.Context, token, remoteIP string) (bool, error)
+}
+
+// siteVerifyCaptcha verifies tokens with the siteverify protocol shared by
+// Cloudflare Turnstile, hCaptcha and reCAPTCHA.
+type siteVerifyCaptcha struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func (c *siteVerifyCaptcha) verify(ctx context.Context, token, remoteIP string) (bool, error) {
+	form := url.Values{"secret": {c.secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("captcha: siteverify answered %s", resp.Status)
+	}
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("captcha: %w", err)
+	}
+	return result.Success, nil
+}
+
+// contactFormToken returns the token of a contact form of site shown at
+// issued. It holds the time and its HMAC, keyed with ContactFormSecret, so
+// clients cannot pretend they showed the form earlier.
+func contactFormToken(site uint, issued time.Time) string {
+	unix := strconv.FormatInt(issued.Unix(), 10)
+	return unix + "." + contactFormMAC(site, unix)
+}
+
+// contactFormIssued returns when the contact form of site with token was
+// shown. It reports false if the token was not issued for site.
+func contactFormIssued(token string, site uint) (time.Time, bool) {
+	unix, mac, ok := strings.Cut(token, ".")
+	if !ok || secrets.ContactFormSecret == "" || !hmac.Equal([]byte(mac), []byte(contactFormMAC(site, unix))) {
+		return time.Time{}, false
+	}
+	sec, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(sec, 0), true
+}
+
+func contactFormMAC(site uint, unix string) string {
+	mac := hmac.New(sha256.New, []byte(secrets.ContactFormSecret))
+	fmt.Fprintf(mac, "contact\x00%d\x00%s", site, unix)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// contactGuard keeps spam out of the contact form.
+type contactGuard struct {
+	// minSubmit is the least time a person takes to fill in the form, and
+	// maxAge the longest a form token can be used.
+	minSubmit time.Duration
+	maxAge    time.Duration
+	// limit holds a token bucket per IP address, nil disables it.
+	limit limitStore
+	// captcha is nil when no CAPTCHA is configured.
+	captcha captchaVerifier
+}
+
+// newContactGuard returns the guard configured under Contact.
+func newContactGuard() *contactGuard {
+	g := &contactGuard{
+		minSubmit: time.Duration(cfg.Contact.MinSubmitSeconds()) * time.Second,
+		maxAge:    time.Duration(cfg.Contact.MaxFormHours()) * time.Hour,
+	}
+	if perHour := cfg.Contact.PerHour(); perHour > 0 {
+		g.limit = newLimitStore(app.BucketRate{Rate: perHour / 3600, Burst: float64(cfg.Contact.Burst())})
+	}
+	if u := cfg.Contact.CaptchaVerifyURL(); u != "" {
+		g.captcha = &siteVerifyCaptcha{url: u, secret: secrets.CaptchaSecret, client: &http.Client{Timeout: 10 * time.Second}}
+	}
+	return g
+}
+
+// check reports whether a submission looks like it was sent by a person.
+// Submissions that filled in the honeypot or came in too fast are bots, which
+// are not told so. It returns an error when the form token is invalid or
+// expired, or when the sender has to slow down or solve the CAPTCHA.
+func (g *contactGuard) check(ctx context.Context, website *string, formToken string, captchaToken *string) (bool, error) {
+	if website != nil && *website != "" {
+		return false, nil
+	}
+	issued, ok := contactFormIssued(formToken, siteOf(ctx))
+	if !ok {
+		return false, invalidArgument("formToken", "formToken is invalid")
+	}
+	age := time.Since(issued)
+	if age > g.maxAge {
+		return false, invalidArgument("formToken", "the form has expired, reload it and try again")
+	}
+	if age < g.minSubmit {
+		return false, nil
+	}
+	c, _ := ctx.Value(clientKey{}).(*client)
+	if g.limit != nil && c != nil {
+		wait, err := g.limit.take(ctx, "contact:"+c.ip, 1, time.Now())
+		if err != nil {
+			return false, err
+		}
+		if wait > 0 {
+			return false, &errs.Error{
+				Code:    errs.ResourceExhausted,
+				Message: fmt.Sprintf("too many messages, try again in %d seconds", retryAfterSeconds(wait)),
+			}
+		}
+	}
+	if g.captcha != nil {
+		if captchaToken == nil || *captchaToken == "" {
+			return false, invalidArgument("captchaToken", "captchaToken is required")
+		}
+		var ip string
+		if c != nil {
+			ip = c.ip
+		}
+		ok, err := g.captcha.verify(ctx, *captchaToken, ip)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			return false, invalidArgument("captchaToken", "the CAPTCHA was not solved")
+		}
+	}
+	return true, nil
+}
+
+// updateContactMessage applies p to the message with global ID id, and
+// returns the updated message.
+func updateContactMessage(ctx context.Context, id string, p *app.UpdateContactMessageParams) (*app.ContactMessage, error) {
+	msgID, err := parseID("id", typeContactMessage, id)
+	if err != nil {
+		return nil, err
+	}
+	return app.UpdateContactMessage(ctx, siteOf(ctx), msgID, p)
+}
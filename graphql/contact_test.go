@@ -0,0 +1,142 @@
+//go:build encore_app
+
+package graphql
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"encore.app/app"
+	"encore.dev/beta/errs"
+)
+
+// stubCaptcha accepts the token "solved" and records what it was asked.
+type stubCaptcha struct {
+	err      error
+	calls    int
+	remoteIP string
+}
+
+func (c *stubCaptcha) verify(_ context.Context, token, remoteIP string) (bool, error) {
+	c.calls++
+	c.remoteIP = remoteIP
+	return token == "solved", c.err
+}
+
+func testContactGuard(t *testing.T) (*contactGuard, context.Context) {
+	t.Helper()
+	old := secrets.ContactFormSecret
+	secrets.ContactFormSecret = "contact-form-secret"
+	t.Cleanup(func() { secrets.ContactFormSecret = old })
+	g := &contactGuard{minSubmit: 3 * time.Second, maxAge: time.Hour}
+	ctx := context.WithValue(app.WithSite(context.Background(), 1), clientKey{}, &client{ip: "203.0.113.7"})
+	return g, ctx
+}
+
+// shownAgo returns the token of a form of site 1 shown d ago.
+func shownAgo(d time.Duration) string {
+	return contactFormToken(1, time.Now().Add(-d))
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func errCode(err error) errs.ErrCode {
+	var e *errs.Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return errs.Unknown
+}
+
+func TestContactGuardHoneypot(t *testing.T) {
+	g, ctx := testContactGuard(t)
+	human, err := g.check(ctx, ptr("https://spam.example"), shownAgo(time.Minute), nil)
+	if human || err != nil {
+		t.Fatalf("check = %v, %v; want a silent drop", human, err)
+	}
+	human, err = g.check(ctx, ptr(""), shownAgo(time.Minute), nil)
+	if !human || err != nil {
+		t.Fatalf("check with an empty honeypot = %v, %v", human, err)
+	}
+}
+
+func TestContactGuardTiming(t *testing.T) {
+	g, ctx := testContactGuard(t)
+	if human, err := g.check(ctx, nil, shownAgo(time.Second), nil); human || err != nil {
+		t.Errorf("submitted after 1s: check = %v, %v; want a silent drop", human, err)
+	}
+	if _, err := g.check(ctx, nil, shownAgo(2*time.Hour), nil); errCode(err) != errs.InvalidArgument {
+		t.Errorf("submitted after 2h: err = %v, want expired", err)
+	}
+
+	// The time in the token cannot be moved back without the secret.
+	unix, mac, _ := strings.Cut(shownAgo(time.Second), ".")
+	earlier := strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+	forged := earlier + "." + mac
+	for _, token := range []string{"", "garbage", unix, forged, unix + "." + mac + "x", contactFormToken(2, time.Now().Add(-time.Minute))} {
+		if _, err := g.check(ctx, nil, token, nil); errCode(err) != errs.InvalidArgument {
+			t.Errorf("token %q: err = %v, want invalid", token, err)
+		}
+	}
+
+	secrets.ContactFormSecret = ""
+	if _, err := g.check(ctx, nil, shownAgo(time.Minute), nil); errCode(err) != errs.InvalidArgument {
+		t.Errorf("without a secret: err = %v, want invalid", err)
+	}
+}
+
+func TestContactGuardRateLimit(t *testing.T) {
+	g, ctx := testContactGuard(t)
+	g.limit = newMemoryStore(app.BucketRate{Rate: 5.0 / 3600, Burst: 2})
+	for i := 0; i < 2; i++ {
+		if human, err := g.check(ctx, nil, shownAgo(time.Minute), nil); !human || err != nil {
+			t.Fatalf("message %d: check = %v, %v", i+1, human, err)
+		}
+	}
+	_, err := g.check(ctx, nil, shownAgo(time.Minute), nil)
+	if errCode(err) != errs.ResourceExhausted {
+		t.Fatalf("third message: err = %v, want ResourceExhausted", err)
+	}
+
+	// Other addresses have buckets of their own.
+	other := context.WithValue(ctx, clientKey{}, &client{ip: "198.51.100.1"})
+	if human, err := g.check(other, nil, shownAgo(time.Minute), nil); !human || err != nil {
+		t.Fatalf("another address: check = %v, %v", human, err)
+	}
+}
+
+func TestContactGuardCaptcha(t *testing.T) {
+	g, ctx := testContactGuard(t)
+	captcha := &stubCaptcha{}
+	g.captcha = captcha
+
+	if _, err := g.check(ctx, nil, shownAgo(time.Minute), nil); errCode(err) != errs.InvalidArgument {
+		t.Errorf("without a token: err = %v, want captchaToken is required", err)
+	}
+	if _, err := g.check(ctx, nil, shownAgo(time.Minute), ptr("wrong")); errCode(err) != errs.InvalidArgument {
+		t.Errorf("unsolved: err = %v, want invalid", err)
+	}
+	if human, err := g.check(ctx, nil, shownAgo(time.Minute), ptr("solved")); !human || err != nil {
+		t.Errorf("solved: check = %v, %v", human, err)
+	}
+	if captcha.remoteIP != "203.0.113.7" {
+		t.Errorf("the CAPTCHA was verified for %q, want the client's address", captcha.remoteIP)
+	}
+
+	// Bots are dropped before the provider is asked.
+	calls := captcha.calls
+	g.check(ctx, ptr("filled"), shownAgo(time.Minute), ptr("solved"))
+	g.check(ctx, nil, shownAgo(time.Second), ptr("solved"))
+	if captcha.calls != calls {
+		t.Error("the CAPTCHA was verified for a bot")
+	}
+
+	captcha.err = errors.New("provider down")
+	if _, err := g.check(ctx, nil, shownAgo(time.Minute), ptr("solved")); err == nil {
+		t.Error("a failing provider let the message through")
+	}
+}
@@ -0,0 +1,49 @@
+package graphql
+
+import (
+	"fmt"
+
+	"encore.app/graphql/model"
+)
+
+// The content types in crud.graphqls, crud.resolvers.go and crud_gen.go are
+// generated by app/scripts/crudgen from the app models marked
+// //crud:generate. Their lists are connections, paged with the helpers below.
+
+// maxPageSize is the most records a page of a connection can hold.
+const maxPageSize = 100
+
+// pageArgs checks the first and after arguments of a connection of the type
+// typ. The cursor after is the global ID of the last record of the previous
+// page. limit is one more than the size of the page, so that paginate can
+// tell whether another page follows.
+func pageArgs(typ string, first *int, after *string) (limit int, afterPK uint, err error) {
+	n := 20
+	if first != nil {
+		if *first < 1 || *first > maxPageSize {
+			return 0, 0, invalidArgument("first", fmt.Sprintf("first must be between 1 and %d", maxPageSize))
+		}
+		n = *first
+	}
+	if after != nil {
+		afterPK, err = parseID("after", typ, *after)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	return n + 1, afterPK, nil
+}
+
+// paginate cuts records, listed with a limit from pageArgs, down to the page
+// and describes it.
+func paginate[T any](typ string, records []*T, limit int, pk func(*T) uint) ([]*T, *model.PageInfo) {
+	info := &model.PageInfo{HasNextPage: len(records) == limit}
+	if info.HasNextPage {
+		records = records[:limit-1]
+	}
+	if n := len(records); n > 0 {
+		cursor := globalID(typ, pk(records[n-1]))
+		info.EndCursor = &cursor
+	}
+	return records, info
+}
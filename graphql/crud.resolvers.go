@@ -0,0 +1,48 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.81
+
+import (
+	"context"
+
+	"encore.app/app"
+	"encore.app/graphql/generated"
+	"encore.app/graphql/model"
+)
+
+// TestimonialCreate is the resolver for the testimonialCreate field.
+func (r *mutationResolver) TestimonialCreate(ctx context.Context, input model.CreateTestimonialInput, clientMutationID *string) (*model.CreateTestimonialPayload, error) {
+	return r.createTestimonial(ctx, input, clientMutationID)
+}
+
+// TestimonialUpdate is the resolver for the testimonialUpdate field.
+func (r *mutationResolver) TestimonialUpdate(ctx context.Context, id string, input model.UpdateTestimonialInput, clientMutationID *string) (*model.UpdateTestimonialPayload, error) {
+	return r.updateTestimonial(ctx, id, input, clientMutationID)
+}
+
+// TestimonialDelete is the resolver for the testimonialDelete field.
+func (r *mutationResolver) TestimonialDelete(ctx context.Context, id string, clientMutationID *string) (*model.DeleteTestimonialPayload, error) {
+	return r.deleteTestimonial(ctx, id, clientMutationID)
+}
+
+// Testimonials is the resolver for the testimonials field.
+func (r *queryResolver) Testimonials(ctx context.Context, first *int, after *string, where *model.TestimonialFilter) (*model.TestimonialConnection, error) {
+	return r.listTestimonials(ctx, first, after, where)
+}
+
+// Testimonial is the resolver for the testimonial field.
+func (r *queryResolver) Testimonial(ctx context.Context, id string) (*app.Testimonial, error) {
+	return r.getTestimonial(ctx, id)
+}
+
+// ID is the resolver for the id field.
+func (r *testimonialResolver) ID(ctx context.Context, obj *app.Testimonial) (string, error) {
+	return globalID(typeTestimonial, obj.ID), nil
+}
+
+// Testimonial returns generated.TestimonialResolver implementation.
+func (r *Resolver) Testimonial() generated.TestimonialResolver { return &testimonialResolver{r} }
+
+type testimonialResolver struct{ *Resolver }
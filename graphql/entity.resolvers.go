@@ -0,0 +1,54 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.81
+
+import (
+	"context"
+
+	"encore.app/app"
+	"encore.app/graphql/generated"
+	"encore.app/graphql/model"
+)
+
+// FindManyBlogByIDs is the resolver for the findManyBlogByIDs field.
+func (r *entityResolver) FindManyBlogByIDs(ctx context.Context, reps []*model.BlogByIDsInput) ([]*app.Blog, error) {
+	ids := make([]string, len(reps))
+	for i, rep := range reps {
+		ids[i] = rep.ID
+	}
+	return loadEntities[app.Blog](ctx, r.Resolver, ids)
+}
+
+// FindManyProjectByIDs is the resolver for the findManyProjectByIDs field.
+func (r *entityResolver) FindManyProjectByIDs(ctx context.Context, reps []*model.ProjectByIDsInput) ([]*app.Project, error) {
+	ids := make([]string, len(reps))
+	for i, rep := range reps {
+		ids[i] = rep.ID
+	}
+	return loadEntities[app.Project](ctx, r.Resolver, ids)
+}
+
+// FindManyResumeByIDs is the resolver for the findManyResumeByIDs field.
+func (r *entityResolver) FindManyResumeByIDs(ctx context.Context, reps []*model.ResumeByIDsInput) ([]*app.Resume, error) {
+	ids := make([]string, len(reps))
+	for i, rep := range reps {
+		ids[i] = rep.ID
+	}
+	return loadEntities[app.Resume](ctx, r.Resolver, ids)
+}
+
+// FindManyUserByIDs is the resolver for the findManyUserByIDs field.
+func (r *entityResolver) FindManyUserByIDs(ctx context.Context, reps []*model.UserByIDsInput) ([]*app.User, error) {
+	ids := make([]string, len(reps))
+	for i, rep := range reps {
+		ids[i] = rep.ID
+	}
+	return loadEntities[app.User](ctx, r.Resolver, ids)
+}
+
+// Entity returns generated.EntityResolver implementation.
+func (r *Resolver) Entity() generated.EntityResolver { return &entityResolver{r} }
+
+type entityResolver struct{ *Resolver }
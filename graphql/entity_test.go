@@ -0,0 +1,274 @@
+//go:build encore_app
+
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"encore.app/app"
+	"encore.app/graphql/generated"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+)
+
+// testResponse is a GraphQL response whose data is left for the test to
+// decode.
+type testResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Path       []any          `json:"path"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+// execute runs query on the executable schema, with the authorization and
+// argument validation of the service, for p on site.
+func execute(t *testing.T, site uint, p *principal, query string, vars map[string]any) *testResponse {
+	t.Helper()
+	schema := generated.NewExecutableSchema(generated.Config{Resolvers: &Resolver{}})
+	srv := handler.New(schema)
+	srv.AddTransport(transport.POST{})
+	srv.Use(extension.Introspection{})
+	srv.SetErrorPresenter(newErrorPresenter(false))
+	srv.AroundFields(authorize(schema.Schema()))
+	srv.AroundFields(validateArguments(schema.Schema()))
+
+	body, err := json.Marshal(map[string]any{"query": query, "variables": vars})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(app.WithSite(context.Background(), site), principalKey{}, p)
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body)).WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	var resp testResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s: %v", w.Body, err)
+	}
+	return &resp
+}
+
+const entitiesQuery = `query($reps: [_Any!]!) {
+	_entities(representations: $reps) {
+		__typename
+		... on User { id name }
+		... on Project { id title }
+		... on Blog { id title }
+		... on Resume { id title }
+	}
+}`
+
+// entity is an element of an _entities result; nil ones are null.
+type entity struct {
+	Typename string `json:"__typename"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Title    string `json:"title"`
+}
+
+// entities resolves reps, given as __typename and id pairs.
+func entities(t *testing.T, site uint, p *principal, reps ...string) ([]*entity, *testResponse) {
+	t.Helper()
+	list := make([]map[string]any, 0, len(reps)/2)
+	for i := 0; i < len(reps); i += 2 {
+		list = append(list, map[string]any{"__typename": reps[i], "id": reps[i+1]})
+	}
+	resp := execute(t, site, p, entitiesQuery, map[string]any{"reps": list})
+	var data struct {
+		Entities []*entity `json:"_entities"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatalf("%s: %v", resp.Data, err)
+	}
+	if len(data.Entities) != len(list) {
+		t.Fatalf("%d entities for %d representations: %+v", len(data.Entities), len(list), resp)
+	}
+	return data.Entities, resp
+}
+
+// entityRecords are records of every entity type of the default site.
+type entityRecords struct {
+	site                   uint
+	user, project, hidden  string
+	blog, draft, resume    string
+	userName, projectTitle string
+}
+
+func createEntityRecords(t *testing.T) *entityRecords {
+	t.Helper()
+	ctx := context.Background()
+	site, err := app.ResolveSite(ctx, &app.ResolveSiteParams{Slug: cfg.DefaultSite()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &entityRecords{site: site.ID}
+	user, err := app.CreateUser(ctx, site.ID, &app.CreateUserParams{
+		Name:  "Ada",
+		Email: fmt.Sprintf("ada-%d@example.com", time.Now().UnixNano()),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.user, r.userName = globalID(typeUser, user.ID), user.Name
+	project, err := app.CreateProject(ctx, site.ID, &app.CreateProjectParams{Title: "Engine", UserID: user.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.project, r.projectTitle = globalID(typeProject, project.ID), project.Title
+	hidden, err := app.CreateProject(ctx, site.ID, &app.CreateProjectParams{Title: "Secret", UserID: user.ID, Hidden: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.hidden = globalID(typeProject, hidden.ID)
+	blog, err := app.CreateBlog(ctx, site.ID, &app.CreateBlogParams{Title: "Notes", Content: "..."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.blog = globalID(typeBlog, blog.ID)
+	draft, err := app.CreateBlog(ctx, site.ID, &app.CreateBlogParams{Title: "Unfinished", Content: "...", Draft: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.draft = globalID(typeBlog, draft.ID)
+	resume, err := app.CreateResume(ctx, site.ID, &app.CreateResumeParams{Title: "Analyst", Category: "work"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.resume = globalID(typeResume, resume.ID)
+	return r
+}
+
+// reader may read every entity type, but not edit them.
+var reader = &principal{scopes: []string{"read:users", "read:projects", "read:blogs", "read:resumes"}}
+
+func TestEntitiesMixedBatch(t *testing.T) {
+	r := createEntityRecords(t)
+	got, resp := entities(t, r.site, reader,
+		"Blog", r.blog,
+		"User", r.user,
+		"Resume", r.resume,
+		"Project", r.project,
+		"User", r.user,
+	)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors: %+v", resp.Errors)
+	}
+	want := []struct{ typename, id string }{
+		{"Blog", r.blog}, {"User", r.user}, {"Resume", r.resume}, {"Project", r.project}, {"User", r.user},
+	}
+	for i, w := range want {
+		if got[i] == nil || got[i].Typename != w.typename || got[i].ID != w.id {
+			t.Errorf("entity %d = %+v, want %s %s", i, got[i], w.typename, w.id)
+		}
+	}
+	if got[1].Name != r.userName || got[3].Title != r.projectTitle {
+		t.Errorf("fields were not resolved: %+v, %+v", got[1], got[3])
+	}
+}
+
+func TestEntitiesMissing(t *testing.T) {
+	r := createEntityRecords(t)
+	got, resp := entities(t, r.site, reader,
+		"User", globalID(typeUser, math.MaxInt32),
+		"Blog", r.user, // an ID of another type
+		"Resume", r.resume,
+	)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors: %+v", resp.Errors)
+	}
+	if got[0] != nil || got[1] != nil || got[2] == nil {
+		t.Errorf("entities = %+v, %+v, %+v; want null, null, the resume", got[0], got[1], got[2])
+	}
+
+	// The records of the default site are unknown to every other site.
+	got, _ = entities(t, r.site+math.MaxInt32, reader,
+		"User", r.user, "Project", r.project, "Blog", r.blog, "Resume", r.resume)
+	for i, e := range got {
+		if e != nil {
+			t.Errorf("entity %d of another site resolved to %+v", i, e)
+		}
+	}
+
+	resp = execute(t, r.site, reader, entitiesQuery, map[string]any{
+		"reps": []map[string]any{{"__typename": "User", "id": "not-an-id"}},
+	})
+	if len(resp.Errors) == 0 {
+		t.Error("an invalid ID did not fail")
+	}
+}
+
+func TestEntitiesHideDraftsAndHiddenProjects(t *testing.T) {
+	r := createEntityRecords(t)
+	got, _ := entities(t, r.site, reader, "Blog", r.draft, "Project", r.hidden, "Blog", r.blog)
+	if got[0] != nil || got[1] != nil || got[2] == nil {
+		t.Errorf("as a reader: %+v, %+v, %+v; want null, null, the post", got[0], got[1], got[2])
+	}
+
+	editor := &principal{authenticated: true, scopes: append([]string{"write:blogs", "write:projects"}, reader.scopes...)}
+	got, _ = entities(t, r.site, editor, "Blog", r.draft, "Project", r.hidden)
+	if got[0] == nil || got[1] == nil {
+		t.Errorf("as an editor: %+v, %+v; want the draft and the hidden project", got[0], got[1])
+	}
+}
+
+func TestEntitiesFilterByScope(t *testing.T) {
+	r := createEntityRecords(t)
+	blogsOnly := &principal{authenticated: true, scopes: []string{"read:blogs"}}
+	got, resp := entities(t, r.site, blogsOnly, "User", r.user, "Blog", r.blog, "Resume", r.resume)
+	if got[0] != nil || got[1] == nil || got[2] != nil {
+		t.Errorf("entities = %+v, %+v, %+v; want null, the post, null", got[0], got[1], got[2])
+	}
+	// filterList reports the first failure once.
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != CodeForbidden {
+		t.Errorf("errors = %+v, want one FORBIDDEN", resp.Errors)
+	}
+
+	anonymous := &principal{}
+	got, resp = entities(t, r.site, anonymous, "Blog", r.blog)
+	if got[0] != nil || len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != CodeUnauthenticated {
+		t.Errorf("anonymous without scopes: %+v, errors %+v", got[0], resp.Errors)
+	}
+}
+
+func TestServiceSDL(t *testing.T) {
+	resp := execute(t, 0, &principal{}, `{ _service { sdl } }`, nil)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors: %+v", resp.Errors)
+	}
+	var data struct {
+		Service struct {
+			SDL string `json:"sdl"`
+		} `json:"_service"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	for _, typ := range []string{"User", "Project", "Blog", "Resume"} {
+		decl := sdlDeclaration(data.Service.SDL, "type "+typ+" ")
+		if !strings.Contains(decl, `@key(fields: "id")`) {
+			t.Errorf("%s is not an entity in the SDL: %q", typ, decl)
+		}
+	}
+}
+
+// sdlDeclaration returns the line of sdl that starts with prefix.
+func sdlDeclaration(sdl, prefix string) string {
+	for _, line := range strings.Split(sdl, "\n") {
+		if strings.HasPrefix(line, prefix) {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,160 @@
+package graphql
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"runtime/debug"
+
+	"encore.app/app"
+	"encore.app/graphql/model"
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Error codes reported in the extensions.code of GraphQL errors.
+const (
+	CodeNotFound         = "NOT_FOUND"
+	CodeValidationFailed = "VALIDATION_FAILED"
+	CodeConflict         = "CONFLICT"
+	CodeUnauthenticated  = "UNAUTHENTICATED"
+	CodeForbidden        = "FORBIDDEN"
+	CodeRateLimited      = "RATE_LIMITED"
+	CodeInternal         = "INTERNAL"
+)
+
+// invalidArgument returns a VALIDATION_FAILED error about the given input field.
+func invalidArgument(field, msg string) error {
+	return &errs.Error{Code: errs.InvalidArgument, Message: msg, Details: app.FieldDetails{Field: field}}
+}
+
+// codeFor maps an Encore error code onto the codes exposed to GraphQL clients.
+func codeFor(code errs.ErrCode) string {
+	switch code {
+	case errs.NotFound:
+		return CodeNotFound
+	case errs.InvalidArgument, errs.OutOfRange, errs.FailedPrecondition:
+		return CodeValidationFailed
+	case errs.AlreadyExists, errs.Aborted:
+		return CodeConflict
+	case errs.Unauthenticated:
+		return CodeUnauthenticated
+	case errs.PermissionDenied:
+		return CodeForbidden
+	case errs.ResourceExhausted:
+		return CodeRateLimited
+	default:
+		return CodeInternal
+	}
+}
+
+// clientError is the client-facing description of an expected failure.
+type clientError struct {
+	code    string
+	message string
+	field   string
+	// current is the server copy of a record an update conflicted with.
+	current model.Node
+}
+
+// classify describes err for clients if it is an expected failure, such as a
+// missing record or a duplicate value. It reports false for anything else.
+func classify(err error) (clientError, bool) {
+	var (
+		encoreErr *errs.Error
+		conflict  *versionConflict
+	)
+	switch {
+	case errors.As(err, &conflict):
+		return clientError{code: CodeConflict, message: conflict.Error(), field: "expectedVersion", current: conflict.current}, true
+	case errors.As(app.DBError(err), &encoreErr):
+		ce := clientError{code: codeFor(encoreErr.Code), message: encoreErr.Message}
+		switch d := encoreErr.Details.(type) {
+		case app.FieldDetails:
+			ce.field = d.Field
+		case app.VersionDetails:
+			ce.field = "expectedVersion"
+		}
+		return ce, ce.code != CodeInternal
+	}
+	return clientError{}, false
+}
+
+// newErrorPresenter returns the error presenter for the GraphQL server.
+//
+// Domain errors keep their message and get a stable extensions.code. Anything
+// unexpected is reported as INTERNAL under a correlation ID that is also
+// logged together with the original error. When maskInternal is set, the
+// original message is never sent to the client.
+func newErrorPresenter(maskInternal bool) graphql.ErrorPresenterFunc {
+	return func(ctx context.Context, err error) *gqlerror.Error {
+		gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+
+		if ce, ok := classify(err); ok {
+			gqlErr.Message = ce.message
+			setCode(gqlErr, ce.code)
+			if ce.field != "" {
+				gqlErr.Extensions["field"] = ce.field
+			}
+			if v, ok := ce.current.(versioned); ok {
+				gqlErr.Extensions["currentVersion"] = v.VersionInfo().Version
+			}
+			return gqlErr
+		}
+		// Errors raised by gqlgen itself (parsing, validation, coercion) are
+		// already fit for clients. Resolver errors arrive wrapped in a
+		// gqlerror.Error too, which then holds the original in Err.
+		var clientErr *gqlerror.Error
+		if errors.As(err, &clientErr) && clientErr.Err == nil {
+			return gqlErr
+		}
+
+		id := correlationID()
+		var logged *loggedError
+		if errors.As(err, &logged) {
+			id = logged.id
+		}
+		rlog.Error("graphql: internal error", "correlation_id", id, "path", gqlErr.Path.String(), "err", err)
+		if maskInternal {
+			gqlErr.Message = "internal error"
+		}
+		setCode(gqlErr, CodeInternal)
+		gqlErr.Extensions["correlationId"] = id
+		return gqlErr
+	}
+}
+
+func setCode(gqlErr *gqlerror.Error, code string) {
+	if gqlErr.Extensions == nil {
+		gqlErr.Extensions = map[string]any{}
+	}
+	gqlErr.Extensions["code"] = code
+}
+
+// loggedError is an error whose details were already logged under the
+// correlation ID id, which the presenter then reports instead of a new one.
+type loggedError struct {
+	id  string
+	err error
+}
+
+func (e *loggedError) Error() string { return e.err.Error() }
+func (e *loggedError) Unwrap() error { return e.err }
+
+// recoverPanic turns a panic in a resolver into an internal error. The stack
+// trace is only logged, as it must not reach clients in any environment.
+func recoverPanic(ctx context.Context, p any) error {
+	id := correlationID()
+	rlog.Error("graphql: panic", "correlation_id", id, "panic", fmt.Sprint(p), "stack", string(debug.Stack()))
+	return &loggedError{id: id, err: fmt.Errorf("panic: %v", p)}
+}
+
+func correlationID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
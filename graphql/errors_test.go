@@ -0,0 +1,59 @@
+//go:build encore_app
+
+package graphql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"encore.dev/beta/errs"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func TestErrorPresenter(t *testing.T) {
+	path := ast.Path{ast.PathName("blogs"), ast.PathIndex(0), ast.PathName("title")}
+	driverErr := errors.New(`pq: duplicate key value violates unique constraint "idx_users_email"`)
+	tests := []struct {
+		name     string
+		mask     bool
+		err      error
+		message  string
+		code     string
+		internal bool
+	}{
+		{"driver error", true, gqlerror.WrapPath(path, driverErr), "internal error", CodeInternal, true},
+		{"internal encore error", true, gqlerror.WrapPath(path, &errs.Error{Code: errs.Internal, Message: "dial tcp 10.0.0.5:5432"}), "internal error", CodeInternal, true},
+		{"unmasked", false, gqlerror.WrapPath(path, driverErr), driverErr.Error(), CodeInternal, true},
+		{"expected failure", true, gqlerror.WrapPath(path, &errs.Error{Code: errs.NotFound, Message: "not found"}), "not found", CodeNotFound, false},
+		{"coercion error", true, gqlerror.ErrorPathf(path, "Date must be a YYYY-MM-DD string"), "Date must be a YYYY-MM-DD string", "", false},
+	}
+	for _, tt := range tests {
+		got := newErrorPresenter(tt.mask)(context.Background(), tt.err)
+		if got.Message != tt.message {
+			t.Errorf("%s: message %q, want %q", tt.name, got.Message, tt.message)
+		}
+		if code, _ := got.Extensions["code"].(string); code != tt.code {
+			t.Errorf("%s: code %q, want %q", tt.name, code, tt.code)
+		}
+		if id, _ := got.Extensions["correlationId"].(string); (id != "") != tt.internal {
+			t.Errorf("%s: correlationId %q", tt.name, id)
+		}
+		if got.Path.String() != path.String() {
+			t.Errorf("%s: path %s, want %s", tt.name, got.Path, path)
+		}
+	}
+}
+
+func TestErrorPresenterKeepsLoggedID(t *testing.T) {
+	err := recoverPanic(context.Background(), "boom")
+	var logged *loggedError
+	if !errors.As(err, &logged) {
+		t.Fatalf("recoverPanic returned %T", err)
+	}
+	got := newErrorPresenter(true)(context.Background(), gqlerror.WrapPath(ast.Path{ast.PathName("blog")}, err))
+	if got.Message != "internal error" || got.Extensions["correlationId"] != logged.id {
+		t.Errorf("presented %q with correlationId %v, want the logged %s", got.Message, got.Extensions["correlationId"], logged.id)
+	}
+}
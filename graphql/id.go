@@ -0,0 +1,44 @@
+package graphql
+
+import (
+	"fmt"
+
+	"encore.app/app"
+)
+
+// Type names used as the prefix of global IDs.
+const (
+	typeUser    = app.TypeUser
+	typeProject = app.TypeProject
+	typeBlog    = app.TypeBlog
+	typeResume  = app.TypeResume
+	typeAPIKey  = "ApiKey"
+	typeWebhook = "Webhook"
+	// The following are not Nodes, their IDs only have to be unique.
+	typeWebhookDelivery = "WebhookDelivery"
+	typeContactMessage  = "ContactMessage"
+)
+
+// globalID returns the opaque ID of a record, see app.GlobalID.
+func globalID(typ string, pk uint) string {
+	return app.GlobalID(typ, pk)
+}
+
+// decodeGlobalID splits a global ID into its type name and primary key.
+func decodeGlobalID(id string) (typ string, pk uint, ok bool) {
+	return app.DecodeGlobalID(id)
+}
+
+// parseID converts a global ID argument of the given type into a primary key.
+// field names the argument or input field so that a malformed value, or the
+// ID of another type, can be reported on it.
+func parseID(field, typ, id string) (uint, error) {
+	got, pk, ok := decodeGlobalID(id)
+	if !ok {
+		return 0, invalidArgument(field, fmt.Sprintf("%s is not a valid ID", field))
+	}
+	if got != typ {
+		return 0, invalidArgument(field, fmt.Sprintf("%s must be the ID of a %s, got a %s", field, typ, got))
+	}
+	return pk, nil
+}
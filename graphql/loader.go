@@ -0,0 +1,170 @@
+package graphql
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"encore.app/app"
+)
+
+const (
+	// batchWait is how long a batchLoader waits for more fields of a request
+	// before it asks for their values.
+	batchWait = 2 * time.Millisecond
+	// maxBatch is the most records a batchLoader asks for at once.
+	maxBatch = 100
+)
+
+// loadersKey is the context key of the loaders of a request.
+type loadersKey struct{}
+
+// loaders batch the fields of one request that would otherwise call the app
+// service once per record.
+type loaders struct {
+	viewCounts          *batchLoader[int]
+	blogTranslations    *batchLoader[[]*app.BlogTranslation]
+	projectTranslations *batchLoader[[]*app.ProjectTranslation]
+	resumeTranslations  *batchLoader[[]*app.ResumeTranslation]
+	reactions           *batchLoader[*app.ReactionsResponse]
+}
+
+// newLoaders returns the loaders of a request for site, which ask the app
+// service with ctx.
+func newLoaders(ctx context.Context, site uint) *loaders {
+	return &loaders{
+		viewCounts:          newBatchLoader(ctx, site, fetchViewCounts),
+		blogTranslations:    newBatchLoader(ctx, site, fetchBlogTranslations),
+		projectTranslations: newBatchLoader(ctx, site, fetchProjectTranslations),
+		resumeTranslations:  newBatchLoader(ctx, site, fetchResumeTranslations),
+		reactions:           newBatchLoader(ctx, site, fetchReactions),
+	}
+}
+
+// loadersOf returns the loaders of the request of ctx. Outside a request,
+// such as in tests, it returns new ones that only batch the fields of the
+// caller.
+func loadersOf(ctx context.Context) *loaders {
+	if l, ok := ctx.Value(loadersKey{}).(*loaders); ok {
+		return l
+	}
+	return newLoaders(ctx, siteOf(ctx))
+}
+
+// batchLoader loads a field of the records of one request, so that a list of
+// n records costs one call to the app service instead of n. The fields with
+// the same key, such as the type of the record, that come in within batchWait
+// of each other form a batch. Values are not kept beyond their batch, so
+// subscriptions always see fresh ones.
+type batchLoader[V any] struct {
+	ctx   context.Context
+	site  uint
+	fetch func(ctx context.Context, site uint, key string, ids []uint) (map[uint]V, error)
+
+	mu      sync.Mutex
+	batches map[string]*batch[V]
+}
+
+type batch[V any] struct {
+	ids    []uint
+	once   sync.Once
+	done   chan struct{}
+	values map[uint]V
+	err    error
+}
+
+func newBatchLoader[V any](ctx context.Context, site uint, fetch func(ctx context.Context, site uint, key string, ids []uint) (map[uint]V, error)) *batchLoader[V] {
+	return &batchLoader[V]{ctx: ctx, site: site, fetch: fetch, batches: make(map[string]*batch[V])}
+}
+
+// load returns the value of the record pk under key.
+func (l *batchLoader[V]) load(ctx context.Context, key string, pk uint) (V, error) {
+	l.mu.Lock()
+	b := l.batches[key]
+	if b == nil {
+		b = &batch[V]{done: make(chan struct{})}
+		l.batches[key] = b
+		time.AfterFunc(batchWait, func() { l.run(key, b) })
+	}
+	if !slices.Contains(b.ids, pk) {
+		b.ids = append(b.ids, pk)
+	}
+	full := len(b.ids) >= maxBatch
+	l.mu.Unlock()
+	if full {
+		l.run(key, b)
+	}
+	select {
+	case <-b.done:
+		return b.values[pk], b.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// run loads the values of batch b under key, once. Later fields with the key
+// start a new batch.
+func (l *batchLoader[V]) run(key string, b *batch[V]) {
+	b.once.Do(func() {
+		l.mu.Lock()
+		if l.batches[key] == b {
+			delete(l.batches, key)
+		}
+		ids := b.ids
+		l.mu.Unlock()
+		b.values, b.err = l.fetch(l.ctx, l.site, key, ids)
+		close(b.done)
+	})
+}
+
+// localesBatchKey joins the locales of a chain into the key of a batch of
+// translations. The empty key stands for all locales.
+func localesBatchKey(locales []string) string {
+	return strings.Join(locales, ",")
+}
+
+func splitLocales(key string) []string {
+	if key == "" {
+		return nil
+	}
+	return strings.Split(key, ",")
+}
+
+func fetchBlogTranslations(ctx context.Context, site uint, locales string, ids []uint) (map[uint][]*app.BlogTranslation, error) {
+	resp, err := app.ListBlogTranslations(ctx, site, &app.ListBlogTranslationsParams{BlogIDs: ids, Locales: splitLocales(locales)})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint][]*app.BlogTranslation, len(ids))
+	for _, t := range resp.Translations {
+		byID[t.BlogID] = append(byID[t.BlogID], t)
+	}
+	return byID, nil
+}
+
+func fetchProjectTranslations(ctx context.Context, site uint, locales string, ids []uint) (map[uint][]*app.ProjectTranslation, error) {
+	resp, err := app.ListProjectTranslations(ctx, site, &app.ListProjectTranslationsParams{ProjectIDs: ids, Locales: splitLocales(locales)})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint][]*app.ProjectTranslation, len(ids))
+	for _, t := range resp.Translations {
+		byID[t.ProjectID] = append(byID[t.ProjectID], t)
+	}
+	return byID, nil
+}
+
+func fetchResumeTranslations(ctx context.Context, site uint, locales string, ids []uint) (map[uint][]*app.ResumeTranslation, error) {
+	resp, err := app.ListResumeTranslations(ctx, site, &app.ListResumeTranslationsParams{ResumeIDs: ids, Locales: splitLocales(locales)})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint][]*app.ResumeTranslation, len(ids))
+	for _, t := range resp.Translations {
+		byID[t.ResumeID] = append(byID[t.ResumeID], t)
+	}
+	return byID, nil
+}
@@ -0,0 +1,74 @@
+//go:build encore_app
+
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestBatchLoader(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	fetch := func(ctx context.Context, site uint, key string, ids []uint) (map[uint]string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		slices.Sort(ids)
+		calls = append(calls, fmt.Sprint(key, ids))
+		values := make(map[uint]string, len(ids))
+		for _, id := range ids {
+			values[id] = fmt.Sprint(key, id)
+		}
+		return values, nil
+	}
+	l := newBatchLoader(context.Background(), 1, fetch)
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"blog", "project"} {
+		for _, id := range []uint{3, 1, 2, 1} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if got, err := l.load(context.Background(), key, id); err != nil || got != fmt.Sprint(key, id) {
+					t.Errorf("load(%s, %d) = %q, %v", key, id, got, err)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	slices.Sort(calls)
+	if want := "[blog[1 2 3] project[1 2 3]]"; fmt.Sprint(calls) != want {
+		t.Errorf("fetched %v, want %s", calls, want)
+	}
+
+	// Values are not kept beyond their batch.
+	if _, err := l.load(context.Background(), "blog", 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 3 {
+		t.Errorf("fetched %v, want a new batch", calls)
+	}
+}
+
+func TestBatchLoaderFull(t *testing.T) {
+	var sizes []int
+	fetch := func(ctx context.Context, site uint, key string, ids []uint) (map[uint]int, error) {
+		sizes = append(sizes, len(ids))
+		return nil, nil
+	}
+	l := newBatchLoader(context.Background(), 1, fetch)
+	l.batches["x"] = &batch[int]{done: make(chan struct{})}
+	for id := range uint(maxBatch - 1) {
+		l.batches["x"].ids = append(l.batches["x"].ids, id)
+	}
+	// The hundredth record runs the batch without waiting for the timer.
+	if _, err := l.load(context.Background(), "x", maxBatch); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(sizes) != fmt.Sprint([]int{maxBatch}) {
+		t.Errorf("batch sizes = %v, want [%d]", sizes, maxBatch)
+	}
+}
@@ -0,0 +1,207 @@
+package graphql
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"encore.app/app"
+)
+
+// maxAcceptedLocales is how many locales of an Accept-Language header are
+// considered.
+const maxAcceptedLocales = 10
+
+// localesKey is the context key of the locales the request accepts, most
+// preferred first.
+type localesKey struct{}
+
+// normalizeLocale returns the lowercase form of a locale such as en_US, which
+// is en-us.
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// baseLanguage returns the language of a normalized locale, such as de for
+// de-ch.
+func baseLanguage(locale string) string {
+	lang, _, _ := strings.Cut(locale, "-")
+	return lang
+}
+
+// acceptedLocales returns the normalized locales of an Accept-Language
+// header, most preferred first. Wildcards and locales with q=0 are left out.
+func acceptedLocales(header string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+	var ws []weighted
+	for _, part := range strings.Split(header, ",") {
+		locale, params, _ := strings.Cut(part, ";")
+		w := weighted{locale: normalizeLocale(locale), q: 1}
+		for _, param := range strings.Split(params, ";") {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if q, err := strconv.ParseFloat(value, 64); name == "q" && err == nil {
+				w.q = q
+			}
+		}
+		if w.locale != "" && w.locale != "*" && w.q > 0 {
+			ws = append(ws, w)
+		}
+	}
+	slices.SortStableFunc(ws, func(a, b weighted) int { return cmp.Compare(b.q, a.q) })
+	locales := make([]string, 0, min(len(ws), maxAcceptedLocales))
+	for _, w := range ws[:min(len(ws), maxAcceptedLocales)] {
+		locales = append(locales, w.locale)
+	}
+	return locales
+}
+
+// defaultLocale returns the locale of the texts stored on the records
+// themselves.
+func defaultLocale() string {
+	return normalizeLocale(cfg.Locales.Default())
+}
+
+// supportedLocale reports whether records can be translated into the
+// normalized locale.
+func supportedLocale(locale string) bool {
+	return slices.ContainsFunc(cfg.Locales.Supported(), func(s string) bool {
+		return normalizeLocale(s) == locale
+	})
+}
+
+// localeChain returns the supported locales to look for a translation in,
+// most preferred first: locale if given, then those of the Accept-Language
+// header, each followed by its language. The chain ends before the default
+// locale, whose text is the record itself, and is empty if that comes first.
+func localeChain(ctx context.Context, locale *string) ([]string, error) {
+	var wanted []string
+	if locale != nil {
+		l := normalizeLocale(*locale)
+		if l != defaultLocale() && !supportedLocale(l) && !supportedLocale(baseLanguage(l)) {
+			return nil, invalidArgument("locale", fmt.Sprintf("unsupported locale %q", *locale))
+		}
+		wanted = append(wanted, l)
+	}
+	accepted, _ := ctx.Value(localesKey{}).([]string)
+	wanted = append(wanted, accepted...)
+
+	var chain []string
+	for _, l := range wanted {
+		for _, candidate := range []string{l, baseLanguage(l)} {
+			if candidate == defaultLocale() {
+				return chain, nil
+			}
+			if supportedLocale(candidate) && !slices.Contains(chain, candidate) {
+				chain = append(chain, candidate)
+			}
+		}
+	}
+	return chain, nil
+}
+
+// translatedText is the text of a record in one locale.
+type translatedText struct {
+	Locale string
+	Title  string
+	Body   string
+}
+
+// translationLocale checks and normalizes the locale of a new translation.
+func translationLocale(locale string) (string, error) {
+	l := normalizeLocale(locale)
+	if l == defaultLocale() {
+		return "", invalidArgument("locale", fmt.Sprintf("%q is the default locale, whose text is the record itself", locale))
+	}
+	if !supportedLocale(l) {
+		return "", invalidArgument("locale", fmt.Sprintf("unsupported locale %q", locale))
+	}
+	return l, nil
+}
+
+// blogText returns the title and content of a blog post in the caller's
+// locale.
+func (r *Resolver) blogText(ctx context.Context, obj *app.Blog, locale *string) (title, content string, err error) {
+	t, err := r.translated(ctx, locale, func(chain []string) ([]translatedText, error) {
+		translations, err := loadersOf(ctx).blogTranslations.load(ctx, localesBatchKey(chain), obj.ID)
+		if err != nil {
+			return nil, err
+		}
+		texts := make([]translatedText, len(translations))
+		for i, t := range translations {
+			texts[i] = translatedText{Locale: t.Locale, Title: t.Title, Body: t.Content}
+		}
+		return texts, nil
+	})
+	if err != nil || t == nil {
+		return obj.Title, obj.Content, err
+	}
+	return t.Title, t.Body, nil
+}
+
+// projectText returns the title and description of a project in the
+// caller's locale.
+func (r *Resolver) projectText(ctx context.Context, obj *app.Project, locale *string) (title, description string, err error) {
+	t, err := r.translated(ctx, locale, func(chain []string) ([]translatedText, error) {
+		translations, err := loadersOf(ctx).projectTranslations.load(ctx, localesBatchKey(chain), obj.ID)
+		if err != nil {
+			return nil, err
+		}
+		texts := make([]translatedText, len(translations))
+		for i, t := range translations {
+			texts[i] = translatedText{Locale: t.Locale, Title: t.Title, Body: t.Description}
+		}
+		return texts, nil
+	})
+	if err != nil || t == nil {
+		return obj.Title, obj.Description, err
+	}
+	return t.Title, t.Body, nil
+}
+
+// resumeText returns the title and description of a resume section in the
+// caller's locale.
+func (r *Resolver) resumeText(ctx context.Context, obj *app.Resume, locale *string) (title, description string, err error) {
+	t, err := r.translated(ctx, locale, func(chain []string) ([]translatedText, error) {
+		translations, err := loadersOf(ctx).resumeTranslations.load(ctx, localesBatchKey(chain), obj.ID)
+		if err != nil {
+			return nil, err
+		}
+		texts := make([]translatedText, len(translations))
+		for i, t := range translations {
+			texts[i] = translatedText{Locale: t.Locale, Title: t.Title, Body: t.Description}
+		}
+		return texts, nil
+	})
+	if err != nil || t == nil {
+		return obj.Title, obj.Description, err
+	}
+	return t.Title, t.Body, nil
+}
+
+// translated returns the text of a record in the first locale of the
+// caller's chain it is translated into, or nil if there is none. list
+// returns the translations of the record into the locales of the chain.
+func (r *Resolver) translated(ctx context.Context, locale *string, list func(chain []string) ([]translatedText, error)) (*translatedText, error) {
+	chain, err := localeChain(ctx, locale)
+	if err != nil || len(chain) == 0 {
+		return nil, err
+	}
+	texts, err := list(chain)
+	if err != nil {
+		return nil, err
+	}
+	for _, locale := range chain {
+		for _, t := range texts {
+			if t.Locale == locale {
+				return &t, nil
+			}
+		}
+	}
+	return nil, nil
+}
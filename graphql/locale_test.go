@@ -0,0 +1,74 @@
+//go:build encore_app
+
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"encore.dev/beta/errs"
+)
+
+func TestAcceptedLocales(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"", []string{}},
+		{"en-GB", []string{"en-gb"}},
+		{"en_US, de", []string{"en-us", "de"}},
+		{"de;q=0.5, en-GB, fr;q=0.8", []string{"en-gb", "fr", "de"}},
+		{"de;q=0.5, en;q=0.5", []string{"de", "en"}},
+		{"*, en;q=0.1", []string{"en"}},
+		{"en;q=0, de", []string{"de"}},
+		{"en; q=0.9, de;q=oops", []string{"de", "en"}},
+		{strings.Repeat("en,", 12), []string{"en", "en", "en", "en", "en", "en", "en", "en", "en", "en"}},
+	}
+	for _, tt := range tests {
+		if got := acceptedLocales(tt.header); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("acceptedLocales(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+// TestLocaleChain relies on the locales of config.cue: id is the default and
+// en the only other supported locale.
+func TestLocaleChain(t *testing.T) {
+	tests := []struct {
+		locale   string
+		accepted []string
+		want     []string
+		err      bool
+	}{
+		{accepted: nil, want: nil},
+		{accepted: []string{"en"}, want: []string{"en"}},
+		{accepted: []string{"en-gb", "en"}, want: []string{"en"}},
+		{accepted: []string{"de", "en-au"}, want: []string{"en"}},
+		{accepted: []string{"id", "en"}, want: nil},
+		{accepted: []string{"id-id", "en"}, want: nil},
+		{accepted: []string{"en", "id"}, want: []string{"en"}},
+		{locale: "en_US", want: []string{"en"}},
+		{locale: "ID", accepted: []string{"en"}, want: nil},
+		{locale: "en", accepted: []string{"id"}, want: []string{"en"}},
+		{locale: "fr", accepted: []string{"en"}, err: true},
+	}
+	for _, tt := range tests {
+		ctx := context.WithValue(context.Background(), localesKey{}, tt.accepted)
+		var locale *string
+		if tt.locale != "" {
+			locale = &tt.locale
+		}
+		got, err := localeChain(ctx, locale)
+		if tt.err {
+			if errCode(err) != errs.InvalidArgument {
+				t.Errorf("localeChain(%q, %q) error = %v, want INVALID_ARGUMENT", tt.locale, tt.accepted, err)
+			}
+			continue
+		}
+		if err != nil || fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("localeChain(%q, %q) = %q, %v, want %q", tt.locale, tt.accepted, got, err, tt.want)
+		}
+	}
+}
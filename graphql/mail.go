@@ -0,0 +1,133 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	netmail "net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"encore.dev/rlog"
+)
+
+// mailMessage is a plain-text email.
+type mailMessage struct {
+	to      string
+	subject string
+	text    string
+	// headers are added to the standard ones.
+	headers map[string]string
+}
+
+// mailer sends email.
+type mailer interface {
+	send(ctx context.Context, m *mailMessage) error
+}
+
+// newMailer returns the mailer configured under Mail.
+func newMailer() (mailer, error) {
+	from, err := netmail.ParseAddress(cfg.Mail.From())
+	if err != nil {
+		return nil, fmt.Errorf("Mail.From: %w", err)
+	}
+	switch kind := cfg.Mail.Mailer(); kind {
+	case "smtp":
+		m := &smtpMailer{addr: cfg.Mail.SMTPAddr(), from: from}
+		if user := cfg.Mail.SMTPUsername(); user != "" {
+			host, _, _ := strings.Cut(m.addr, ":")
+			m.auth = smtp.PlainAuth("", user, secrets.SMTPPassword, host)
+		}
+		return m, nil
+	case "file":
+		return &fileMailer{dir: cfg.Mail.Dir(), from: from}, nil
+	case "log":
+		return logMailer{}, nil
+	default:
+		return nil, fmt.Errorf("Mail.Mailer: unknown mailer %q", kind)
+	}
+}
+
+// smtpMailer sends email through an SMTP server, with STARTTLS if the server
+// offers it.
+type smtpMailer struct {
+	addr string
+	from *netmail.Address
+	auth smtp.Auth
+}
+
+func (m *smtpMailer) send(_ context.Context, msg *mailMessage) error {
+	data, err := formatMail(m.from, msg)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(m.addr, m.auth, m.from.Address, []string{msg.to}, data)
+}
+
+// fileMailer writes every email to its own .eml file, for development.
+type fileMailer struct {
+	dir  string
+	from *netmail.Address
+}
+
+func (m *fileMailer) send(_ context.Context, msg *mailMessage) error {
+	data, err := formatMail(m.from, msg)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+	var suffix [4]byte
+	rand.Read(suffix[:])
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000"), hex.EncodeToString(suffix[:]))
+	return os.WriteFile(filepath.Join(m.dir, name), data, 0o644)
+}
+
+// logMailer only logs every email, for development and tests.
+type logMailer struct{}
+
+func (logMailer) send(_ context.Context, msg *mailMessage) error {
+	rlog.Info("graphql: mail", "to", msg.to, "subject", msg.subject, "text", msg.text)
+	return nil
+}
+
+// formatMail renders msg as an RFC 5322 message from from.
+func formatMail(from *netmail.Address, msg *mailMessage) ([]byte, error) {
+	to, err := netmail.ParseAddress(msg.to)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		// Header values must not be able to start new headers.
+		value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+	header("From", from.String())
+	header("To", to.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	for name, value := range msg.headers {
+		header(name, value)
+	}
+	buf.WriteString("\r\n")
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(msg.text, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -1 +1,4 @@
 package model
+
+// Node is any object that can be fetched through Query.node by its global ID.
+type Node any
@@ -2,21 +2,129 @@
 
 package model
 
-type CreateBlogInput struct {
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"encore.app/app"
+)
+
+type BlogByIDsInput struct {
+	ID string `json:"ID"`
+}
+
+type BlogFilter struct {
+	TitleContains *string    `json:"titleContains,omitempty"`
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"`
+	CreatedAfter  *time.Time `json:"createdAfter,omitempty"`
+}
+
+type BlogTranslationInput struct {
+	Locale  string `json:"locale"`
 	Title   string `json:"title"`
 	Content string `json:"content"`
 }
 
+type ConfirmSubscriptionPayload struct {
+	Confirmed        bool         `json:"confirmed"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type CreateAPIKeyPayload struct {
+	APIKey *app.ApiKey `json:"apiKey,omitempty"`
+	// The secret key. Store it safely, it cannot be retrieved again.
+	Key              *string      `json:"key,omitempty"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type CreateBlogInput struct {
+	Title     string     `json:"title"`
+	Content   string     `json:"content"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	Draft     *bool      `json:"draft,omitempty"`
+}
+
+type CreateBlogPayload struct {
+	Blog             *app.Blog    `json:"blog,omitempty"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type CreateBlogsPayload struct {
+	// Created blogs in input order, null where the item failed or the batch was rolled back.
+	Blogs            []*app.Blog  `json:"blogs"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type CreatePreviewLinkPayload struct {
+	// The preview page, from Previews.URL.
+	URL *string `json:"url,omitempty"`
+	// Pass it as previewToken to blog or project.
+	Token            *string      `json:"token,omitempty"`
+	ExpiresAt        *time.Time   `json:"expiresAt,omitempty"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
 type CreateProjectInput struct {
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	UserID      string `json:"userID"`
+	Hidden      *bool  `json:"hidden,omitempty"`
+}
+
+type CreateProjectPayload struct {
+	Project          *app.Project `json:"project,omitempty"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type CreateProjectsPayload struct {
+	// Created projects in input order, null where the item failed or the batch was rolled back.
+	Projects         []*app.Project `json:"projects"`
+	UserErrors       []*UserError   `json:"userErrors"`
+	ClientMutationID *string        `json:"clientMutationId,omitempty"`
 }
 
 type CreateResumeInput struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Category    string `json:"category"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Category    string     `json:"category"`
+	StartDate   *time.Time `json:"startDate,omitempty"`
+	EndDate     *time.Time `json:"endDate,omitempty"`
+}
+
+type CreateResumePayload struct {
+	Resume           *app.Resume  `json:"resume,omitempty"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type CreateResumesPayload struct {
+	// Created resumes in input order, null where the item failed or the batch was rolled back.
+	Resumes          []*app.Resume `json:"resumes"`
+	UserErrors       []*UserError  `json:"userErrors"`
+	ClientMutationID *string       `json:"clientMutationId,omitempty"`
+}
+
+type CreateTestimonialInput struct {
+	Author  string     `json:"author"`
+	Quote   string     `json:"quote"`
+	Rating  *int       `json:"rating,omitempty"`
+	GivenOn *time.Time `json:"givenOn,omitempty"`
+	Website *string    `json:"website,omitempty"`
+}
+
+type CreateTestimonialPayload struct {
+	Testimonial      *app.Testimonial `json:"testimonial,omitempty"`
+	UserErrors       []*UserError     `json:"userErrors"`
+	ClientMutationID *string          `json:"clientMutationId,omitempty"`
 }
 
 type CreateUserInput struct {
@@ -24,30 +132,898 @@ type CreateUserInput struct {
 	Email string `json:"email"`
 }
 
+type CreateUserPayload struct {
+	User             *app.User    `json:"user,omitempty"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type CreateUsersPayload struct {
+	// Created users in input order, null where the item failed or the batch was rolled back.
+	Users            []*app.User  `json:"users"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type CreateWebhookPayload struct {
+	Webhook          *app.Webhook `json:"webhook,omitempty"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type DailyViews struct {
+	Date  time.Time `json:"date"`
+	Views int       `json:"views"`
+}
+
+type DeleteBlogPayload struct {
+	DeletedID        *string      `json:"deletedId,omitempty"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type DeleteBlogsPayload struct {
+	DeletedIds       []string     `json:"deletedIds"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type DeleteProjectPayload struct {
+	DeletedID        *string      `json:"deletedId,omitempty"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type DeleteProjectsPayload struct {
+	DeletedIds       []string     `json:"deletedIds"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type DeleteResumePayload struct {
+	DeletedID        *string      `json:"deletedId,omitempty"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type DeleteResumesPayload struct {
+	DeletedIds       []string     `json:"deletedIds"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type DeleteTestimonialPayload struct {
+	DeletedID        *string      `json:"deletedId,omitempty"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type DeleteUserPayload struct {
+	DeletedID        *string      `json:"deletedId,omitempty"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type DeleteUsersPayload struct {
+	DeletedIds       []string     `json:"deletedIds"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type DeleteWebhookPayload struct {
+	DeletedID        *string      `json:"deletedId,omitempty"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
 type Mutation struct {
 }
 
+// A page of a connection, see Generated Content Types in the README.
+type PageInfo struct {
+	HasNextPage bool `json:"hasNextPage"`
+	// The cursor of the last edge of the page, to pass as after for the next page.
+	EndCursor *string `json:"endCursor,omitempty"`
+}
+
+type ProjectByIDsInput struct {
+	ID string `json:"ID"`
+}
+
+type ProjectFilter struct {
+	UserID        *string `json:"userID,omitempty"`
+	TitleContains *string `json:"titleContains,omitempty"`
+}
+
+type ProjectTranslationInput struct {
+	Locale      string `json:"locale"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
 type Query struct {
 }
 
+type Reaction struct {
+	Kind  ReactionKind `json:"kind"`
+	Count int          `json:"count"`
+	// Whether the caller reacted with this kind.
+	ViewerHasReacted bool `json:"viewerHasReacted"`
+}
+
+type ReactionPayload struct {
+	// The reactions to the post after the change.
+	Reactions        []*Reaction  `json:"reactions,omitempty"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type RecordViewPayload struct {
+	// Whether the view was counted. Repeated views are not.
+	Recorded         bool         `json:"recorded"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type ReferrerViews struct {
+	// Host of the referring page, null for direct visits.
+	Referrer *string `json:"referrer,omitempty"`
+	Views    int     `json:"views"`
+}
+
+type ResumeByIDsInput struct {
+	ID string `json:"ID"`
+}
+
+type ResumeFilter struct {
+	Category      *string `json:"category,omitempty"`
+	TitleContains *string `json:"titleContains,omitempty"`
+}
+
+type ResumeTranslationInput struct {
+	Locale      string `json:"locale"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+type RevokeAPIKeyPayload struct {
+	APIKey           *app.ApiKey  `json:"apiKey,omitempty"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type SubmitContactMessagePayload struct {
+	// Whether the message was accepted. It is true for messages dropped as spam too.
+	Accepted         bool         `json:"accepted"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type SubscribeNewsletterPayload struct {
+	// Whether the request was accepted. It does not tell whether the address was already subscribed.
+	Accepted         bool         `json:"accepted"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type Subscription struct {
+}
+
+type TestimonialConnection struct {
+	Edges    []*TestimonialEdge `json:"edges"`
+	PageInfo *PageInfo          `json:"pageInfo"`
+}
+
+type TestimonialEdge struct {
+	// Pass it as after to list the testimonials that follow.
+	Cursor string           `json:"cursor"`
+	Node   *app.Testimonial `json:"node"`
+}
+
+type TestimonialFilter struct {
+	AuthorContains *string    `json:"authorContains,omitempty"`
+	GivenOnBefore  *time.Time `json:"givenOnBefore,omitempty"`
+	GivenOnAfter   *time.Time `json:"givenOnAfter,omitempty"`
+}
+
 type UpdateBlogInput struct {
 	Title   *string `json:"title,omitempty"`
 	Content *string `json:"content,omitempty"`
+	// Publishes a draft when set to false.
+	Draft *bool `json:"draft,omitempty"`
+	// Reject the update with CONFLICT unless the record is still at this version.
+	ExpectedVersion *int `json:"expectedVersion,omitempty"`
+}
+
+type UpdateBlogItem struct {
+	ID    string           `json:"id"`
+	Input *UpdateBlogInput `json:"input"`
+}
+
+type UpdateBlogPayload struct {
+	Blog             *app.Blog    `json:"blog,omitempty"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type UpdateBlogsPayload struct {
+	// Updated blogs in input order, null where the item failed or the batch was rolled back.
+	Blogs            []*app.Blog  `json:"blogs"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type UpdateContactMessagePayload struct {
+	Message          *app.ContactMessage `json:"message,omitempty"`
+	UserErrors       []*UserError        `json:"userErrors"`
+	ClientMutationID *string             `json:"clientMutationId,omitempty"`
 }
 
 type UpdateProjectInput struct {
 	Title       *string `json:"title,omitempty"`
 	Description *string `json:"description,omitempty"`
 	UserID      *string `json:"userID,omitempty"`
+	Hidden      *bool   `json:"hidden,omitempty"`
+	// Reject the update with CONFLICT unless the record is still at this version.
+	ExpectedVersion *int `json:"expectedVersion,omitempty"`
+}
+
+type UpdateProjectItem struct {
+	ID    string              `json:"id"`
+	Input *UpdateProjectInput `json:"input"`
+}
+
+type UpdateProjectPayload struct {
+	Project          *app.Project `json:"project,omitempty"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type UpdateProjectsPayload struct {
+	// Updated projects in input order, null where the item failed or the batch was rolled back.
+	Projects         []*app.Project `json:"projects"`
+	UserErrors       []*UserError   `json:"userErrors"`
+	ClientMutationID *string        `json:"clientMutationId,omitempty"`
 }
 
 type UpdateResumeInput struct {
-	Title       *string `json:"title,omitempty"`
-	Description *string `json:"description,omitempty"`
-	Category    *string `json:"category,omitempty"`
+	Title       *string    `json:"title,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	Category    *string    `json:"category,omitempty"`
+	StartDate   *time.Time `json:"startDate,omitempty"`
+	EndDate     *time.Time `json:"endDate,omitempty"`
+	// Reject the update with CONFLICT unless the record is still at this version.
+	ExpectedVersion *int `json:"expectedVersion,omitempty"`
+}
+
+type UpdateResumeItem struct {
+	ID    string             `json:"id"`
+	Input *UpdateResumeInput `json:"input"`
+}
+
+type UpdateResumePayload struct {
+	Resume           *app.Resume  `json:"resume,omitempty"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type UpdateResumesPayload struct {
+	// Updated resumes in input order, null where the item failed or the batch was rolled back.
+	Resumes          []*app.Resume `json:"resumes"`
+	UserErrors       []*UserError  `json:"userErrors"`
+	ClientMutationID *string       `json:"clientMutationId,omitempty"`
+}
+
+type UpdateTestimonialInput struct {
+	Author  *string    `json:"author,omitempty"`
+	Quote   *string    `json:"quote,omitempty"`
+	Rating  *int       `json:"rating,omitempty"`
+	GivenOn *time.Time `json:"givenOn,omitempty"`
+	Website *string    `json:"website,omitempty"`
+	// Reject the update with CONFLICT unless the record is still at this version.
+	ExpectedVersion *int `json:"expectedVersion,omitempty"`
+}
+
+type UpdateTestimonialPayload struct {
+	Testimonial      *app.Testimonial `json:"testimonial,omitempty"`
+	UserErrors       []*UserError     `json:"userErrors"`
+	ClientMutationID *string          `json:"clientMutationId,omitempty"`
 }
 
 type UpdateUserInput struct {
 	Name  *string `json:"name,omitempty"`
 	Email *string `json:"email,omitempty"`
+	// Reject the update with CONFLICT unless the record is still at this version.
+	ExpectedVersion *int `json:"expectedVersion,omitempty"`
+}
+
+type UpdateUserItem struct {
+	ID    string           `json:"id"`
+	Input *UpdateUserInput `json:"input"`
+}
+
+type UpdateUserPayload struct {
+	User             *app.User    `json:"user,omitempty"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type UpdateUsersPayload struct {
+	// Updated users in input order, null where the item failed or the batch was rolled back.
+	Users            []*app.User  `json:"users"`
+	UserErrors       []*UserError `json:"userErrors"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+}
+
+type UpsertBlogTranslationPayload struct {
+	Translation      *app.BlogTranslation `json:"translation,omitempty"`
+	UserErrors       []*UserError         `json:"userErrors"`
+	ClientMutationID *string              `json:"clientMutationId,omitempty"`
+}
+
+type UpsertProjectTranslationPayload struct {
+	Translation      *app.ProjectTranslation `json:"translation,omitempty"`
+	UserErrors       []*UserError            `json:"userErrors"`
+	ClientMutationID *string                 `json:"clientMutationId,omitempty"`
+}
+
+type UpsertResumeTranslationPayload struct {
+	Translation      *app.ResumeTranslation `json:"translation,omitempty"`
+	UserErrors       []*UserError           `json:"userErrors"`
+	ClientMutationID *string                `json:"clientMutationId,omitempty"`
+}
+
+type UserByIDsInput struct {
+	ID string `json:"ID"`
+}
+
+// An expected failure of a mutation, reported instead of a top-level error.
+type UserError struct {
+	// Path to the offending argument or input field, e.g. ["input", "email"].
+	Field   []string  `json:"field,omitempty"`
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+	// The current server copy of the record when an update was rejected because of a stale expectedVersion.
+	Current Node `json:"current,omitempty"`
+}
+
+type UserFilter struct {
+	NameContains  *string    `json:"nameContains,omitempty"`
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"`
+	CreatedAfter  *time.Time `json:"createdAfter,omitempty"`
+}
+
+type ViewStats struct {
+	Total int `json:"total"`
+	// Every day of the range, including those without views.
+	Days []*DailyViews `json:"days"`
+	// The referrers of the range, most views first.
+	Referrers []*ReferrerViews `json:"referrers"`
+}
+
+type CacheControlScope string
+
+const (
+	// Shared caches such as CDNs may store the response.
+	CacheControlScopePublic CacheControlScope = "PUBLIC"
+	// Only the client's own cache may store the response.
+	CacheControlScopePrivate CacheControlScope = "PRIVATE"
+)
+
+var AllCacheControlScope = []CacheControlScope{
+	CacheControlScopePublic,
+	CacheControlScopePrivate,
+}
+
+func (e CacheControlScope) IsValid() bool {
+	switch e {
+	case CacheControlScopePublic, CacheControlScopePrivate:
+		return true
+	}
+	return false
+}
+
+func (e CacheControlScope) String() string {
+	return string(e)
+}
+
+func (e *CacheControlScope) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CacheControlScope(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CacheControlScope", str)
+	}
+	return nil
+}
+
+func (e CacheControlScope) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *CacheControlScope) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e CacheControlScope) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ConstraintFormat string
+
+const (
+	ConstraintFormatEmail ConstraintFormat = "EMAIL"
+	ConstraintFormatURL   ConstraintFormat = "URL"
+)
+
+var AllConstraintFormat = []ConstraintFormat{
+	ConstraintFormatEmail,
+	ConstraintFormatURL,
+}
+
+func (e ConstraintFormat) IsValid() bool {
+	switch e {
+	case ConstraintFormatEmail, ConstraintFormatURL:
+		return true
+	}
+	return false
+}
+
+func (e ConstraintFormat) String() string {
+	return string(e)
+}
+
+func (e *ConstraintFormat) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ConstraintFormat(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ConstraintFormat", str)
+	}
+	return nil
+}
+
+func (e ConstraintFormat) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ConstraintFormat) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ConstraintFormat) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ContactMessageStatus string
+
+const (
+	ContactMessageStatusUnread   ContactMessageStatus = "UNREAD"
+	ContactMessageStatusRead     ContactMessageStatus = "READ"
+	ContactMessageStatusArchived ContactMessageStatus = "ARCHIVED"
+)
+
+var AllContactMessageStatus = []ContactMessageStatus{
+	ContactMessageStatusUnread,
+	ContactMessageStatusRead,
+	ContactMessageStatusArchived,
+}
+
+func (e ContactMessageStatus) IsValid() bool {
+	switch e {
+	case ContactMessageStatusUnread, ContactMessageStatusRead, ContactMessageStatusArchived:
+		return true
+	}
+	return false
+}
+
+func (e ContactMessageStatus) String() string {
+	return string(e)
+}
+
+func (e *ContactMessageStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ContactMessageStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ContactMessageStatus", str)
+	}
+	return nil
+}
+
+func (e ContactMessageStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ContactMessageStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ContactMessageStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+// What happens to the projects of a deleted user.
+type DeleteUserStrategy string
+
+const (
+	// Delete the projects together with the user.
+	DeleteUserStrategyCascade DeleteUserStrategy = "CASCADE"
+	// Move the projects to the user given as toUserId.
+	DeleteUserStrategyReassign DeleteUserStrategy = "REASSIGN"
+	// Refuse to delete a user that still owns projects.
+	DeleteUserStrategyRestrict DeleteUserStrategy = "RESTRICT"
+)
+
+var AllDeleteUserStrategy = []DeleteUserStrategy{
+	DeleteUserStrategyCascade,
+	DeleteUserStrategyReassign,
+	DeleteUserStrategyRestrict,
+}
+
+func (e DeleteUserStrategy) IsValid() bool {
+	switch e {
+	case DeleteUserStrategyCascade, DeleteUserStrategyReassign, DeleteUserStrategyRestrict:
+		return true
+	}
+	return false
+}
+
+func (e DeleteUserStrategy) String() string {
+	return string(e)
+}
+
+func (e *DeleteUserStrategy) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DeleteUserStrategy(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DeleteUserStrategy", str)
+	}
+	return nil
+}
+
+func (e DeleteUserStrategy) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *DeleteUserStrategy) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e DeleteUserStrategy) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ErrorCode string
+
+const (
+	ErrorCodeNotFound         ErrorCode = "NOT_FOUND"
+	ErrorCodeValidationFailed ErrorCode = "VALIDATION_FAILED"
+	ErrorCodeConflict         ErrorCode = "CONFLICT"
+	ErrorCodeUnauthenticated  ErrorCode = "UNAUTHENTICATED"
+	ErrorCodeForbidden        ErrorCode = "FORBIDDEN"
+)
+
+var AllErrorCode = []ErrorCode{
+	ErrorCodeNotFound,
+	ErrorCodeValidationFailed,
+	ErrorCodeConflict,
+	ErrorCodeUnauthenticated,
+	ErrorCodeForbidden,
+}
+
+func (e ErrorCode) IsValid() bool {
+	switch e {
+	case ErrorCodeNotFound, ErrorCodeValidationFailed, ErrorCodeConflict, ErrorCodeUnauthenticated, ErrorCodeForbidden:
+		return true
+	}
+	return false
+}
+
+func (e ErrorCode) String() string {
+	return string(e)
+}
+
+func (e *ErrorCode) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ErrorCode(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ErrorCode", str)
+	}
+	return nil
+}
+
+func (e ErrorCode) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ErrorCode) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ErrorCode) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ReactionKind string
+
+const (
+	ReactionKindLike  ReactionKind = "LIKE"
+	ReactionKindClap  ReactionKind = "CLAP"
+	ReactionKindHeart ReactionKind = "HEART"
+	ReactionKindLaugh ReactionKind = "LAUGH"
+	ReactionKindFire  ReactionKind = "FIRE"
+)
+
+var AllReactionKind = []ReactionKind{
+	ReactionKindLike,
+	ReactionKindClap,
+	ReactionKindHeart,
+	ReactionKindLaugh,
+	ReactionKindFire,
+}
+
+func (e ReactionKind) IsValid() bool {
+	switch e {
+	case ReactionKindLike, ReactionKindClap, ReactionKindHeart, ReactionKindLaugh, ReactionKindFire:
+		return true
+	}
+	return false
+}
+
+func (e ReactionKind) String() string {
+	return string(e)
+}
+
+func (e *ReactionKind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReactionKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReactionKind", str)
+	}
+	return nil
+}
+
+func (e ReactionKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ReactionKind) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ReactionKind) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ViewPeriod string
+
+const (
+	// Today, in UTC.
+	ViewPeriodDay ViewPeriod = "DAY"
+	// The last 7 days, including today.
+	ViewPeriodWeek ViewPeriod = "WEEK"
+	// The last 30 days, including today.
+	ViewPeriodMonth ViewPeriod = "MONTH"
+)
+
+var AllViewPeriod = []ViewPeriod{
+	ViewPeriodDay,
+	ViewPeriodWeek,
+	ViewPeriodMonth,
+}
+
+func (e ViewPeriod) IsValid() bool {
+	switch e {
+	case ViewPeriodDay, ViewPeriodWeek, ViewPeriodMonth:
+		return true
+	}
+	return false
+}
+
+func (e ViewPeriod) String() string {
+	return string(e)
+}
+
+func (e *ViewPeriod) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ViewPeriod(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ViewPeriod", str)
+	}
+	return nil
+}
+
+func (e ViewPeriod) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ViewPeriod) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ViewPeriod) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ViewedEntity string
+
+const (
+	ViewedEntityBlog    ViewedEntity = "BLOG"
+	ViewedEntityProject ViewedEntity = "PROJECT"
+)
+
+var AllViewedEntity = []ViewedEntity{
+	ViewedEntityBlog,
+	ViewedEntityProject,
+}
+
+func (e ViewedEntity) IsValid() bool {
+	switch e {
+	case ViewedEntityBlog, ViewedEntityProject:
+		return true
+	}
+	return false
+}
+
+func (e ViewedEntity) String() string {
+	return string(e)
+}
+
+func (e *ViewedEntity) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ViewedEntity(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ViewedEntity", str)
+	}
+	return nil
+}
+
+func (e ViewedEntity) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ViewedEntity) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ViewedEntity) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	// Waiting for its first or next attempt.
+	WebhookDeliveryStatusPending WebhookDeliveryStatus = "PENDING"
+	// The endpoint answered with a 2xx status.
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "SUCCEEDED"
+	// Every attempt failed; the delivery is not retried anymore.
+	WebhookDeliveryStatusFailed WebhookDeliveryStatus = "FAILED"
+)
+
+var AllWebhookDeliveryStatus = []WebhookDeliveryStatus{
+	WebhookDeliveryStatusPending,
+	WebhookDeliveryStatusSucceeded,
+	WebhookDeliveryStatusFailed,
+}
+
+func (e WebhookDeliveryStatus) IsValid() bool {
+	switch e {
+	case WebhookDeliveryStatusPending, WebhookDeliveryStatusSucceeded, WebhookDeliveryStatusFailed:
+		return true
+	}
+	return false
+}
+
+func (e WebhookDeliveryStatus) String() string {
+	return string(e)
+}
+
+func (e *WebhookDeliveryStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WebhookDeliveryStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WebhookDeliveryStatus", str)
+	}
+	return nil
+}
+
+func (e WebhookDeliveryStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *WebhookDeliveryStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e WebhookDeliveryStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
 }
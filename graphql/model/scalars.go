@@ -0,0 +1,140 @@
+package model
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // timezone arguments must work on hosts without a zoneinfo database
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// DateLayout is the wire format of the Date scalar.
+const DateLayout = "2006-01-02"
+
+// MarshalDateTime writes t as an RFC 3339 string in UTC.
+//
+// Fields declaring optional `format` and `timezone` arguments are rendered in
+// that timezone and, when a format is given, as a display string instead.
+func MarshalDateTime(t time.Time) graphql.ContextMarshaler {
+	return graphql.ContextWriterFunc(func(ctx context.Context, w io.Writer) error {
+		loc := time.UTC
+		var format string
+		if fc := graphql.GetFieldContext(ctx); fc != nil {
+			if tz, ok := fc.Args["timezone"].(*string); ok && tz != nil {
+				l, err := time.LoadLocation(*tz)
+				if err != nil {
+					return gqlerror.Errorf("unknown timezone %q", *tz)
+				}
+				loc = l
+			}
+			if f, ok := fc.Args["format"].(*string); ok && f != nil {
+				format = *f
+			}
+		}
+		t = t.In(loc)
+		if format == "" {
+			io.WriteString(w, strconv.Quote(t.Format(time.RFC3339)))
+		} else {
+			io.WriteString(w, strconv.Quote(FormatDateTime(t, format)))
+		}
+		return nil
+	})
+}
+
+// The errors of the scalars are *gqlerror.Error, which the error presenter
+// passes on to clients as they are.
+
+// UnmarshalDateTime parses an RFC 3339 string. An explicit offset is required
+// so that the instant is unambiguous; the result is normalized to UTC.
+func UnmarshalDateTime(ctx context.Context, v any) (time.Time, error) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, gqlerror.Errorf("DateTime must be an RFC 3339 string")
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, gqlerror.Errorf("DateTime must be an RFC 3339 string with a timezone offset, got %q", s)
+	}
+	return t.UTC(), nil
+}
+
+// MarshalDate writes the calendar date of t as YYYY-MM-DD.
+func MarshalDate(t time.Time) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		io.WriteString(w, strconv.Quote(t.Format(DateLayout)))
+	})
+}
+
+// UnmarshalDate parses a YYYY-MM-DD string into midnight UTC of that day.
+func UnmarshalDate(v any) (time.Time, error) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, gqlerror.Errorf("Date must be a YYYY-MM-DD string")
+	}
+	t, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return time.Time{}, gqlerror.Errorf("Date must be a YYYY-MM-DD string, got %q", s)
+	}
+	return t, nil
+}
+
+// formatTokens maps display format tokens to Go layout elements. Longer tokens
+// come first so that "MMMM" wins over "MM".
+var formatTokens = []struct{ token, layout string }{
+	{"YYYY", "2006"},
+	{"YY", "06"},
+	{"MMMM", "January"},
+	{"MMM", "Jan"},
+	{"MM", "01"},
+	{"M", "1"},
+	{"DD", "02"},
+	{"D", "2"},
+	{"dddd", "Monday"},
+	{"ddd", "Mon"},
+	{"HH", "15"},
+	{"hh", "03"},
+	{"h", "3"},
+	{"mm", "04"},
+	{"m", "4"},
+	{"ss", "05"},
+	{"s", "5"},
+	{"A", "PM"},
+	{"a", "pm"},
+	{"ZZ", "-0700"},
+	{"Z", "-07:00"},
+	{"z", "MST"},
+}
+
+// FormatDateTime renders t using day.js style tokens, e.g. "D MMMM YYYY, HH:mm".
+// Text wrapped in square brackets is copied through literally, as is any
+// character that is not part of a token.
+func FormatDateTime(t time.Time, format string) string {
+	var b strings.Builder
+	for i := 0; i < len(format); {
+		if format[i] == '[' {
+			if end := strings.IndexByte(format[i:], ']'); end > 0 {
+				b.WriteString(format[i+1 : i+end])
+				i += end + 1
+				continue
+			}
+		}
+		matched := false
+		for _, ft := range formatTokens {
+			if strings.HasPrefix(format[i:], ft.token) {
+				b.WriteString(t.Format(ft.layout))
+				i += len(ft.token)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteByte(format[i])
+			i++
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,169 @@
+//go:build encore_app
+
+package model
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+func TestFormatDateTime(t *testing.T) {
+	at := time.Date(2024, time.March, 5, 14, 7, 9, 0, time.FixedZone("WIB", 7*60*60))
+	for _, tt := range []struct {
+		format string
+		want   string
+	}{
+		{"YYYY-MM-DD", "2024-03-05"},
+		{"YY M D", "24 3 5"},
+		{"D MMMM YYYY, HH:mm", "5 March 2024, 14:07"},
+		{"ddd, D MMM", "Tue, 5 Mar"},
+		{"dddd", "Tuesday"},
+		{"hh:mm:ss A", "02:07:09 PM"},
+		{"h:m:s a", "2:7:9 pm"},
+		{"HH:mm Z", "14:07 +07:00"},
+		{"HH:mm ZZ z", "14:07 +0700 WIB"},
+		{"[Today is] dddd", "Today is Tuesday"},
+		{"[YYYY] YYYY", "YYYY 2024"},
+		{"YYYY [", "2024 ["},
+		{"", ""},
+	} {
+		if got := FormatDateTime(at, tt.format); got != tt.want {
+			t.Errorf("FormatDateTime(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+// marshal writes m with the field arguments args, as a resolved field would.
+func marshal(t *testing.T, m graphql.ContextMarshaler, args map[string]any) (string, error) {
+	t.Helper()
+	ctx := graphql.WithFieldContext(context.Background(), &graphql.FieldContext{Args: args})
+	var b bytes.Buffer
+	if err := m.MarshalGQLContext(ctx, &b); err != nil {
+		return "", err
+	}
+	return mustUnquote(t, b.String()), nil
+}
+
+func TestMarshalDateTime(t *testing.T) {
+	at := time.Date(2024, time.March, 5, 23, 30, 0, 0, time.UTC)
+	str := func(s string) *string { return &s }
+	for _, tt := range []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{"no arguments", nil, "2024-03-05T23:30:00Z"},
+		{"null arguments", map[string]any{"timezone": (*string)(nil), "format": (*string)(nil)}, "2024-03-05T23:30:00Z"},
+		{"timezone", map[string]any{"timezone": str("Asia/Jakarta")}, "2024-03-06T06:30:00+07:00"},
+		{"format", map[string]any{"format": str("D MMM YYYY")}, "5 Mar 2024"},
+		{"both", map[string]any{"timezone": str("Asia/Jakarta"), "format": str("D MMM YYYY HH:mm")}, "6 Mar 2024 06:30"},
+	} {
+		got, err := marshal(t, MarshalDateTime(at), tt.args)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: got %q, %v; want %q", tt.name, got, err, tt.want)
+		}
+	}
+
+	if _, err := marshal(t, MarshalDateTime(at), map[string]any{"timezone": str("Mars/Olympus_Mons")}); err == nil {
+		t.Error("an unknown timezone was accepted")
+	}
+}
+
+func TestUnmarshalDateTime(t *testing.T) {
+	for _, tt := range []struct {
+		in      any
+		want    time.Time
+		wantErr bool
+	}{
+		{in: "2024-03-05T23:30:00Z", want: time.Date(2024, time.March, 5, 23, 30, 0, 0, time.UTC)},
+		{in: "2024-03-06T06:30:00+07:00", want: time.Date(2024, time.March, 5, 23, 30, 0, 0, time.UTC)},
+		{in: "2024-03-05T23:30:00.5Z", want: time.Date(2024, time.March, 5, 23, 30, 0, 5e8, time.UTC)},
+		{in: "2024-03-05T23:30:00", wantErr: true},
+		{in: "2024-03-05", wantErr: true},
+		{in: "yesterday", wantErr: true},
+		{in: 1709681400, wantErr: true},
+	} {
+		got, err := UnmarshalDateTime(context.Background(), tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("UnmarshalDateTime(%v) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) || got.Location() != time.UTC {
+			t.Errorf("UnmarshalDateTime(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalDate(t *testing.T) {
+	for _, tt := range []struct {
+		in      any
+		want    time.Time
+		wantErr bool
+	}{
+		{in: "2024-03-05", want: time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)},
+		{in: "2024-02-29", want: time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{in: "2023-02-29", wantErr: true},
+		{in: "2024-03-05T00:00:00Z", wantErr: true},
+		{in: "5 March 2024", wantErr: true},
+		{in: nil, wantErr: true},
+	} {
+		got, err := UnmarshalDate(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("UnmarshalDate(%v) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("UnmarshalDate(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	var b bytes.Buffer
+	MarshalDate(time.Date(2024, time.March, 5, 23, 30, 0, 0, time.UTC)).MarshalGQL(&b)
+	if b.String() != `"2024-03-05"` {
+		t.Errorf("MarshalDate = %s", b.String())
+	}
+}
+
+// The Duration scalar is mapped onto the ISO 8601 marshalers of gqlgen.
+func TestDuration(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want time.Duration
+	}{
+		{"P7D", 7 * 24 * time.Hour},
+		{"PT4M30S", 4*time.Minute + 30*time.Second},
+		{"PT1H", time.Hour},
+		{"PT0.5S", 500 * time.Millisecond},
+	} {
+		got, err := graphql.UnmarshalDuration(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("UnmarshalDuration(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+			continue
+		}
+		var b bytes.Buffer
+		graphql.MarshalDuration(got).MarshalGQL(&b)
+		back, err := graphql.UnmarshalDuration(mustUnquote(t, b.String()))
+		if err != nil || back != tt.want {
+			t.Errorf("%q did not survive a round trip: %s", tt.in, b.String())
+		}
+	}
+	for _, in := range []any{"7 days", "", 3600} {
+		if _, err := graphql.UnmarshalDuration(in); err == nil {
+			t.Errorf("UnmarshalDuration(%v) was accepted", in)
+		}
+	}
+}
+
+func mustUnquote(t *testing.T, s string) string {
+	t.Helper()
+	u, err := strconv.Unquote(s)
+	if err != nil {
+		t.Fatalf("%s: %v", s, err)
+	}
+	return u
+}
@@ -0,0 +1,180 @@
+package graphql
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"encore.app/app"
+	"encore.dev/beta/errs"
+	"encore.dev/pubsub"
+	"encore.dev/rlog"
+)
+
+var (
+	_ = pubsub.NewSubscription(app.BlogEvents, "blog-events-newsletter", pubsub.SubscriptionConfig[*app.BlogEvent]{
+		Handler: pubsub.MethodHandler((*Service).QueueNewsletter),
+	})
+	_ = pubsub.NewSubscription(app.NewsletterEmails, "newsletter-emails-send", pubsub.SubscriptionConfig[*app.NewsletterEmail]{
+		Handler: pubsub.MethodHandler((*Service).SendNewsletterEmail),
+	})
+)
+
+// QueueNewsletter queues an email about every newly published blog post to
+// the confirmed subscribers of its site.
+func (s *Service) QueueNewsletter(ctx context.Context, e *app.BlogEvent) error {
+	if e.Meta.Type != app.BlogPublished {
+		return nil
+	}
+	subject, text := newsletterDigest(&e.Blog)
+	return app.QueueNewsletterIssue(ctx, e.Meta.SiteID, &app.QueueNewsletterIssueParams{EventID: e.Meta.ID, Subject: subject, Text: text})
+}
+
+// newsletterDigest returns the subject and text of the email about a post.
+func newsletterDigest(b *app.BlogSnapshot) (subject, text string) {
+	excerpt := strings.TrimSpace(b.Content)
+	if n := cfg.Newsletter.ExcerptLength(); utf8.RuneCountInString(excerpt) > n {
+		excerpt = strings.TrimSpace(string([]rune(excerpt)[:n])) + "…"
+	}
+	return "New post: " + b.Title, b.Title + "\n\n" + excerpt + "\n"
+}
+
+// SendNewsletterEmail mails a queued email, unless its subscriber has
+// unsubscribed in the meantime.
+func (s *Service) SendNewsletterEmail(ctx context.Context, e *app.NewsletterEmail) error {
+	sub, err := app.GetNewsletterSubscriber(ctx, e.SiteID, e.SubscriberID)
+	if isNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	m := &mailMessage{to: sub.Email, subject: e.Subject, text: e.Text}
+	if e.Confirmation {
+		link := cfg.Newsletter.ConfirmURL() + url.QueryEscape(confirmToken(sub.ID, sub.SubscribedAt))
+		m.subject = "Confirm your subscription"
+		m.text = "Please confirm that you want to receive the newsletter:\n\n" + link + "\n\n" +
+			"If you did not ask for it, ignore this email and you will not hear from us again.\n"
+	} else {
+		if !sub.Active() {
+			return nil
+		}
+		link := cfg.Newsletter.UnsubscribeURL() + url.QueryEscape(unsubscribeToken(sub.ID))
+		m.text += "\n--\nUnsubscribe: " + link + "\n"
+		// RFC 8058 one-click unsubscribe.
+		m.headers = map[string]string{
+			"List-Unsubscribe":      "<" + link + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		}
+	}
+	return s.mailer.send(ctx, m)
+}
+
+// subscribe records that email asked for the newsletter, which queues the
+// email asking to confirm it.
+func subscribe(ctx context.Context, email string) error {
+	if secrets.NewsletterSecret == "" {
+		return &errs.Error{Code: errs.Unavailable, Message: "the newsletter is not configured"}
+	}
+	return app.SubscribeNewsletter(ctx, siteOf(ctx), &app.SubscribeNewsletterParams{Email: email})
+}
+
+// confirm confirms the subscription that token was issued for. It fails for
+// tokens that expired or were superseded by a later subscription request.
+func confirm(ctx context.Context, token string, now time.Time) error {
+	invalid := invalidArgument("token", "the confirmation link is invalid or has expired")
+	fields, ok := verifyNewsletterToken("confirm", token)
+	if !ok || len(fields) != 2 {
+		return invalid
+	}
+	id, err1 := strconv.ParseUint(fields[0], 10, 64)
+	issued, err2 := strconv.ParseInt(fields[1], 10, 64)
+	if err1 != nil || err2 != nil {
+		return invalid
+	}
+	ttl := time.Duration(cfg.Newsletter.ConfirmHours()) * time.Hour
+	if now.Sub(time.Unix(issued, 0)) > ttl {
+		return invalid
+	}
+	err := app.ConfirmNewsletterSubscriber(ctx, siteOf(ctx), uint(id), &app.ConfirmNewsletterSubscriberParams{SubscribedAt: issued})
+	if isNotFound(err) {
+		return invalid
+	}
+	return err
+}
+
+// Unsubscribe is the target of the unsubscribe links in newsletter emails.
+// GET asks to confirm, so that link scanners do not unsubscribe anyone, and
+// POST unsubscribes, which also serves RFC 8058 one-click unsubscribe.
+//
+//encore:api public raw method=GET,POST path=/newsletter/unsubscribe
+func (s *Service) Unsubscribe(w http.ResponseWriter, req *http.Request) {
+	token := req.URL.Query().Get("token")
+	id, ok := parseUnsubscribeToken(token)
+	if !ok {
+		http.Error(w, "This unsubscribe link is invalid.", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if req.Method == http.MethodGet {
+		fmt.Fprintf(w, `<!doctype html><title>Unsubscribe</title><form method="post" action="?token=%s"><p>Stop receiving the newsletter?</p><button>Unsubscribe</button></form>`,
+			html.EscapeString(url.QueryEscape(token)))
+		return
+	}
+	if err := app.UnsubscribeNewsletter(req.Context(), uint(id)); err != nil {
+		rlog.Error("graphql: unsubscribe", "id", id, "err", err)
+		http.Error(w, "Something went wrong, please try again later.", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprint(w, `<!doctype html><title>Unsubscribed</title><p>You have been unsubscribed.</p>`)
+}
+
+func confirmToken(id uint, subscribedAt time.Time) string {
+	return signNewsletterToken("confirm", strconv.FormatUint(uint64(id), 10), strconv.FormatInt(subscribedAt.Unix(), 10))
+}
+
+func unsubscribeToken(id uint) string {
+	return signNewsletterToken("unsubscribe", strconv.FormatUint(uint64(id), 10))
+}
+
+func parseUnsubscribeToken(token string) (uint64, bool) {
+	fields, ok := verifyNewsletterToken("unsubscribe", token)
+	if !ok || len(fields) != 1 {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(fields[0], 10, 64)
+	return id, err == nil
+}
+
+// signNewsletterToken joins fields with dots and appends their HMAC, keyed
+// with NewsletterSecret. The purpose is signed too, so that a token for one
+// purpose is useless for another.
+func signNewsletterToken(purpose string, fields ...string) string {
+	payload := strings.Join(fields, ".")
+	return payload + "." + newsletterMAC(purpose, payload)
+}
+
+// verifyNewsletterToken returns the fields of a token signed for purpose.
+func verifyNewsletterToken(purpose, token string) ([]string, bool) {
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 || secrets.NewsletterSecret == "" || !hmac.Equal([]byte(token[i+1:]), []byte(newsletterMAC(purpose, token[:i]))) {
+		return nil, false
+	}
+	return strings.Split(token[:i], "."), true
+}
+
+func newsletterMAC(purpose, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secrets.NewsletterSecret))
+	mac.Write([]byte(purpose))
+	mac.Write([]byte{0})
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
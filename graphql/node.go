@@ -0,0 +1,95 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+
+	"encore.app/app"
+	"encore.app/graphql/model"
+)
+
+type nodeKey struct {
+	typ string
+	pk  uint
+}
+
+// loadNodes fetches the records behind global IDs with one call of the app
+// service per type.
+// The result follows the order of ids and holds nil for records that do not
+// exist. field names the argument the IDs came from.
+func (r *Resolver) loadNodes(ctx context.Context, field string, ids []string) ([]model.Node, error) {
+	pks := make(map[string][]uint)
+	keys := make([]nodeKey, len(ids))
+	for i, id := range ids {
+		typ, pk, ok := decodeGlobalID(id)
+		if !ok {
+			return nil, invalidArgument(field, fmt.Sprintf("%q is not a valid ID", id))
+		}
+		pks[typ] = append(pks[typ], pk)
+		keys[i] = nodeKey{typ, pk}
+	}
+
+	site := siteOf(ctx)
+	found := make(map[nodeKey]model.Node)
+	for typ, group := range pks {
+		switch typ {
+		case typeUser:
+			resp, err := app.ListUsers(ctx, site, &app.ListUsersParams{IDs: group})
+			if err != nil {
+				return nil, err
+			}
+			addNodes(found, typ, resp.Users, func(u *app.User) uint { return u.ID })
+		case typeProject:
+			resp, err := app.ListProjects(ctx, site, &app.ListProjectsParams{IDs: group, IncludeHidden: seesHidden(ctx)})
+			if err != nil {
+				return nil, err
+			}
+			addNodes(found, typ, resp.Projects, func(p *app.Project) uint { return p.ID })
+		case typeBlog:
+			resp, err := app.ListBlogs(ctx, site, &app.ListBlogsParams{IDs: group, IncludeDrafts: seesDrafts(ctx)})
+			if err != nil {
+				return nil, err
+			}
+			addNodes(found, typ, resp.Blogs, func(b *app.Blog) uint { return b.ID })
+		case typeResume:
+			resp, err := app.ListResumes(ctx, site, &app.ListResumesParams{IDs: group})
+			if err != nil {
+				return nil, err
+			}
+			addNodes(found, typ, resp.Resumes, func(rs *app.Resume) uint { return rs.ID })
+		default:
+			if err := loadCRUDNodes(ctx, found, typ, group); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	nodes := make([]model.Node, len(keys))
+	for i, key := range keys {
+		if n, ok := found[key]; ok {
+			nodes[i] = n
+		}
+	}
+	return nodes, nil
+}
+
+// addNodes adds the records of one type to found.
+func addNodes[T any](found map[nodeKey]model.Node, typ string, records []*T, pkOf func(*T) uint) {
+	for _, rec := range records {
+		found[nodeKey{typ, pkOf(rec)}] = rec
+	}
+}
+
+// loadEntities resolves federation entity representations by their id keys,
+// like loadNodes. IDs of records of another type than T resolve to nil.
+func loadEntities[T any](ctx context.Context, r *Resolver, ids []string) ([]*T, error) {
+	nodes, err := r.loadNodes(ctx, "representations", ids)
+	if err != nil {
+		return nil, err
+	}
+	entities := make([]*T, len(nodes))
+	for i, n := range nodes {
+		entities[i], _ = n.(*T)
+	}
+	return entities, nil
+}
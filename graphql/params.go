@@ -0,0 +1,98 @@
+package graphql
+
+import (
+	"encore.app/app"
+	"encore.app/graphql/model"
+)
+
+// The functions below translate mutation inputs into the requests of the app
+// service, resolving the global IDs they contain.
+
+func createUserParams(input *model.CreateUserInput) *app.CreateUserParams {
+	return &app.CreateUserParams{Name: input.Name, Email: input.Email}
+}
+
+func updateUserParams(input *model.UpdateUserInput) *app.UpdateUserParams {
+	return &app.UpdateUserParams{Name: input.Name, Email: input.Email, ExpectedVersion: input.ExpectedVersion}
+}
+
+func deleteUserParams(strategy model.DeleteUserStrategy, toUserID *string) (*app.DeleteUserParams, error) {
+	p := &app.DeleteUserParams{Strategy: app.DeleteStrategy(strategy)}
+	if strategy == model.DeleteUserStrategyReassign && toUserID != nil {
+		pk, err := parseID("toUserId", typeUser, *toUserID)
+		if err != nil {
+			return nil, err
+		}
+		p.ToUserID = pk
+	}
+	return p, nil
+}
+
+func createProjectParams(input *model.CreateProjectInput) (*app.CreateProjectParams, error) {
+	userID, err := parseID("userID", typeUser, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &app.CreateProjectParams{
+		Title:       input.Title,
+		Description: input.Description,
+		UserID:      userID,
+		Hidden:      input.Hidden != nil && *input.Hidden,
+	}, nil
+}
+
+func updateProjectParams(input *model.UpdateProjectInput) (*app.UpdateProjectParams, error) {
+	p := &app.UpdateProjectParams{
+		Title:           input.Title,
+		Description:     input.Description,
+		Hidden:          input.Hidden,
+		ExpectedVersion: input.ExpectedVersion,
+	}
+	if input.UserID != nil {
+		userID, err := parseID("userID", typeUser, *input.UserID)
+		if err != nil {
+			return nil, err
+		}
+		p.UserID = &userID
+	}
+	return p, nil
+}
+
+func createBlogParams(input *model.CreateBlogInput) *app.CreateBlogParams {
+	return &app.CreateBlogParams{
+		Title:     input.Title,
+		Content:   input.Content,
+		CreatedAt: input.CreatedAt,
+		Draft:     input.Draft != nil && *input.Draft,
+	}
+}
+
+func updateBlogParams(input *model.UpdateBlogInput) *app.UpdateBlogParams {
+	return &app.UpdateBlogParams{
+		Title:           input.Title,
+		Content:         input.Content,
+		Draft:           input.Draft,
+		ExpectedVersion: input.ExpectedVersion,
+	}
+}
+
+func createResumeParams(input *model.CreateResumeInput) *app.CreateResumeParams {
+	return &app.CreateResumeParams{
+		Title:       input.Title,
+		Description: input.Description,
+		Category:    input.Category,
+		StartDate:   input.StartDate,
+		EndDate:     input.EndDate,
+	}
+}
+
+func updateResumeParams(input *model.UpdateResumeInput) *app.UpdateResumeParams {
+	return &app.UpdateResumeParams{
+		Title:           input.Title,
+		Description:     input.Description,
+		Category:        input.Category,
+		StartDate:       input.StartDate,
+		EndDate:         input.EndDate,
+		ExpectedVersion: input.ExpectedVersion,
+	}
+}
@@ -0,0 +1,91 @@
+package graphql
+
+import (
+	"context"
+	"strconv"
+
+	"encore.app/graphql/model"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+type violationsKey struct{}
+
+// returnsPayload reports whether the field returns a mutation payload, i.e. a
+// type that can carry userErrors.
+func returnsPayload(schema *ast.Schema, def *ast.FieldDefinition) bool {
+	typ := schema.Types[def.Type.Name()]
+	return typ != nil && typ.Fields.ForName("userErrors") != nil
+}
+
+// mutate runs fn as the body of a payload mutation. Arguments that failed
+// validation and expected failures of fn are returned as userErrors, so that
+// clients can handle them without parsing top-level errors. Anything else is
+// returned as err.
+func mutate(ctx context.Context, fn func() error) ([]*model.UserError, error) {
+	if vs := violationsFrom(ctx); len(vs) > 0 {
+		userErrors := make([]*model.UserError, len(vs))
+		for i, v := range vs {
+			userErrors[i] = v.userError()
+		}
+		return userErrors, nil
+	}
+	if err := fn(); err != nil {
+		ce, ok := classify(err)
+		if !ok {
+			return nil, err
+		}
+		return []*model.UserError{ce.userError(fieldPath(ctx, ce.field))}, nil
+	}
+	return []*model.UserError{}, nil
+}
+
+// violationsFrom returns the argument violations that validateArguments left
+// for a payload mutation.
+func violationsFrom(ctx context.Context) []violation {
+	vs, _ := ctx.Value(violationsKey{}).([]violation)
+	return vs
+}
+
+func (v violation) userError() *model.UserError {
+	return &model.UserError{
+		Field:   pathStrings(v.path),
+		Code:    model.ErrorCode(CodeValidationFailed),
+		Message: v.message,
+	}
+}
+
+func (ce clientError) userError(field []string) *model.UserError {
+	return &model.UserError{
+		Field:   field,
+		Code:    model.ErrorCode(ce.code),
+		Message: ce.message,
+		Current: ce.current,
+	}
+}
+
+// fieldPath locates a named argument or input field of the current mutation.
+// Names that are not arguments are taken to belong to its input object.
+func fieldPath(ctx context.Context, field string) []string {
+	if field == "" {
+		return nil
+	}
+	args := graphql.GetFieldContext(ctx).Field.Definition.Arguments
+	if args.ForName(field) == nil && args.ForName("input") != nil {
+		return []string{"input", field}
+	}
+	return []string{field}
+}
+
+func pathStrings(path ast.Path) []string {
+	out := make([]string, len(path))
+	for i, el := range path {
+		switch el := el.(type) {
+		case ast.PathName:
+			out[i] = string(el)
+		case ast.PathIndex:
+			out[i] = strconv.Itoa(int(el))
+		}
+	}
+	return out
+}
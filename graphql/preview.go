@@ -0,0 +1,87 @@
+package graphql
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"encore.app/app"
+	"encore.dev/beta/errs"
+)
+
+// seesDrafts reports whether the caller may see drafts, which it may if it
+// may edit them.
+func seesDrafts(ctx context.Context) bool {
+	return principalFrom(ctx).has("write:blogs")
+}
+
+// seesHidden reports whether the caller may see hidden projects, which it may
+// if it may edit them.
+func seesHidden(ctx context.Context) bool {
+	return principalFrom(ctx).has("write:projects")
+}
+
+// requireVisible returns NotFound unless the blog post or project typ pk
+// exists and the caller may see it.
+func requireVisible(ctx context.Context, typ string, pk uint) error {
+	var err error
+	switch typ {
+	case typeBlog:
+		_, err = app.GetBlog(ctx, siteOf(ctx), pk, &app.GetBlogParams{IncludeDrafts: seesDrafts(ctx)})
+	case typeProject:
+		_, err = app.GetProject(ctx, siteOf(ctx), pk, &app.GetProjectParams{IncludeHidden: seesHidden(ctx)})
+	}
+	return err
+}
+
+// previewToken returns a token that shows the record typ pk of site until
+// expires. It holds the expiry and its HMAC, keyed with PreviewSecret.
+func previewToken(site uint, typ string, pk uint, expires time.Time) string {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	return exp + "." + previewMAC(site, typ, pk, exp)
+}
+
+// validPreviewToken reports whether token shows the record typ pk of site at
+// now.
+func validPreviewToken(token string, site uint, typ string, pk uint, now time.Time) bool {
+	exp, mac, ok := strings.Cut(token, ".")
+	if !ok || secrets.PreviewSecret == "" || !hmac.Equal([]byte(mac), []byte(previewMAC(site, typ, pk, exp))) {
+		return false
+	}
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	return err == nil && now.Before(time.Unix(unix, 0))
+}
+
+func previewMAC(site uint, typ string, pk uint, exp string) string {
+	mac := hmac.New(sha256.New, []byte(secrets.PreviewSecret))
+	fmt.Fprintf(mac, "%d\x00%s\x00%d\x00%s", site, typ, pk, exp)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// previewURL fills the {id} and {token} placeholders of Previews.URL.
+func previewURL(id, token string) string {
+	return strings.NewReplacer("{id}", url.QueryEscape(id), "{token}", url.QueryEscape(token)).Replace(cfg.Previews.URL())
+}
+
+// previewing checks the previewToken argument of a query for the record typ
+// pk. It reports whether the token shows the record, which then does not
+// need to be visible, and keeps the response out of shared caches.
+func previewing(ctx context.Context, token *string, typ string, pk uint) (bool, error) {
+	if token == nil {
+		return false, nil
+	}
+	site, _ := app.SiteFrom(ctx)
+	if !validPreviewToken(*token, site, typ, pk, time.Now()) {
+		return false, &errs.Error{Code: errs.PermissionDenied, Message: "the preview link is invalid or has expired"}
+	}
+	if policy, ok := ctx.Value(cachePolicyKey{}).(*cachePolicy); ok {
+		policy.markPrivate()
+	}
+	return true, nil
+}
@@ -0,0 +1,63 @@
+//go:build encore_app
+
+package graphql
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func setPreviewSecret(t *testing.T, secret string) {
+	t.Helper()
+	old := secrets.PreviewSecret
+	secrets.PreviewSecret = secret
+	t.Cleanup(func() { secrets.PreviewSecret = old })
+}
+
+func TestValidPreviewToken(t *testing.T) {
+	setPreviewSecret(t, "preview-secret")
+	now := time.Now()
+	token := previewToken(1, typeBlog, 7, now.Add(time.Hour))
+
+	exp, mac, _ := strings.Cut(token, ".")
+	tampered := []byte(mac)
+	if tampered[0] == 'A' {
+		tampered[0] = 'B'
+	} else {
+		tampered[0] = 'A'
+	}
+	for _, tt := range []struct {
+		name  string
+		token string
+		site  uint
+		typ   string
+		pk    uint
+		at    time.Time
+		want  bool
+	}{
+		{"valid", token, 1, typeBlog, 7, now, true},
+		{"just before expiry", token, 1, typeBlog, 7, now.Add(time.Hour - time.Second), true},
+		{"expired", token, 1, typeBlog, 7, now.Add(time.Hour), false},
+		{"another site", token, 2, typeBlog, 7, now, false},
+		{"another type", token, 1, typeProject, 7, now, false},
+		{"another record", token, 1, typeBlog, 8, now, false},
+		{"tampered MAC", exp + "." + string(tampered), 1, typeBlog, 7, now, false},
+		{"extended expiry", "9999999999." + mac, 1, typeBlog, 7, now, false},
+		{"no MAC", exp, 1, typeBlog, 7, now, false},
+		{"empty", "", 1, typeBlog, 7, now, false},
+	} {
+		if got := validPreviewToken(tt.token, tt.site, tt.typ, tt.pk, tt.at); got != tt.want {
+			t.Errorf("%s: validPreviewToken = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidPreviewTokenWithoutSecret(t *testing.T) {
+	setPreviewSecret(t, "")
+	// A token signed with the empty key must not show anything either.
+	token := previewToken(1, typeBlog, 7, time.Now().Add(time.Hour))
+	if validPreviewToken(token, 1, typeBlog, 7, time.Now()) {
+		t.Error("a token was valid without a PreviewSecret")
+	}
+}
@@ -0,0 +1,213 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"encore.app/app"
+	"encore.dev/beta/auth"
+	"encore.dev/rlog"
+	"github.com/99designs/gqlgen/complexity"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// limitStore keeps the token buckets of the rate limiter.
+type limitStore interface {
+	// take removes cost tokens from the bucket of key. If there are not
+	// enough, it takes nothing and returns how long until there will be.
+	take(ctx context.Context, key string, cost float64, now time.Time) (wait time.Duration, err error)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// memoryStore keeps the buckets in memory, so each instance of the service
+// enforces its own limit.
+type memoryStore struct {
+	app.BucketRate
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+func newMemoryStore(r app.BucketRate) *memoryStore {
+	return &memoryStore{BucketRate: r, buckets: make(map[string]*bucket)}
+}
+
+func (s *memoryStore) take(_ context.Context, key string, cost float64, now time.Time) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: s.Burst, last: now}
+		s.buckets[key] = b
+	}
+	var wait time.Duration
+	b.tokens, wait = s.Spend(b.tokens, b.last, now, cost)
+	b.last = now
+	return wait, nil
+}
+
+// sweep forgets buckets that have refilled completely, as they are no
+// different from new ones. It runs at most once a minute.
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.swept) < time.Minute {
+		return
+	}
+	s.swept = now
+	for key, b := range s.buckets {
+		if tokens, _ := s.Spend(b.tokens, b.last, now, 0); tokens >= s.Burst {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// postgresStore keeps the buckets in the rate_limit_buckets table of the app
+// service, so that all instances of the service share one limit per client.
+type postgresStore struct {
+	app.BucketRate
+}
+
+func (s *postgresStore) take(ctx context.Context, key string, cost float64, _ time.Time) (time.Duration, error) {
+	resp, err := app.TakeTokens(ctx, &app.TakeTokensParams{Key: key, Cost: cost, Bucket: s.BucketRate})
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(resp.WaitMs) * time.Millisecond, nil
+}
+
+// newLimitStore returns the store configured by RateLimit.Store for buckets
+// of rate r.
+func newLimitStore(r app.BucketRate) limitStore {
+	if cfg.RateLimit.Store() == "postgres" {
+		return &postgresStore{BucketRate: r}
+	}
+	return newMemoryStore(r)
+}
+
+// client is the rate limit state of the request being served.
+type client struct {
+	key        string
+	ip         string
+	userAgent  string
+	retryAfter time.Duration
+}
+
+type clientKey struct{}
+
+// clientFor identifies who a request is counted against: the authenticated
+// user, which includes API keys, or else the client's IP address.
+func clientFor(req *http.Request, trustForwardedFor bool) *client {
+	ip, ua := clientIP(req, trustForwardedFor), req.UserAgent()
+	if uid, ok := auth.UserID(); ok {
+		return &client{key: "user:" + string(uid), ip: ip, userAgent: ua}
+	}
+	return &client{key: "ip:" + ip, ip: ip, userAgent: ua}
+}
+
+// clientIP returns the IP address of the client that sent req.
+func clientIP(req *http.Request, trustForwardedFor bool) string {
+	if xff := req.Header.Get("X-Forwarded-For"); trustForwardedFor && xff != "" {
+		first, _, _ := strings.Cut(xff, ",")
+		return strings.TrimSpace(first)
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// rateLimit is a gqlgen extension that charges every operation its query
+// complexity against the token bucket of the client.
+type rateLimit struct {
+	store limitStore
+	burst int
+	es    graphql.ExecutableSchema
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationContextMutator
+} = &rateLimit{}
+
+func (l *rateLimit) ExtensionName() string { return "RateLimit" }
+
+func (l *rateLimit) Validate(es graphql.ExecutableSchema) error {
+	l.es = es
+	return nil
+}
+
+func (l *rateLimit) MutateOperationContext(ctx context.Context, opCtx *graphql.OperationContext) *gqlerror.Error {
+	c, ok := ctx.Value(clientKey{}).(*client)
+	if !ok {
+		return nil
+	}
+	cost := max(complexity.Calculate(ctx, l.es, opCtx.Operation, opCtx.Variables), 1)
+	if cost > l.burst {
+		return &gqlerror.Error{
+			Message:    fmt.Sprintf("query complexity %d exceeds the limit of %d", cost, l.burst),
+			Extensions: map[string]any{"code": CodeValidationFailed, "cost": cost},
+		}
+	}
+	wait, err := l.store.take(ctx, c.key, float64(cost), time.Now())
+	if err != nil {
+		// An unavailable store must not take the API down with it.
+		rlog.Error("graphql: rate limit store", "err", err)
+		return nil
+	}
+	if wait > 0 {
+		c.retryAfter = wait
+		return &gqlerror.Error{
+			Message: "rate limit exceeded",
+			Extensions: map[string]any{
+				"code":       CodeRateLimited,
+				"cost":       cost,
+				"retryAfter": retryAfterSeconds(wait),
+			},
+		}
+	}
+	return nil
+}
+
+func retryAfterSeconds(wait time.Duration) int {
+	return int(math.Ceil(wait.Seconds()))
+}
+
+// limitedWriter turns the response to a rate limited request into a 429 with
+// a Retry-After header.
+type limitedWriter struct {
+	http.ResponseWriter
+	client      *client
+	wroteHeader bool
+}
+
+func (w *limitedWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if w.client.retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(w.client.retryAfter)))
+		status = http.StatusTooManyRequests
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *limitedWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
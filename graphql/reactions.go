@@ -0,0 +1,183 @@
+package graphql
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"sync"
+	"time"
+
+	"encore.app/app"
+	"encore.app/graphql/model"
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+)
+
+// reactorFor identifies the caller for reactions. Callers with credentials
+// are told apart by their auth UID. Anonymous visitors are told apart by a
+// fingerprint of their IP address and user agent, keyed with the
+// FingerprintSecret secret so that it cannot be traced back to either. It
+// returns "" for anonymous visitors without that secret, who can neither be
+// told apart nor react.
+func reactorFor(ctx context.Context) string {
+	if p := principalFrom(ctx); p.authenticated {
+		return "user:" + string(p.uid)
+	}
+	c, ok := ctx.Value(clientKey{}).(*client)
+	if !ok || secrets.FingerprintSecret == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(secrets.FingerprintSecret))
+	mac.Write([]byte(c.ip))
+	mac.Write([]byte{0})
+	mac.Write([]byte(c.userAgent))
+	return "anon:" + hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// reactions returns the count of every kind of reaction to the post blogID,
+// and whether reactor used it. Within a request, the reactions of the posts
+// are loaded in batches.
+func reactions(ctx context.Context, blogID uint, reactor string) ([]*model.Reaction, error) {
+	resp, err := loadersOf(ctx).reactions.load(ctx, reactor, blogID)
+	if err != nil {
+		return nil, err
+	}
+	return reactionsFrom(resp), nil
+}
+
+func fetchReactions(ctx context.Context, site uint, reactor string, ids []uint) (map[uint]*app.ReactionsResponse, error) {
+	resp, err := app.ListBlogReactions(ctx, site, &app.ListBlogReactionsParams{BlogIDs: ids, Reactor: reactor})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*app.ReactionsResponse, len(ids))
+	for i, id := range ids {
+		byID[id] = resp.Reactions[i]
+	}
+	return byID, nil
+}
+
+// reactionsFrom lists every kind of reaction with the counts of resp.
+func reactionsFrom(resp *app.ReactionsResponse) []*model.Reaction {
+	reactions := make([]*model.Reaction, len(model.AllReactionKind))
+	for i, kind := range model.AllReactionKind {
+		reactions[i] = &model.Reaction{Kind: kind, ViewerHasReacted: slices.Contains(resp.Mine, string(kind))}
+		for _, c := range resp.Counts {
+			if c.Kind == string(kind) {
+				reactions[i].Count = int(c.Count)
+			}
+		}
+	}
+	return reactions
+}
+
+// reactionHub wakes the reactionsChanged subscriptions of a post when its
+// reactions change. It only reaches the subscriptions of its own instance;
+// the others notice on their next poll.
+type reactionHub struct {
+	mu   sync.Mutex
+	subs map[uint]map[chan struct{}]struct{}
+}
+
+func newReactionHub() *reactionHub {
+	return &reactionHub{subs: make(map[uint]map[chan struct{}]struct{})}
+}
+
+// subscribe returns a channel that receives when the reactions to the post
+// blogID change, and the function that stops it.
+func (h *reactionHub) subscribe(blogID uint) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs[blogID] == nil {
+		h.subs[blogID] = make(map[chan struct{}]struct{})
+	}
+	h.subs[blogID][ch] = struct{}{}
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subs[blogID], ch)
+		if len(h.subs[blogID]) == 0 {
+			delete(h.subs, blogID)
+		}
+	}
+}
+
+// notify wakes the subscriptions of the post blogID. Subscriptions that are
+// still busy with an earlier change pick this one up with it.
+func (h *reactionHub) notify(blogID uint) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[blogID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// watchReactions sends the reactions to the post blogID as seen by reactor to
+// out, first right away and then whenever they change, until ctx is done.
+// Changes are picked up from the hub, and every poll for those made on other
+// instances. A poll of 0 only relies on the hub.
+func (r *Resolver) watchReactions(ctx context.Context, blogID uint, reactor string, out chan<- []*model.Reaction, poll time.Duration) {
+	defer close(out)
+	wake, stop := r.reactionHub.subscribe(blogID)
+	defer stop()
+	var tick <-chan time.Time
+	if poll > 0 {
+		ticker := time.NewTicker(poll)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	var last []*model.Reaction
+	for {
+		reactions, err := reactions(ctx, blogID, reactor)
+		if err != nil && ctx.Err() == nil {
+			rlog.Error("graphql: load reactions", "blog_id", blogID, "err", err)
+		} else if err == nil && !slices.EqualFunc(reactions, last, func(a, b *model.Reaction) bool { return *a == *b }) {
+			select {
+			case out <- reactions:
+				last = reactions
+			case <-ctx.Done():
+				return
+			}
+		}
+		select {
+		case <-wake:
+		case <-tick:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// changeReaction runs change for the post blogID and the caller, which
+// returns the reactions to the post afterwards.
+func (r *Resolver) changeReaction(ctx context.Context, blogID string, clientMutationID *string, change func(pk uint, reactor string) (*app.ReactionsResponse, error)) (*model.ReactionPayload, error) {
+	payload := &model.ReactionPayload{ClientMutationID: clientMutationID}
+	userErrors, err := mutate(ctx, func() error {
+		pk, err := parseID("blogId", typeBlog, blogID)
+		if err != nil {
+			return err
+		}
+		reactor := reactorFor(ctx)
+		if reactor == "" {
+			return &errs.Error{Code: errs.FailedPrecondition, Message: "anonymous reactions are not configured"}
+		}
+		resp, err := change(pk, reactor)
+		if err != nil {
+			return err
+		}
+		r.reactionHub.notify(pk)
+		payload.Reactions = reactionsFrom(resp)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload.UserErrors = userErrors
+	return payload, nil
+}
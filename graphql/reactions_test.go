@@ -0,0 +1,132 @@
+//go:build encore_app
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"encore.app/app"
+)
+
+// withFingerprintSecret sets FingerprintSecret to secret for the test.
+func withFingerprintSecret(t *testing.T, secret string) {
+	t.Helper()
+	old := secrets.FingerprintSecret
+	secrets.FingerprintSecret = secret
+	t.Cleanup(func() { secrets.FingerprintSecret = old })
+}
+
+func TestReactorFor(t *testing.T) {
+	withFingerprintSecret(t, "fingerprint-secret")
+	visitor := func(ip, userAgent string) context.Context {
+		return context.WithValue(context.Background(), clientKey{}, &client{ip: ip, userAgent: userAgent})
+	}
+	user := context.WithValue(visitor("203.0.113.7", "curl"), principalKey{}, &principal{authenticated: true, uid: "key:1"})
+	if got := reactorFor(user); got != "user:key:1" {
+		t.Errorf("reactor of a key = %q, want user:key:1", got)
+	}
+
+	anon := reactorFor(visitor("203.0.113.7", "curl"))
+	if !strings.HasPrefix(anon, "anon:") || strings.Contains(anon, "203.0.113.7") {
+		t.Errorf("reactor of a visitor = %q, want an anonymous fingerprint", anon)
+	}
+	if again := reactorFor(visitor("203.0.113.7", "curl")); again != anon {
+		t.Errorf("same visitor: %q, then %q", anon, again)
+	}
+	for _, other := range []context.Context{visitor("203.0.113.8", "curl"), visitor("203.0.113.7", "firefox")} {
+		if got := reactorFor(other); got == anon {
+			t.Errorf("another visitor got the same reactor %q", got)
+		}
+	}
+
+	if got := reactorFor(context.Background()); got != "" {
+		t.Errorf("reactor without a client = %q, want none", got)
+	}
+	withFingerprintSecret(t, "")
+	if got := reactorFor(visitor("203.0.113.7", "curl")); got != "" {
+		t.Errorf("reactor without FingerprintSecret = %q, want none", got)
+	}
+	if got := reactorFor(user); got != "user:key:1" {
+		t.Errorf("reactor of a key without FingerprintSecret = %q, want user:key:1", got)
+	}
+}
+
+func TestReactWithoutFingerprintSecret(t *testing.T) {
+	withFingerprintSecret(t, "")
+	r := createEntityRecords(t)
+	resp := execute(t, r.site, &principal{}, `mutation($id: ID!) {
+		react(blogId: $id, kind: LIKE) { reactions { kind count } userErrors { code message } }
+	}`, map[string]any{"id": r.blog})
+	var data struct {
+		React struct {
+			UserErrors []struct{ Code, Message string }
+		}
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if errs := data.React.UserErrors; len(errs) != 1 || errs[0].Code != CodeValidationFailed {
+		t.Errorf("userErrors = %+v, want anonymous reactions refused", errs)
+	}
+}
+
+// TestReactionsOfList checks that the reactions loaded in one batch end up on
+// their own posts.
+func TestReactionsOfList(t *testing.T) {
+	ctx := context.Background()
+	r := createEntityRecords(t)
+	var ids []uint
+	for _, title := range []string{"One", "Two", "Three"} {
+		blog, err := app.CreateBlog(ctx, r.site, &app.CreateBlogParams{Title: title, Content: "..."})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, blog.ID)
+	}
+	for i, reactor := range []string{"user:me", "user:a", "user:b"} {
+		for _, id := range ids[:i+1] {
+			if _, err := app.React(ctx, r.site, id, &app.ReactParams{Kind: "CLAP", Reactor: reactor}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	me := &principal{authenticated: true, uid: "me", scopes: reader.scopes}
+	var data struct {
+		Nodes []struct {
+			Reactions []struct {
+				Kind             string
+				Count            int
+				ViewerHasReacted bool
+			}
+		}
+	}
+	gids := make([]string, len(ids))
+	for i, id := range ids {
+		gids[i] = globalID(typeBlog, id)
+	}
+	resp := execute(t, r.site, me, `query($ids: [ID!]!) {
+		nodes(ids: $ids) { ... on Blog { reactions { kind count viewerHasReacted } } }
+	}`, map[string]any{"ids": gids})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors = %+v", resp.Errors)
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	for i, n := range data.Nodes {
+		for _, reaction := range n.Reactions {
+			if reaction.Kind != "CLAP" {
+				if reaction.Count != 0 || reaction.ViewerHasReacted {
+					t.Errorf("post %d: %+v, want none", i, reaction)
+				}
+				continue
+			}
+			if want := 3 - i; reaction.Count != want || reaction.ViewerHasReacted != (i == 0) {
+				t.Errorf("post %d: %+v, want %d claps, mine only on the first", i, reaction, want)
+			}
+		}
+	}
+}
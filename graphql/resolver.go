@@ -1,11 +1,56 @@
 package graphql
 
-import "gorm.io/gorm"
+import (
+	"context"
+	"fmt"
+
+	"encore.app/app"
+	"encore.dev/beta/errs"
+)
 
 // This file will not be regenerated automatically.
 //
 // It serves as dependency injection for your app, add any dependencies you require here.
 
 type Resolver struct {
-	db *gorm.DB
+	// scopes lists the scopes that can be granted to API keys.
+	scopes []string
+	// contact guards submitContactMessage.
+	contact *contactGuard
+	// views keeps recordView from counting reloads.
+	views *viewDedupe
+	// reactionHub pushes reaction changes to reactionsChanged.
+	reactionHub *reactionHub
+}
+
+// isNotFound reports whether err is the NotFound error of an app endpoint.
+func isNotFound(err error) bool {
+	return errs.Code(err) == errs.NotFound
+}
+
+// invalidateDeletion drops the cached responses that contain the deleted user
+// userID or the projects the deletion removed or moved.
+func invalidateDeletion(ctx context.Context, userID uint, deletion *app.UserDeletion) {
+	invalidate(ctx, typeUser, userID)
+	var projects []uint
+	for _, id := range deletion.ProjectIDs {
+		if _, pk, ok := decodeGlobalID(id); ok {
+			projects = append(projects, pk)
+		}
+	}
+	invalidate(ctx, typeProject, projects...)
+}
+
+// maxFirst is the most items a list argument named first can ask for.
+const maxFirst = 500
+
+// firstArg checks the first argument of a list field.
+func firstArg(first *int, def int) (int, error) {
+	if first == nil {
+		return def, nil
+	}
+	if *first < 1 || *first > maxFirst {
+		return 0, invalidArgument("first", fmt.Sprintf("first must be between 1 and %d", maxFirst))
+	}
+	return *first, nil
 }
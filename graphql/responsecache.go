@@ -0,0 +1,365 @@
+package graphql
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"encore.app/app"
+	"encore.dev/metrics"
+	"encore.dev/rlog"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+)
+
+// responseStore keeps cached query responses. Entries are tagged with the
+// records they contain so that mutations can drop them.
+type responseStore interface {
+	// get returns the entry stored under key, if it has not expired.
+	get(ctx context.Context, key string) ([]byte, bool, error)
+	// set stores value under key for ttl, tagged with tags.
+	set(ctx context.Context, key string, value []byte, ttl time.Duration, tags []string) error
+	// invalidate drops every entry tagged with any of tags.
+	invalidate(ctx context.Context, tags []string) error
+}
+
+// sharedResponseStore keeps entries in the store of its instance, but drops
+// them on every instance: it records the tags it invalidates with the app
+// service, and follow drops those recorded through other instances.
+type sharedResponseStore struct {
+	responseStore
+	since time.Time
+}
+
+// invalidationOverlap is how far back each poll of follow goes into the
+// previous one, for invalidations whose transaction was still running then.
+const invalidationOverlap = 5 * time.Second
+
+func (s *sharedResponseStore) invalidate(ctx context.Context, tags []string) error {
+	if err := s.responseStore.invalidate(ctx, tags); err != nil {
+		return err
+	}
+	return app.InvalidateCache(ctx, &app.InvalidateCacheParams{Tags: tags})
+}
+
+// follow drops the entries invalidated through any instance every poll, and
+// never returns.
+func (s *sharedResponseStore) follow(poll time.Duration) {
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := s.poll(context.Background()); err != nil {
+			rlog.Error("graphql: follow response cache invalidations", "err", err)
+		}
+	}
+}
+
+// poll drops the entries invalidated since the previous poll. The first one
+// drops those of the retention of the app service, which are not cached yet.
+func (s *sharedResponseStore) poll(ctx context.Context) error {
+	resp, err := app.CacheInvalidations(ctx, &app.CacheInvalidationsParams{Since: s.since})
+	if err != nil {
+		return err
+	}
+	if len(resp.Tags) > 0 {
+		if err := s.responseStore.invalidate(ctx, resp.Tags); err != nil {
+			return err
+		}
+	}
+	s.since = resp.Until.Add(-invalidationOverlap)
+	return nil
+}
+
+type cacheLabels struct {
+	Result string // "hit" or "miss"
+}
+
+var cacheLookups = metrics.NewCounterGroup[cacheLabels, uint64]("graphql_response_cache_lookups", metrics.CounterConfig{})
+
+type cacheEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+	tags    []string
+}
+
+// memoryResponseStore keeps at most size entries in memory and evicts the
+// least recently used. Each instance of the service has its own, which only
+// sees the invalidations of its instance.
+type memoryResponseStore struct {
+	size    int
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	tagged  map[string]map[string]struct{}
+}
+
+func newMemoryResponseStore(size int) *memoryResponseStore {
+	return &memoryResponseStore{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+		tagged:  make(map[string]map[string]struct{}),
+	}
+}
+
+func (s *memoryResponseStore) get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	e := el.Value.(*cacheEntry)
+	if !time.Now().Before(e.expires) {
+		s.remove(el)
+		return nil, false, nil
+	}
+	s.lru.MoveToFront(el)
+	return e.value, true, nil
+}
+
+func (s *memoryResponseStore) set(_ context.Context, key string, value []byte, ttl time.Duration, tags []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.entries[key]; ok {
+		s.remove(el)
+	}
+	e := &cacheEntry{key: key, value: value, expires: time.Now().Add(ttl), tags: tags}
+	s.entries[key] = s.lru.PushFront(e)
+	for _, tag := range tags {
+		if s.tagged[tag] == nil {
+			s.tagged[tag] = make(map[string]struct{})
+		}
+		s.tagged[tag][key] = struct{}{}
+	}
+	for s.lru.Len() > s.size {
+		s.remove(s.lru.Back())
+	}
+	return nil
+}
+
+func (s *memoryResponseStore) invalidate(_ context.Context, tags []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tag := range tags {
+		for key := range s.tagged[tag] {
+			s.remove(s.entries[key])
+		}
+	}
+	return nil
+}
+
+func (s *memoryResponseStore) remove(el *list.Element) {
+	e := s.lru.Remove(el).(*cacheEntry)
+	delete(s.entries, e.key)
+	for _, tag := range e.tags {
+		delete(s.tagged[tag], e.key)
+		if len(s.tagged[tag]) == 0 {
+			delete(s.tagged, tag)
+		}
+	}
+}
+
+// tagSet collects cache tags while an operation runs.
+type tagSet struct {
+	mu   sync.Mutex
+	tags map[string]struct{}
+}
+
+func (t *tagSet) add(tags ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.tags == nil {
+		t.tags = make(map[string]struct{})
+	}
+	for _, tag := range tags {
+		t.tags[tag] = struct{}{}
+	}
+}
+
+func (t *tagSet) list() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tags := make([]string, 0, len(t.tags))
+	for tag := range t.tags {
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+	return tags
+}
+
+type (
+	responseTagsKey  struct{}
+	invalidationsKey struct{}
+)
+
+// recordTag is the cache tag of a single record. The type name alone tags
+// lists of records of that type, whose members change on every write.
+func recordTag(typ string, pk uint) string {
+	return typ + ":" + strconv.FormatUint(uint64(pk), 10)
+}
+
+// invalidate drops the cached responses that contain the records pks of typ,
+// or any list of typ, once the current mutation is done.
+func invalidate(ctx context.Context, typ string, pks ...uint) {
+	t, ok := ctx.Value(invalidationsKey{}).(*tagSet)
+	if !ok {
+		return
+	}
+	t.add(typ)
+	for _, pk := range pks {
+		t.add(recordTag(typ, pk))
+	}
+}
+
+// tagResponses is a field middleware that tags the response of a query with
+// every record it resolves, and with the type of every list or connection of
+// records.
+func tagResponses(ctx context.Context, next graphql.Resolver) (any, error) {
+	res, err := next(ctx)
+	t, ok := ctx.Value(responseTagsKey{}).(*tagSet)
+	fc := graphql.GetFieldContext(ctx)
+	if !ok || err != nil || fc == nil || fc.Field.Definition == nil {
+		return res, err
+	}
+	if typ := fc.Field.Definition.Type; typ.Elem != nil {
+		switch name := typ.Name(); name {
+		case typeUser, typeProject, typeBlog, typeResume:
+			t.add(name)
+		}
+	} else if name, ok := crudConnection(typ.Name()); ok {
+		t.add(name)
+	}
+	v := reflect.ValueOf(res)
+	if v.Kind() != reflect.Slice {
+		t.add(nodeTags(res)...)
+		return res, err
+	}
+	for i := range v.Len() {
+		t.add(nodeTags(v.Index(i).Interface())...)
+	}
+	return res, err
+}
+
+func nodeTags(n any) []string {
+	switch n := n.(type) {
+	case *app.User:
+		return []string{recordTag(typeUser, n.ID)}
+	case *app.Project:
+		return []string{recordTag(typeProject, n.ID)}
+	case *app.Blog:
+		return []string{recordTag(typeBlog, n.ID)}
+	case *app.Resume:
+		return []string{recordTag(typeResume, n.ID)}
+	}
+	if typ, pk, ok := crudNode(n); ok {
+		return []string{recordTag(typ, pk)}
+	}
+	return nil
+}
+
+// cachedResponse is what responseCache stores for a query.
+type cachedResponse struct {
+	Expires  time.Time         `json:"expires"`
+	Response *graphql.Response `json:"response"`
+}
+
+// responseCache returns a response middleware that serves query operations
+// from store and invalidates the stored responses touched by mutations.
+//
+// Only responses without errors whose @cacheControl policy is public are
+// stored, for their maxAge. Entries are keyed by the normalized operation,
+// its variables, the site and the scopes of the caller, so callers only ever
+// share responses they could all have seen.
+func responseCache(store responseStore) graphql.ResponseMiddleware {
+	return func(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+		opCtx := graphql.GetOperationContext(ctx)
+		switch opCtx.Operation.Operation {
+		case ast.Mutation:
+			t := &tagSet{}
+			resp := next(context.WithValue(ctx, invalidationsKey{}, t))
+			if tags := t.list(); len(tags) > 0 {
+				// The context may already be past its deadline, and the
+				// entries must go regardless.
+				if err := store.invalidate(context.WithoutCancel(ctx), tags); err != nil {
+					rlog.Error("graphql: invalidate response cache", "err", err)
+				}
+			}
+			return resp
+		case ast.Query:
+		default:
+			return next(ctx)
+		}
+
+		policy, ok := ctx.Value(cachePolicyKey{}).(*cachePolicy)
+		if !ok {
+			policy = &cachePolicy{}
+			ctx = context.WithValue(ctx, cachePolicyKey{}, policy)
+		}
+		key := responseKey(ctx, opCtx)
+		if value, ok, err := store.get(ctx, key); err != nil {
+			rlog.Error("graphql: read response cache", "err", err)
+		} else if ok {
+			var cached cachedResponse
+			if err := json.Unmarshal(value, &cached); err == nil {
+				cacheLookups.With(cacheLabels{Result: "hit"}).Increment()
+				policy.restrict(int(time.Until(cached.Expires)/time.Second), "")
+				return cached.Response
+			}
+		}
+		cacheLookups.With(cacheLabels{Result: "miss"}).Increment()
+
+		t := &tagSet{}
+		resp := next(context.WithValue(ctx, responseTagsKey{}, t))
+		maxAge, public := policy.shared()
+		if resp == nil || len(resp.Errors) > 0 || !public || maxAge <= 0 {
+			return resp
+		}
+		ttl := time.Duration(maxAge) * time.Second
+		value, err := json.Marshal(cachedResponse{Expires: time.Now().Add(ttl), Response: resp})
+		if err == nil {
+			err = store.set(ctx, key, value, ttl, t.list())
+		}
+		if err != nil {
+			rlog.Error("graphql: write response cache", "err", err)
+		}
+		return resp
+	}
+}
+
+// responseKey identifies the result of an operation for a caller.
+func responseKey(ctx context.Context, opCtx *graphql.OperationContext) string {
+	var doc bytes.Buffer
+	formatter.NewFormatter(&doc).FormatQueryDocument(opCtx.Doc)
+	vars, _ := json.Marshal(opCtx.Variables)
+	site, _ := app.SiteFrom(ctx)
+	scopes := slices.Clone(principalFrom(ctx).scopes)
+	slices.Sort(scopes)
+	// Only the part of Accept-Language that picks translations matters.
+	locales, _ := localeChain(ctx, nil)
+
+	h := sha256.New()
+	for _, part := range [][]byte{
+		doc.Bytes(),
+		[]byte(opCtx.OperationName),
+		vars,
+		[]byte(strconv.FormatUint(uint64(site), 10)),
+		[]byte(strings.Join(scopes, " ")),
+		[]byte(strings.Join(locales, ",")),
+	} {
+		h.Write(part)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,67 @@
+//go:build encore_app
+
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMemoryResponseStore(t *testing.T) {
+	ctx := context.Background()
+	s := newMemoryResponseStore(2)
+	s.set(ctx, "a", []byte("A"), time.Minute, []string{"Blog", "Blog:1"})
+	s.set(ctx, "b", []byte("B"), time.Minute, []string{"Project:1"})
+	s.set(ctx, "gone", []byte("-"), -time.Second, nil)
+	if _, ok, _ := s.get(ctx, "gone"); ok {
+		t.Error("expired entry served")
+	}
+	s.set(ctx, "c", []byte("C"), time.Minute, []string{"Blog:2"})
+	if _, ok, _ := s.get(ctx, "a"); ok {
+		t.Error("least recently used entry kept past the size")
+	}
+	s.invalidate(ctx, []string{"Blog:2"})
+	if _, ok, _ := s.get(ctx, "c"); ok {
+		t.Error("invalidated entry served")
+	}
+	if v, ok, _ := s.get(ctx, "b"); !ok || string(v) != "B" {
+		t.Errorf("untouched entry = %q, %v", v, ok)
+	}
+}
+
+// TestSharedResponseStore checks that a write through one instance drops the
+// entries of another once it polls.
+func TestSharedResponseStore(t *testing.T) {
+	ctx := context.Background()
+	tag := fmt.Sprintf("Blog:%d", time.Now().UnixNano())
+	a := &sharedResponseStore{responseStore: newMemoryResponseStore(10)}
+	b := &sharedResponseStore{responseStore: newMemoryResponseStore(10)}
+	for _, s := range []*sharedResponseStore{a, b} {
+		if err := s.poll(ctx); err != nil {
+			t.Fatal(err)
+		}
+		s.set(ctx, "post", []byte("{}"), time.Minute, []string{tag})
+		s.set(ctx, "other", []byte("{}"), time.Minute, []string{"Project:1"})
+	}
+
+	if err := a.invalidate(ctx, []string{tag}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := a.get(ctx, "post"); ok {
+		t.Error("entry kept by the instance that invalidated it")
+	}
+	if _, ok, _ := b.get(ctx, "post"); !ok {
+		t.Fatal("other instance dropped the entry before polling")
+	}
+	if err := b.poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := b.get(ctx, "post"); ok {
+		t.Error("other instance kept the entry after polling")
+	}
+	if _, ok, _ := b.get(ctx, "other"); !ok {
+		t.Error("other instance dropped an entry that was not invalidated")
+	}
+}
@@ -0,0 +1,436 @@
+package graphql
+
+import (
+	"context"
+
+	"encore.app/app"
+	"encore.dev/rlog"
+)
+
+// The REST API serves the endpoints of the app service to clients outside of
+// Encore, such as static site builders, under /v1/sites/:site, where :site is
+// the slug of a site. Like the GraphQL API, it is authenticated by
+// AuthHandler and checks the read: and write: scopes of each type. API keys
+// only reach their own site, and drafts and hidden projects need the write:
+// scope of their type.
+
+// restSite returns the site with slug for the caller, if it holds all of
+// scopes.
+func restSite(ctx context.Context, slug string, scopes ...string) (uint, error) {
+	site, err := app.ResolveSite(ctx, &app.ResolveSiteParams{Slug: slug})
+	if err != nil {
+		return 0, err
+	}
+	p, err := principalFor(site)
+	if err != nil {
+		return 0, err
+	}
+	for _, scope := range scopes {
+		if err := p.require(scope); err != nil {
+			return 0, err
+		}
+	}
+	return site.ID, nil
+}
+
+// withHidden adds the write: scope of a type to its read: scope when hidden
+// records are asked for.
+func withHidden(read, write string, include bool) []string {
+	if include {
+		return []string{read, write}
+	}
+	return []string{read}
+}
+
+// dropCached drops the cached GraphQL responses that invalidations, called
+// like after a mutation, tags as stale.
+func (s *Service) dropCached(ctx context.Context, invalidations func(ctx context.Context)) {
+	if s.cache == nil {
+		return
+	}
+	t := &tagSet{}
+	invalidations(context.WithValue(ctx, invalidationsKey{}, t))
+	if err := s.cache.invalidate(context.WithoutCancel(ctx), t.list()); err != nil {
+		rlog.Error("graphql: invalidate response cache", "err", err)
+	}
+}
+
+// ListUsers lists the users of a site.
+//
+//encore:api auth method=GET path=/v1/sites/:site/users
+func (s *Service) ListUsers(ctx context.Context, site string, p *app.ListUsersParams) (*app.ListUsersResponse, error) {
+	id, err := restSite(ctx, site, "read:users")
+	if err != nil {
+		return nil, err
+	}
+	return app.ListUsers(ctx, id, p)
+}
+
+// GetUser returns a user of a site.
+//
+//encore:api auth method=GET path=/v1/sites/:site/users/:id
+func (s *Service) GetUser(ctx context.Context, site string, id uint) (*app.User, error) {
+	siteID, err := restSite(ctx, site, "read:users")
+	if err != nil {
+		return nil, err
+	}
+	return app.GetUser(ctx, siteID, id)
+}
+
+// CreateUser creates a user on a site.
+//
+//encore:api auth method=POST path=/v1/sites/:site/users
+func (s *Service) CreateUser(ctx context.Context, site string, p *app.CreateUserParams) (*app.User, error) {
+	id, err := restSite(ctx, site, "write:users")
+	if err != nil {
+		return nil, err
+	}
+	user, err := app.CreateUser(ctx, id, p)
+	if err != nil {
+		return nil, err
+	}
+	s.dropCached(ctx, func(ctx context.Context) { invalidate(ctx, typeUser, user.ID) })
+	return user, nil
+}
+
+// UpdateUser changes the fields of a user that are set.
+//
+//encore:api auth method=PATCH path=/v1/sites/:site/users/:id
+func (s *Service) UpdateUser(ctx context.Context, site string, id uint, p *app.UpdateUserParams) (*app.User, error) {
+	siteID, err := restSite(ctx, site, "write:users")
+	if err != nil {
+		return nil, err
+	}
+	user, err := app.UpdateUser(ctx, siteID, id, p)
+	if err != nil {
+		return nil, err
+	}
+	s.dropCached(ctx, func(ctx context.Context) { invalidate(ctx, typeUser, user.ID) })
+	return user, nil
+}
+
+// DeleteUser deletes a user and handles its projects according to the
+// strategy.
+//
+//encore:api auth method=DELETE path=/v1/sites/:site/users/:id
+func (s *Service) DeleteUser(ctx context.Context, site string, id uint, p *app.DeleteUserParams) (*app.DeleteUserResponse, error) {
+	siteID, err := restSite(ctx, site, "write:users")
+	if err != nil {
+		return nil, err
+	}
+	resp, err := app.DeleteUser(ctx, siteID, id, p)
+	if err != nil {
+		return nil, err
+	}
+	s.dropCached(ctx, func(ctx context.Context) { invalidateDeletion(ctx, id, resp.Deletion) })
+	return resp, nil
+}
+
+// BatchUsers runs create, update and delete operations on the users of a
+// site in one transaction.
+//
+//encore:api auth method=POST path=/v1/sites/:site/batch/users
+func (s *Service) BatchUsers(ctx context.Context, site string, p *app.BatchUsersParams) (*app.BatchUsersResponse, error) {
+	id, err := restSite(ctx, site, "write:users")
+	if err != nil {
+		return nil, err
+	}
+	resp, err := app.BatchUsers(ctx, id, p)
+	if err != nil {
+		return nil, err
+	}
+	s.dropCached(ctx, func(ctx context.Context) {
+		invalidate(ctx, typeUser)
+		for _, res := range resp.Results {
+			switch {
+			case res.User != nil && res.Deletion != nil:
+				invalidateDeletion(ctx, res.User.ID, res.Deletion)
+			case res.User != nil:
+				invalidate(ctx, typeUser, res.User.ID)
+			}
+		}
+	})
+	return resp, nil
+}
+
+// ListProjects lists the projects of a site.
+//
+//encore:api auth method=GET path=/v1/sites/:site/projects
+func (s *Service) ListProjects(ctx context.Context, site string, p *app.ListProjectsParams) (*app.ListProjectsResponse, error) {
+	id, err := restSite(ctx, site, withHidden("read:projects", "write:projects", p.IncludeHidden)...)
+	if err != nil {
+		return nil, err
+	}
+	return app.ListProjects(ctx, id, p)
+}
+
+// GetProject returns a project of a site.
+//
+//encore:api auth method=GET path=/v1/sites/:site/projects/:id
+func (s *Service) GetProject(ctx context.Context, site string, id uint, p *app.GetProjectParams) (*app.Project, error) {
+	siteID, err := restSite(ctx, site, withHidden("read:projects", "write:projects", p.IncludeHidden)...)
+	if err != nil {
+		return nil, err
+	}
+	return app.GetProject(ctx, siteID, id, p)
+}
+
+// CreateProject creates a project on a site.
+//
+//encore:api auth method=POST path=/v1/sites/:site/projects
+func (s *Service) CreateProject(ctx context.Context, site string, p *app.CreateProjectParams) (*app.Project, error) {
+	id, err := restSite(ctx, site, "write:projects")
+	if err != nil {
+		return nil, err
+	}
+	project, err := app.CreateProject(ctx, id, p)
+	if err != nil {
+		return nil, err
+	}
+	s.dropCached(ctx, func(ctx context.Context) { invalidate(ctx, typeProject, project.ID) })
+	return project, nil
+}
+
+// UpdateProject changes the fields of a project that are set.
+//
+//encore:api auth method=PATCH path=/v1/sites/:site/projects/:id
+func (s *Service) UpdateProject(ctx context.Context, site string, id uint, p *app.UpdateProjectParams) (*app.Project, error) {
+	siteID, err := restSite(ctx, site, "write:projects")
+	if err != nil {
+		return nil, err
+	}
+	project, err := app.UpdateProject(ctx, siteID, id, p)
+	if err != nil {
+		return nil, err
+	}
+	s.dropCached(ctx, func(ctx context.Context) { invalidate(ctx, typeProject, project.ID) })
+	return project, nil
+}
+
+// DeleteProject deletes a project of a site.
+//
+//encore:api auth method=DELETE path=/v1/sites/:site/projects/:id
+func (s *Service) DeleteProject(ctx context.Context, site string, id uint) (*app.Project, error) {
+	siteID, err := restSite(ctx, site, "write:projects")
+	if err != nil {
+		return nil, err
+	}
+	project, err := app.DeleteProject(ctx, siteID, id)
+	if err != nil {
+		return nil, err
+	}
+	s.dropCached(ctx, func(ctx context.Context) { invalidate(ctx, typeProject, project.ID) })
+	return project, nil
+}
+
+// BatchProjects runs create, update and delete operations on the projects of
+// a site in one transaction.
+//
+//encore:api auth method=POST path=/v1/sites/:site/batch/projects
+func (s *Service) BatchProjects(ctx context.Context, site string, p *app.BatchProjectsParams) (*app.BatchProjectsResponse, error) {
+	id, err := restSite(ctx, site, "write:projects")
+	if err != nil {
+		return nil, err
+	}
+	resp, err := app.BatchProjects(ctx, id, p)
+	if err != nil {
+		return nil, err
+	}
+	s.dropCached(ctx, func(ctx context.Context) {
+		invalidate(ctx, typeProject)
+		for _, res := range resp.Results {
+			if res.Project != nil {
+				invalidate(ctx, typeProject, res.Project.ID)
+			}
+		}
+	})
+	return resp, nil
+}
+
+// ListBlogs lists the blog posts of a site.
+//
+//encore:api auth method=GET path=/v1/sites/:site/blogs
+func (s *Service) ListBlogs(ctx context.Context, site string, p *app.ListBlogsParams) (*app.ListBlogsResponse, error) {
+	id, err := restSite(ctx, site, withHidden("read:blogs", "write:blogs", p.IncludeDrafts)...)
+	if err != nil {
+		return nil, err
+	}
+	return app.ListBlogs(ctx, id, p)
+}
+
+// GetBlog returns a blog post of a site.
+//
+//encore:api auth method=GET path=/v1/sites/:site/blogs/:id
+func (s *Service) GetBlog(ctx context.Context, site string, id uint, p *app.GetBlogParams) (*app.Blog, error) {
+	siteID, err := restSite(ctx, site, withHidden("read:blogs", "write:blogs", p.IncludeDrafts)...)
+	if err != nil {
+		return nil, err
+	}
+	return app.GetBlog(ctx, siteID, id, p)
+}
+
+// CreateBlog creates a blog post on a site.
+//
+//encore:api auth method=POST path=/v1/sites/:site/blogs
+func (s *Service) CreateBlog(ctx context.Context, site string, p *app.CreateBlogParams) (*app.Blog, error) {
+	id, err := restSite(ctx, site, "write:blogs")
+	if err != nil {
+		return nil, err
+	}
+	blog, err := app.CreateBlog(ctx, id, p)
+	if err != nil {
+		return nil, err
+	}
+	s.dropCached(ctx, func(ctx context.Context) { invalidate(ctx, typeBlog, blog.ID) })
+	return blog, nil
+}
+
+// UpdateBlog changes the fields of a blog post that are set.
+//
+//encore:api auth method=PATCH path=/v1/sites/:site/blogs/:id
+func (s *Service) UpdateBlog(ctx context.Context, site string, id uint, p *app.UpdateBlogParams) (*app.Blog, error) {
+	siteID, err := restSite(ctx, site, "write:blogs")
+	if err != nil {
+		return nil, err
+	}
+	blog, err := app.UpdateBlog(ctx, siteID, id, p)
+	if err != nil {
+		return nil, err
+	}
+	s.dropCached(ctx, func(ctx context.Context) { invalidate(ctx, typeBlog, blog.ID) })
+	return blog, nil
+}
+
+// DeleteBlog deletes a blog post of a site.
+//
+//encore:api auth method=DELETE path=/v1/sites/:site/blogs/:id
+func (s *Service) DeleteBlog(ctx context.Context, site string, id uint) (*app.Blog, error) {
+	siteID, err := restSite(ctx, site, "write:blogs")
+	if err != nil {
+		return nil, err
+	}
+	blog, err := app.DeleteBlog(ctx, siteID, id)
+	if err != nil {
+		return nil, err
+	}
+	s.dropCached(ctx, func(ctx context.Context) { invalidate(ctx, typeBlog, blog.ID) })
+	return blog, nil
+}
+
+// BatchBlogs runs create, update and delete operations on the blog posts of
+// a site in one transaction.
+//
+//encore:api auth method=POST path=/v1/sites/:site/batch/blogs
+func (s *Service) BatchBlogs(ctx context.Context, site string, p *app.BatchBlogsParams) (*app.BatchBlogsResponse, error) {
+	id, err := restSite(ctx, site, "write:blogs")
+	if err != nil {
+		return nil, err
+	}
+	resp, err := app.BatchBlogs(ctx, id, p)
+	if err != nil {
+		return nil, err
+	}
+	s.dropCached(ctx, func(ctx context.Context) {
+		invalidate(ctx, typeBlog)
+		for _, res := range resp.Results {
+			if res.Blog != nil {
+				invalidate(ctx, typeBlog, res.Blog.ID)
+			}
+		}
+	})
+	return resp, nil
+}
+
+// ListResumes lists the resume sections of a site.
+//
+//encore:api auth method=GET path=/v1/sites/:site/resumes
+func (s *Service) ListResumes(ctx context.Context, site string, p *app.ListResumesParams) (*app.ListResumesResponse, error) {
+	id, err := restSite(ctx, site, "read:resumes")
+	if err != nil {
+		return nil, err
+	}
+	return app.ListResumes(ctx, id, p)
+}
+
+// GetResume returns a resume section of a site.
+//
+//encore:api auth method=GET path=/v1/sites/:site/resumes/:id
+func (s *Service) GetResume(ctx context.Context, site string, id uint) (*app.Resume, error) {
+	siteID, err := restSite(ctx, site, "read:resumes")
+	if err != nil {
+		return nil, err
+	}
+	return app.GetResume(ctx, siteID, id)
+}
+
+// CreateResume creates a resume section on a site.
+//
+//encore:api auth method=POST path=/v1/sites/:site/resumes
+func (s *Service) CreateResume(ctx context.Context, site string, p *app.CreateResumeParams) (*app.Resume, error) {
+	id, err := restSite(ctx, site, "write:resumes")
+	if err != nil {
+		return nil, err
+	}
+	resume, err := app.CreateResume(ctx, id, p)
+	if err != nil {
+		return nil, err
+	}
+	s.dropCached(ctx, func(ctx context.Context) { invalidate(ctx, typeResume, resume.ID) })
+	return resume, nil
+}
+
+// UpdateResume changes the fields of a resume section that are set.
+//
+//encore:api auth method=PATCH path=/v1/sites/:site/resumes/:id
+func (s *Service) UpdateResume(ctx context.Context, site string, id uint, p *app.UpdateResumeParams) (*app.Resume, error) {
+	siteID, err := restSite(ctx, site, "write:resumes")
+	if err != nil {
+		return nil, err
+	}
+	resume, err := app.UpdateResume(ctx, siteID, id, p)
+	if err != nil {
+		return nil, err
+	}
+	s.dropCached(ctx, func(ctx context.Context) { invalidate(ctx, typeResume, resume.ID) })
+	return resume, nil
+}
+
+// DeleteResume deletes a resume section of a site.
+//
+//encore:api auth method=DELETE path=/v1/sites/:site/resumes/:id
+func (s *Service) DeleteResume(ctx context.Context, site string, id uint) (*app.Resume, error) {
+	siteID, err := restSite(ctx, site, "write:resumes")
+	if err != nil {
+		return nil, err
+	}
+	resume, err := app.DeleteResume(ctx, siteID, id)
+	if err != nil {
+		return nil, err
+	}
+	s.dropCached(ctx, func(ctx context.Context) { invalidate(ctx, typeResume, resume.ID) })
+	return resume, nil
+}
+
+// BatchResumes runs create, update and delete operations on the resume
+// sections of a site in one transaction.
+//
+//encore:api auth method=POST path=/v1/sites/:site/batch/resumes
+func (s *Service) BatchResumes(ctx context.Context, site string, p *app.BatchResumesParams) (*app.BatchResumesResponse, error) {
+	id, err := restSite(ctx, site, "write:resumes")
+	if err != nil {
+		return nil, err
+	}
+	resp, err := app.BatchResumes(ctx, id, p)
+	if err != nil {
+		return nil, err
+	}
+	s.dropCached(ctx, func(ctx context.Context) {
+		invalidate(ctx, typeResume)
+		for _, res := range resp.Results {
+			if res.Resume != nil {
+				invalidate(ctx, typeResume, res.Resume.ID)
+			}
+		}
+	})
+	return resp, nil
+}
@@ -0,0 +1,73 @@
+//go:build encore_app
+
+package graphql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"encore.app/app"
+	"encore.dev/beta/errs"
+	"encore.dev/et"
+)
+
+func TestRESTScopes(t *testing.T) {
+	ctx := context.Background()
+	site, err := app.ResolveSite(ctx, &app.ResolveSiteParams{Slug: cfg.DefaultSite()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{}
+
+	et.OverrideAuthInfo("apikey:1", &AuthData{KeyID: 1, SiteID: site.ID, Scopes: []string{"read:blogs"}})
+	if _, err := s.ListBlogs(ctx, site.Slug, &app.ListBlogsParams{}); err != nil {
+		t.Errorf("list with read:blogs: %v", err)
+	}
+	if _, err := s.ListBlogs(ctx, site.Slug, &app.ListBlogsParams{IncludeDrafts: true}); errCode(err) != errs.PermissionDenied {
+		t.Errorf("list drafts with read:blogs: err = %v, want PermissionDenied", err)
+	}
+	if _, err := s.CreateBlog(ctx, site.Slug, &app.CreateBlogParams{Title: "Nope", Content: "..."}); errCode(err) != errs.PermissionDenied {
+		t.Errorf("create with read:blogs: err = %v, want PermissionDenied", err)
+	}
+	if _, err := s.ListUsers(ctx, site.Slug, &app.ListUsersParams{}); errCode(err) != errs.PermissionDenied {
+		t.Errorf("list users with read:blogs: err = %v, want PermissionDenied", err)
+	}
+
+	et.OverrideAuthInfo("apikey:2", &AuthData{KeyID: 2, SiteID: site.ID + 1000, Scopes: []string{"read:blogs"}})
+	if _, err := s.ListBlogs(ctx, site.Slug, &app.ListBlogsParams{}); errCode(err) != errs.PermissionDenied {
+		t.Errorf("list with a key of another site: err = %v, want PermissionDenied", err)
+	}
+	if _, err := s.ListBlogs(ctx, "no-such-site", &app.ListBlogsParams{}); errCode(err) != errs.NotFound {
+		t.Errorf("list on a missing site: err = %v, want NotFound", err)
+	}
+}
+
+// TestRESTWriteDropsCache checks that writes through the REST API drop the
+// cached GraphQL responses they make stale, as mutations do.
+func TestRESTWriteDropsCache(t *testing.T) {
+	ctx := context.Background()
+	site, err := app.ResolveSite(ctx, &app.ResolveSiteParams{Slug: cfg.DefaultSite()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := newMemoryResponseStore(10)
+	s := &Service{cache: store}
+	store.set(ctx, "blogs", []byte("{}"), time.Minute, []string{typeBlog})
+	store.set(ctx, "users", []byte("{}"), time.Minute, []string{typeUser})
+
+	et.OverrideAuthInfo("admin", &AuthData{Scopes: []string{scopeAll}})
+	blog, err := s.CreateBlog(ctx, site.Slug, &app.CreateBlogParams{Title: "Over REST", Content: "..."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blog.SiteID != site.ID {
+		t.Errorf("created on site %d, want %d", blog.SiteID, site.ID)
+	}
+	if _, ok, _ := store.get(ctx, "blogs"); ok {
+		t.Error("cached list of blog posts kept after a create")
+	}
+	if _, ok, _ := store.get(ctx, "users"); !ok {
+		t.Error("cached list of users dropped by a blog post")
+	}
+}
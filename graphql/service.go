@@ -1,13 +1,21 @@
 package graphql
 
 import (
+	"context"
 	"net/http"
+	"time"
 
-	"encore.app/app" // Import app package to access Service
+	"encore.app/app"
 	"encore.app/graphql/generated"
 	"encore.dev"
+	"encore.dev/rlog"
 	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/gorilla/websocket"
+	"github.com/vektah/gqlparser/v2/ast"
 )
 
 //go:generate go run github.com/99designs/gqlgen generate
@@ -16,29 +24,105 @@ import (
 type Service struct {
 	srv        *handler.Server
 	playground http.Handler
+	mailer     mailer
+	// cache holds the responses of the response cache, if it is enabled.
+	cache responseStore
 }
 
 func initService() (*Service, error) {
-	// Initialize app.Service to get the db
-	appService, err := app.New() // Call constructor from app/app.go
-	if err != nil {
-		return nil, err
+	resolver := &Resolver{
+		contact:     newContactGuard(),
+		views:       &viewDedupe{},
+		reactionHub: newReactionHub(),
 	}
-
-	// Use appService's db
-	db := appService.DB()
-
-	// Create config with Resolver that uses db
-	cfg := generated.Config{Resolvers: &Resolver{db: db}}
-	srv := handler.NewDefaultServer(generated.NewExecutableSchema(cfg))
+	if secrets.FingerprintSecret == "" {
+		rlog.Error("graphql: FingerprintSecret is not set, so anonymous visitors cannot react")
+	}
+	schema := generated.NewExecutableSchema(generated.Config{Resolvers: resolver})
+	resolver.scopes = schemaScopes(schema.Schema())
+	srv := handler.New(schema)
+	srv.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+		// Subscriptions are authenticated by header, not by cookie, so
+		// pages on any origin may open them.
+		Upgrader: websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }},
+	})
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{})
+	srv.SetQueryCache(lru.New[*ast.QueryDocument](1000))
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.AutomaticPersistedQuery{Cache: lru.New[string](100)})
+	srv.SetErrorPresenter(newErrorPresenter(encore.Meta().Environment.Type == encore.EnvProduction))
+	srv.SetRecoverFunc(recoverPanic)
+	srv.AroundFields(authorize(schema.Schema()))
+	srv.AroundFields(validateArguments(schema.Schema()))
+	srv.AroundFields(cacheControl(schema.Schema()))
+	srv.AroundResponses(cacheErrors)
+	var store responseStore
+	if n := cfg.ResponseCache.MaxEntries(); n > 0 {
+		srv.AroundFields(tagResponses)
+		store = newMemoryResponseStore(n)
+		if poll := cfg.ResponseCache.PollSeconds(); poll > 0 {
+			shared := &sharedResponseStore{responseStore: store}
+			go shared.follow(time.Duration(poll) * time.Second)
+			store = shared
+		}
+		srv.AroundResponses(responseCache(store))
+	}
+	if rate := cfg.RateLimit.PerSecond(); rate > 0 {
+		store := newLimitStore(app.BucketRate{Rate: rate, Burst: float64(cfg.RateLimit.Burst())})
+		srv.Use(&rateLimit{store: store, burst: cfg.RateLimit.Burst()})
+	}
+	srv.AroundResponses(operationTimeouts(
+		time.Duration(cfg.QueryTimeoutMs())*time.Millisecond,
+		time.Duration(cfg.MutationTimeoutMs())*time.Millisecond,
+	))
 
 	pg := playground.Handler("GraphQL Playground", "/graphql")
-	return &Service{srv: srv, playground: pg}, nil
+	mailer, err := newMailer()
+	if err != nil {
+		return nil, err
+	}
+	return &Service{srv: srv, playground: pg, mailer: mailer, cache: store}, nil
 }
 
-//encore:api public raw path=/graphql
+//encore:api public raw method=GET,POST path=/graphql
 func (s *Service) Query(w http.ResponseWriter, req *http.Request) {
-	s.srv.ServeHTTP(w, req)
+	site, err := siteFor(req.Context(), req)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	p, err := principalFor(site)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	c := clientFor(req, cfg.RateLimit.TrustForwardedFor())
+	ctx := context.WithValue(app.WithSite(req.Context(), site.ID), clientKey{}, c)
+	ctx = context.WithValue(ctx, principalKey{}, p)
+	ctx = context.WithValue(ctx, localesKey{}, acceptedLocales(req.Header.Get("Accept-Language")))
+	ctx = context.WithValue(ctx, loadersKey{}, newLoaders(ctx, site.ID))
+	req = req.WithContext(ctx)
+	switch {
+	case req.Header.Get("Upgrade") != "":
+		// WebSocket subscriptions take over the connection, which the
+		// wrapped writers of the other cases cannot hand over.
+		s.srv.ServeHTTP(w, req)
+	case req.Method == http.MethodGet:
+		serveCached(w, req, p.authenticated, s.serve(c))
+	default:
+		s.serve(c)(w, req)
+	}
+}
+
+// serve returns the handler that runs the GraphQL server for client c.
+func (s *Service) serve(c *client) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		s.srv.ServeHTTP(&limitedWriter{ResponseWriter: w, client: c}, req)
+	}
 }
 
 //encore:api public raw path=/graphql/playground
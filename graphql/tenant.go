@@ -0,0 +1,68 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+
+	"encore.app/app"
+	"encore.dev/rlog"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// siteHeader selects the site of a request by slug, overriding the Host.
+const siteHeader = "X-Site"
+
+// siteFor resolves the site a request is for: the slug in the X-Site header,
+// otherwise the site whose domain is the request's host, otherwise the
+// configured default site.
+func siteFor(ctx context.Context, req *http.Request) (*app.Site, error) {
+	return app.ResolveSite(ctx, siteLookup(req))
+}
+
+// siteLookup returns the parameters that resolve the site of req.
+func siteLookup(req *http.Request) *app.ResolveSiteParams {
+	host := req.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return &app.ResolveSiteParams{
+		Slug:     req.Header.Get(siteHeader),
+		Host:     strings.ToLower(host),
+		Fallback: cfg.DefaultSite(),
+	}
+}
+
+// writeRequestError answers a request that was rejected before reaching the
+// GraphQL server, e.g. because its site could not be resolved, in the shape
+// of a GraphQL response.
+func writeRequestError(w http.ResponseWriter, err error) {
+	status, gqlErr := http.StatusBadRequest, &gqlerror.Error{Message: err.Error()}
+	if ce, ok := classify(err); ok {
+		setCode(gqlErr, ce.code)
+		switch ce.code {
+		case CodeNotFound:
+			status = http.StatusNotFound
+		case CodeForbidden:
+			status = http.StatusForbidden
+		}
+	} else {
+		id := correlationID()
+		rlog.Error("graphql: resolving site", "correlation_id", id, "err", err)
+		status, gqlErr.Message = http.StatusInternalServerError, "internal error"
+		setCode(gqlErr, CodeInternal)
+		gqlErr.Extensions["correlationId"] = id
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(graphql.Response{Errors: gqlerror.List{gqlErr}})
+}
+
+// siteOf returns the site of the request being served.
+func siteOf(ctx context.Context) uint {
+	site, _ := app.SiteFrom(ctx)
+	return site
+}
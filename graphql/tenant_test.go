@@ -0,0 +1,62 @@
+//go:build encore_app
+
+package graphql
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"encore.dev/beta/errs"
+)
+
+func TestSiteLookup(t *testing.T) {
+	tests := []struct {
+		name       string
+		host, slug string
+		wantSlug   string
+		wantHost   string
+	}{
+		{"host", "Blog.Example.com", "", "", "blog.example.com"},
+		{"host with port", "blog.example.com:8443", "", "", "blog.example.com"},
+		{"ipv6 host", "[::1]:4000", "", "", "::1"},
+		{"header", "blog.example.com", "ada", "ada", "blog.example.com"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/graphql", nil)
+		req.Host = tt.host
+		if tt.slug != "" {
+			req.Header.Set(siteHeader, tt.slug)
+		}
+		p := siteLookup(req)
+		if p.Slug != tt.wantSlug || p.Host != tt.wantHost {
+			t.Errorf("%s: slug %q, host %q; want %q, %q", tt.name, p.Slug, p.Host, tt.wantSlug, tt.wantHost)
+		}
+		if p.Fallback != cfg.DefaultSite() {
+			t.Errorf("%s: fallback %q, want the default site", tt.name, p.Fallback)
+		}
+	}
+}
+
+// TestSiteFor resolves sites through the app service. Matching domains is
+// tested there; the default site comes with the migrations.
+func TestSiteFor(t *testing.T) {
+	ctx := context.Background()
+	req := httptest.NewRequest("POST", "/graphql", nil)
+	req.Host = "unknown.example.com"
+	site, err := siteFor(ctx, req)
+	if err != nil || site.Slug != cfg.DefaultSite() {
+		t.Fatalf("unknown host: site %v, %v; want the default site", site, err)
+	}
+
+	req.Header.Set(siteHeader, cfg.DefaultSite())
+	if site, err := siteFor(ctx, req); err != nil || site.Slug != cfg.DefaultSite() {
+		t.Errorf("X-Site: site %v, %v", site, err)
+	}
+
+	// An unknown slug is an error rather than the default site.
+	req.Header.Set(siteHeader, "no-such-site")
+	if _, err := siteFor(ctx, req); errs.Code(err) != errs.NotFound {
+		t.Errorf("unknown X-Site: err = %v, want NotFound", err)
+	}
+}
@@ -0,0 +1,153 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"encore.app/graphql/model"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// violation is a single failed @constraint rule.
+type violation struct {
+	path       ast.Path
+	constraint string
+	message    string
+}
+
+func (v violation) gqlError() *gqlerror.Error {
+	inputPath := make([]any, len(v.path))
+	for i, el := range v.path {
+		switch el := el.(type) {
+		case ast.PathName:
+			inputPath[i] = string(el)
+		case ast.PathIndex:
+			inputPath[i] = int(el)
+		}
+	}
+	return &gqlerror.Error{
+		Message: v.message,
+		Extensions: map[string]any{
+			"code":       CodeValidationFailed,
+			"field":      fieldName(v.path),
+			"inputPath":  inputPath,
+			"constraint": v.constraint,
+		},
+	}
+}
+
+// validateArguments returns a field middleware that checks every argument
+// against the @constraint directives declared in schema. All violations are
+// reported at once, each as its own error of the field, and the resolver is
+// not called.
+// Payload mutations are still called so that they can report the violations
+// as userErrors instead.
+func validateArguments(schema *ast.Schema) graphql.FieldMiddleware {
+	return func(ctx context.Context, next graphql.Resolver) (any, error) {
+		fc := graphql.GetFieldContext(ctx)
+		if fc == nil || fc.Field.Field == nil || fc.Field.Definition == nil || len(fc.Field.Definition.Arguments) == 0 {
+			return next(ctx)
+		}
+		vs := argumentViolations(schema, fc.Field.Definition, fc.Field.ArgumentMap(graphql.GetOperationContext(ctx).Variables))
+		if len(vs) == 0 {
+			return next(ctx)
+		}
+		if returnsPayload(schema, fc.Field.Definition) {
+			return next(context.WithValue(ctx, violationsKey{}, vs))
+		}
+		// gqlgen reports each error of a list at the path of the field, which
+		// keeps it from adding a null error of its own.
+		errs := make(gqlerror.List, len(vs))
+		for i, v := range vs {
+			errs[i] = v.gqlError()
+		}
+		return nil, errs
+	}
+}
+
+// argumentViolations checks the raw argument values of a field.
+func argumentViolations(schema *ast.Schema, def *ast.FieldDefinition, args map[string]any) []violation {
+	var vs []violation
+	for _, arg := range def.Arguments {
+		vs = checkValue(vs, schema, ast.Path{ast.PathName(arg.Name)}, arg.Type, arg.Directives, args[arg.Name])
+	}
+	return vs
+}
+
+func checkValue(vs []violation, schema *ast.Schema, path ast.Path, typ *ast.Type, directives ast.DirectiveList, value any) []violation {
+	if value == nil {
+		return vs
+	}
+	if typ.Elem != nil {
+		items, ok := value.([]any)
+		if !ok {
+			items = []any{value}
+		}
+		for i, item := range items {
+			vs = checkValue(vs, schema, appendPath(path, ast.PathIndex(i)), typ.Elem, directives, item)
+		}
+		return vs
+	}
+	if d := directives.ForName("constraint"); d != nil {
+		if s, ok := value.(string); ok {
+			vs = append(vs, checkConstraint(path, d.ArgumentMap(nil), s)...)
+		}
+	}
+	if def := schema.Types[typ.NamedType]; def != nil && def.Kind == ast.InputObject {
+		obj, _ := value.(map[string]any)
+		for _, f := range def.Fields {
+			vs = checkValue(vs, schema, appendPath(path, ast.PathName(f.Name)), f.Type, f.Directives, obj[f.Name])
+		}
+	}
+	return vs
+}
+
+func checkConstraint(path ast.Path, rule map[string]any, value string) []violation {
+	var vs []violation
+	name := fieldName(path)
+	length := utf8.RuneCountInString(strings.TrimSpace(value))
+	if minLen, ok := rule["minLength"].(int64); ok && int64(length) < minLen {
+		msg := fmt.Sprintf("%s must be at least %d characters", name, minLen)
+		if minLen == 1 {
+			msg = name + " must not be empty"
+		}
+		vs = append(vs, violation{path: path, constraint: "minLength", message: msg})
+	}
+	if maxLen, ok := rule["maxLength"].(int64); ok && int64(length) > maxLen {
+		vs = append(vs, violation{path: path, constraint: "maxLength", message: fmt.Sprintf("%s must be at most %d characters", name, maxLen)})
+	}
+	format, _ := rule["format"].(string)
+	switch model.ConstraintFormat(format) {
+	case model.ConstraintFormatEmail:
+		if addr, err := mail.ParseAddress(value); err != nil || addr.Address != value {
+			vs = append(vs, violation{path: path, constraint: "format", message: name + " must be a valid email address"})
+		}
+	case model.ConstraintFormatURL:
+		if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			vs = append(vs, violation{path: path, constraint: "format", message: name + " must be a valid http(s) URL"})
+		}
+	}
+	return vs
+}
+
+// fieldName returns the innermost named element of path.
+func fieldName(path ast.Path) string {
+	for i := len(path) - 1; i >= 0; i-- {
+		if name, ok := path[i].(ast.PathName); ok {
+			return string(name)
+		}
+	}
+	return ""
+}
+
+// appendPath returns a copy of path extended by el, so sibling paths never
+// share a backing array.
+func appendPath(path ast.Path, el ast.PathElement) ast.Path {
+	return append(path[:len(path):len(path)], el)
+}
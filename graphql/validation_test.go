@@ -0,0 +1,71 @@
+//go:build encore_app
+
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"encore.app/app"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestCheckConstraint(t *testing.T) {
+	path := ast.Path{ast.PathName("input"), ast.PathName("email")}
+	tests := []struct {
+		rule  map[string]any
+		value string
+		want  []string
+	}{
+		{map[string]any{"minLength": int64(1)}, "  ", []string{"minLength"}},
+		{map[string]any{"minLength": int64(1), "maxLength": int64(3)}, "abcd", []string{"maxLength"}},
+		{map[string]any{"maxLength": int64(3)}, "äöü", nil},
+		{map[string]any{"format": "EMAIL"}, "ada@example.com", nil},
+		{map[string]any{"format": "EMAIL"}, "Ada <ada@example.com>", []string{"format"}},
+		{map[string]any{"format": "URL"}, "https://example.com/x", nil},
+		{map[string]any{"format": "URL"}, "javascript:alert(1)", []string{"format"}},
+		{map[string]any{"format": "URL", "maxLength": int64(5)}, "ftp:", []string{"format"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, v := range checkConstraint(path, tt.rule, tt.value) {
+			got = append(got, v.constraint)
+			if !strings.HasPrefix(v.message, "email ") {
+				t.Errorf("%q: message %q does not name the field", tt.value, v.message)
+			}
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%q against %v: violations %v, want %v", tt.value, tt.rule, got, tt.want)
+		}
+	}
+}
+
+// TestViolationsOfNonNullField checks that a field with invalid arguments
+// fails once, at its own path, without a null error on top.
+func TestViolationsOfNonNullField(t *testing.T) {
+	ctx := context.Background()
+	site, err := app.ResolveSite(ctx, &app.ResolveSiteParams{Slug: cfg.DefaultSite()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blog, err := app.CreateBlog(ctx, site.ID, &app.CreateBlogParams{Title: "Notes", Content: "..."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	locale := strings.Repeat("x", 36)
+	resp := execute(t, site.ID, reader, `query($id: ID!, $locale: String) {
+		blog(id: $id) { id title(locale: $locale) }
+	}`, map[string]any{"id": globalID(typeBlog, blog.ID), "locale": locale})
+	if len(resp.Errors) != 1 {
+		t.Fatalf("errors = %+v, want one violation", resp.Errors)
+	}
+	e := resp.Errors[0]
+	if e.Extensions["code"] != CodeValidationFailed || e.Extensions["constraint"] != "maxLength" {
+		t.Errorf("error = %+v, want a maxLength violation", e)
+	}
+	if fmt.Sprint(e.Path) != "[blog title]" {
+		t.Errorf("error at %v, want [blog title]", e.Path)
+	}
+}
@@ -0,0 +1,69 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"encore.app/app"
+	"encore.app/graphql/model"
+	"encore.dev/beta/errs"
+)
+
+// versionConflict is returned when an update was based on a version of the
+// record that is no longer current. It carries the current server copy so
+// that clients can merge their changes into it.
+type versionConflict struct {
+	current model.Node
+	version int
+}
+
+func (e *versionConflict) Error() string {
+	return fmt.Sprintf("record was modified concurrently and is now at version %d", e.version)
+}
+
+type versioned interface {
+	VersionInfo() *app.Versioned
+}
+
+// conflict turns the error the app service returns for an update of the
+// record typ pk that was based on a stale version into a versionConflict
+// carrying the current record. Other errors are returned unchanged.
+func (r *Resolver) conflict(ctx context.Context, err error, typ string, pk uint) error {
+	site := siteOf(ctx)
+	// The caller could update the record, so it may see it too.
+	switch typ {
+	case typeUser:
+		return withCurrent(err, func() (model.Node, error) { return app.GetUser(ctx, site, pk) })
+	case typeProject:
+		return withCurrent(err, func() (model.Node, error) {
+			return app.GetProject(ctx, site, pk, &app.GetProjectParams{IncludeHidden: true})
+		})
+	case typeBlog:
+		return withCurrent(err, func() (model.Node, error) {
+			return app.GetBlog(ctx, site, pk, &app.GetBlogParams{IncludeDrafts: true})
+		})
+	case typeResume:
+		return withCurrent(err, func() (model.Node, error) { return app.GetResume(ctx, site, pk) })
+	}
+	return err
+}
+
+// withCurrent turns err into a versionConflict carrying the record returned by
+// current if the app service rejected an update for a stale version. Other
+// errors are returned unchanged.
+func withCurrent(err error, current func() (model.Node, error)) error {
+	var encoreErr *errs.Error
+	if !errors.As(err, &encoreErr) {
+		return err
+	}
+	details, ok := encoreErr.Details.(app.VersionDetails)
+	if !ok {
+		return err
+	}
+	record, getErr := current()
+	if getErr != nil {
+		return getErr
+	}
+	return &versionConflict{current: record, version: details.Current}
+}